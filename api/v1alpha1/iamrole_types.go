@@ -18,15 +18,23 @@ package v1alpha1
 import (
 	"fmt"
 	"hash/adler32"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/keikoproj/iam-manager/internal/config"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // IamroleSpec defines the desired state of Iamrole
+// +kubebuilder:validation:XValidation:rule="has(self.Path) == has(oldSelf.Path) && (!has(self.Path) || self.Path == oldSelf.Path)",message="Path is immutable"
 type IamroleSpec struct {
 	PolicyDocument PolicyDocument `json:"PolicyDocument"`
 	// +optional
@@ -35,6 +43,105 @@ type IamroleSpec struct {
 	// Please check the documentation for more on how to configure privileged namespace using annotation for iam-manager
 	// +optional
 	RoleName string `json:"RoleName,omitempty"`
+	// ManagedPolicyArns lists managed IAM policies to attach to the role in addition to the cluster wide managed policies
+	// Every ARN must be allowed by iam.managed.policies.allowed config map property
+	// +optional
+	ManagedPolicyArns []string `json:"ManagedPolicyArns,omitempty"`
+	// InlinePolicies lists additional named inline policies attached to the role next to PolicyDocument
+	// +optional
+	// +listType=map
+	// +listMapKey=Name
+	InlinePolicies []InlinePolicy `json:"InlinePolicies,omitempty"`
+	// MaxSessionDuration is the maximum session duration in seconds for the role.
+	// It must be within iam.role.max.session.duration.min and iam.role.max.session.duration.max config map properties.
+	// By default, iam.role.max.session.duration.max is used
+	// +kubebuilder:validation:Minimum=3600
+	// +kubebuilder:validation:Maximum=43200
+	// +optional
+	MaxSessionDuration int64 `json:"MaxSessionDuration,omitempty"`
+	// Description of the role. By default, "#DO NOT DELETE#. Managed by iam-manager" is used
+	// +kubebuilder:validation:MaxLength=1000
+	// +optional
+	Description string `json:"Description,omitempty"`
+	// Path of the role. It must start with iam.role.path.prefix config map property and cannot be changed once set.
+	// By default, iam.role.path.prefix is used
+	// +kubebuilder:validation:MaxLength=512
+	// +kubebuilder:validation:Pattern=`^(/|/[!-~]+/)$`
+	// +optional
+	Path string `json:"Path,omitempty"`
+	// AdoptRoleARN is the ARN of a pre-existing role which is not managed by iam-manager for this namespace yet.
+	// iam-manager takes it over instead of refusing it. The role name must match the generated role name and be allowed
+	// by iam.role.adopt.allowed config map property. Trust policy, inline policies, permission boundary, description
+	// and tags of the role are overwritten
+	// +kubebuilder:validation:Pattern=`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`
+	// +optional
+	AdoptRoleARN string `json:"AdoptRoleARN,omitempty"`
+	// DeletionPolicy decides what happens to the role when the Iamrole is deleted. By default, iam.role.deletion.policy
+	// config map property is used. Retain and RetainWithoutPolicies keep the role and only remove the managedBy, Namespace
+	// and Cluster tags so that it can be adopted again later
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"DeletionPolicy,omitempty"`
+	// Tags are custom tags attached to the role. managedBy, Namespace and Cluster keys are reserved.
+	// They take precedence over the deprecated iammanager.keikoproj.io/tags annotation
+	// +kubebuilder:validation:MaxProperties=47
+	// +optional
+	Tags map[string]string `json:"Tags,omitempty"`
+	// ServiceAccounts lists the IRSA service accounts allowed to assume the role. They are annotated with the role ARN.
+	// Entries take precedence over the iam.amazonaws.com/irsa-service-account annotation with the same name
+	// +optional
+	// +listType=map
+	// +listMapKey=Name
+	ServiceAccounts []ServiceAccount `json:"ServiceAccounts,omitempty"`
+}
+
+// DeletionPolicy describes what happens to the role when the Iamrole is deleted
+// +kubebuilder:validation:Enum=Delete;Retain;RetainWithoutPolicies
+type DeletionPolicy string
+
+const (
+	//DeletionPolicyDelete deletes the role
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	//DeletionPolicyRetain keeps the role with its policies
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+
+	//DeletionPolicyRetainWithoutPolicies keeps the role but removes its inline and managed policies
+	DeletionPolicyRetainWithoutPolicies DeletionPolicy = "RetainWithoutPolicies"
+)
+
+// ServiceAccount type defines an IRSA service account for the role
+type ServiceAccount struct {
+	// Name of the service account in the Iamrole namespace
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`
+	Name string `json:"Name"`
+	// Annotations are added to the service account next to the role annotations
+	// +optional
+	Annotations map[string]string `json:"Annotations,omitempty"`
+	// Labels are added to the service account
+	// +optional
+	Labels map[string]string `json:"Labels,omitempty"`
+	// CreateIfMissing creates the service account when it does not exist. Defaults to true.
+	// Otherwise only an existing service account is annotated
+	// +optional
+	CreateIfMissing *bool `json:"CreateIfMissing,omitempty"`
+}
+
+// ShouldCreate reports whether the service account is created when it does not exist
+func (sa *ServiceAccount) ShouldCreate() bool {
+	return sa.CreateIfMissing == nil || *sa.CreateIfMissing
+}
+
+// InlinePolicy type defines a named inline IAM policy
+type InlinePolicy struct {
+	// Name of the inline policy. The name used for PolicyDocument is reserved
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=128
+	// +kubebuilder:validation:Pattern=`^[\w+=,.@-]+$`
+	Name string `json:"Name"`
+
+	PolicyDocument PolicyDocument `json:"PolicyDocument"`
 }
 
 // +kubebuilder:validation:Required
@@ -58,13 +165,179 @@ type Statement struct {
 	Effect Effect `json:"Effect"`
 
 	//Action allowed on specific resources
-	Action []string `json:"Action"`
+	// +optional
+	Action []string `json:"Action,omitempty"`
+
+	//NotAction matches every action except the listed ones. It cannot be used together with Action
+	// +optional
+	NotAction []string `json:"NotAction,omitempty"`
 
 	//Resources defines target resources which IAM policy will be applied
-	Resource []string `json:"Resource"`
+	// +optional
+	Resource []string `json:"Resource,omitempty"`
+
+	//NotResource matches every resource except the listed ones. It cannot be used together with Resource
+	// +optional
+	NotResource []string `json:"NotResource,omitempty"`
 	// Sid is an optional field which describes the specific statement action
 	// +optional
 	Sid string `json:"Sid,omitempty"`
+	// Condition specifies the circumstances under which the statement is in effect
+	// Condition values may be a single string or a list of strings, so they are left out of the schema
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Condition PolicyCondition `json:"Condition,omitempty"`
+}
+
+// ValidateElements verifies that the statement has exactly one of Action/NotAction
+// and exactly one of Resource/NotResource
+func (s *Statement) ValidateElements() error {
+	if (len(s.Action) > 0) == (len(s.NotAction) > 0) {
+		return fmt.Errorf("statement must specify exactly one of Action or NotAction")
+	}
+	if (len(s.Resource) > 0) == (len(s.NotResource) > 0) {
+		return fmt.Errorf("statement must specify exactly one of Resource or NotResource")
+	}
+	return nil
+}
+
+// ExcludesResource reports whether NotResource keeps the given resource out of the statement
+func (s *Statement) ExcludesResource(resource string) bool {
+	for _, res := range s.NotResource {
+		if res == "*" || res == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludesResourcesContaining reports whether NotResource keeps every resource containing the given
+// fragment out of the statement. Only "*" or "*<fragment>*" style exclusions are wide enough
+func (s *Statement) ExcludesResourcesContaining(fragment string) bool {
+	for _, res := range s.NotResource {
+		if res == "*" || res == "*"+fragment+"*" {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicyCondition holds an IAM condition block keyed by condition operator and then by condition key
+// Example: {"StringEquals": {"aws:SourceVpce": ["vpce-1a2b3c4d"]}, "ForAnyValue:StringLike": {"s3:prefix": ["home/*"]}}
+type PolicyCondition map[string]map[string]StringOrStrings
+
+// conditionOperators lists the base IAM condition operators.
+// Operators can also be prefixed with ForAllValues:/ForAnyValue: and, except Null, suffixed with IfExists
+var conditionOperators = []string{
+	"StringEquals", "StringNotEquals", "StringEqualsIgnoreCase", "StringNotEqualsIgnoreCase", "StringLike", "StringNotLike",
+	"NumericEquals", "NumericNotEquals", "NumericLessThan", "NumericLessThanEquals", "NumericGreaterThan", "NumericGreaterThanEquals",
+	"DateEquals", "DateNotEquals", "DateLessThan", "DateLessThanEquals", "DateGreaterThan", "DateGreaterThanEquals",
+	"Bool", "BinaryEquals", "IpAddress", "NotIpAddress",
+	"ArnEquals", "ArnLike", "ArnNotEquals", "ArnNotLike",
+	"Null",
+}
+
+// IsValidConditionOperator verifies whether the operator is supported by AWS IAM
+func IsValidConditionOperator(operator string) bool {
+	op := operator
+	for _, prefix := range []string{"ForAllValues:", "ForAnyValue:"} {
+		if strings.HasPrefix(op, prefix) {
+			op = strings.TrimPrefix(op, prefix)
+			break
+		}
+	}
+	if strings.HasSuffix(op, "IfExists") && op != "IfExists" {
+		op = strings.TrimSuffix(op, "IfExists")
+		if op == "Null" {
+			return false
+		}
+	}
+	for _, valid := range conditionOperators {
+		if op == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalString renders the condition block in a deterministic, value-order insensitive form.
+// Condition blocks which only use single valued StringEquals/StringLike keys are rendered exactly like
+// the former StringEquals/StringLike struct so the ids of existing trust policy statements do not change
+func (pc PolicyCondition) canonicalString() string {
+	legacy := true
+	for operator, keys := range pc {
+		if operator != "StringEquals" && operator != "StringLike" {
+			legacy = false
+			break
+		}
+		for _, values := range keys {
+			if len(values) != 1 {
+				legacy = false
+			}
+		}
+	}
+
+	if legacy {
+		single := func(keys map[string]StringOrStrings) map[string]string {
+			if keys == nil {
+				return nil
+			}
+			out := make(map[string]string, len(keys))
+			for k, v := range keys {
+				out[k] = v[0]
+			}
+			return out
+		}
+		return fmt.Sprintf("{StringEquals:%v StringLike:%v}", single(pc["StringEquals"]), single(pc["StringLike"]))
+	}
+
+	operators := make([]string, 0, len(pc))
+	for operator := range pc {
+		operators = append(operators, operator)
+	}
+	sort.Strings(operators)
+
+	var b strings.Builder
+	for _, operator := range operators {
+		keys := make([]string, 0, len(pc[operator]))
+		for key := range pc[operator] {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fmt.Fprintf(&b, "{%s:", operator)
+		for _, key := range keys {
+			values := append([]string{}, pc[operator][key]...)
+			sort.Strings(values)
+			fmt.Fprintf(&b, "%s:%v", key, values)
+		}
+		b.WriteString("}")
+	}
+	return b.String()
+}
+
+// Validate verifies that every operator in the condition block is a valid IAM condition operator
+// and every condition key has at least one value
+func (pc PolicyCondition) Validate() error {
+	for operator, keys := range pc {
+		if !IsValidConditionOperator(operator) {
+			return fmt.Errorf("unsupported condition operator %s", operator)
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("condition operator %s must have at least one condition key", operator)
+		}
+		for key, values := range keys {
+			if key == "" {
+				return fmt.Errorf("condition operator %s has an empty condition key", operator)
+			}
+			if len(values) == 0 {
+				return fmt.Errorf("condition key %s under operator %s must have at least one value", key, operator)
+			}
+		}
+	}
+	return nil
 }
 
 // Effect describes whether to allow or deny the specific action
@@ -95,8 +368,13 @@ type TrustPolicyStatement struct {
 	Action string `json:"Action,omitempty"`
 	// +optional
 	Principal Principal `json:"Principal,omitempty"`
+	// Condition holds the trust policy condition block keyed by condition operator and then by condition key
+	// Condition values may be a single string or a list of strings, so they are left out of the schema
 	// +optional
-	Condition *Condition `json:"Condition,omitempty"`
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Condition PolicyCondition `json:"Condition,omitempty"`
 }
 
 // Id returns the sid of the trust policy statement
@@ -123,19 +401,21 @@ func (tps *TrustPolicyStatement) ConditionChecksum() string {
 	if !tps.HasCondition() {
 		return ""
 	}
-	return fmt.Sprintf("%x", adler32.Checksum([]byte(fmt.Sprintf("%+v", *tps.Condition))))
+	return fmt.Sprintf("%x", adler32.Checksum([]byte(tps.Condition.canonicalString())))
 }
 
 func (tps *TrustPolicyStatement) IsConditionAnyServiceAccount() bool {
-	if !tps.HasCondition() || len(tps.Condition.StringLike) == 0 {
+	if !tps.HasCondition() || len(tps.Condition["StringLike"]) == 0 {
 		return false
 	}
 
-	for k, v := range tps.Condition.StringLike {
+	for k, values := range tps.Condition["StringLike"] {
 		if strings.HasSuffix(k, ":sub") {
-			parts := strings.Split(v, ":")
-			if parts[len(parts)-1] == "*" {
-				return true
+			for _, v := range values {
+				parts := strings.Split(v, ":")
+				if parts[len(parts)-1] == "*" {
+					return true
+				}
 			}
 		}
 	}
@@ -154,17 +434,6 @@ type Principal struct {
 	Federated string `json:"Federated,omitempty"`
 }
 
-// Condition struct holds Condition
-// +optional
-type Condition struct {
-	//StringEquals can be used to define Equal condition
-	// +optional
-	StringEquals map[string]string `json:"StringEquals,omitempty"`
-	//StringLike can be used for regex as supported by AWS
-	// +optional
-	StringLike map[string]string `json:"StringLike,omitempty"`
-}
-
 const (
 	//Allow Policy allows policy
 	AllowPolicy Effect = "Allow"
@@ -173,6 +442,205 @@ const (
 	DenyPolicy Effect = "Deny"
 )
 
+// ReservedTagKeys are set by iam-manager on every role and cannot be used as custom tags
+var ReservedTagKeys = []string{"managedBy", "Namespace", "Cluster"}
+
+// maxTagsPerRole is the AWS limit of tags per role including the reserved ones
+const maxTagsPerRole = 50
+
+var tagCharacters = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+// ValidateTag validates a custom tag against the AWS tag rules and the reserved keys
+func ValidateTag(key, value string) error {
+	if key == "" || utf8.RuneCountInString(key) > 128 {
+		return fmt.Errorf("tag key %q must be between 1 and 128 characters", key)
+	}
+	if utf8.RuneCountInString(value) > 256 {
+		return fmt.Errorf("value of tag %q must not be longer than 256 characters", key)
+	}
+	if !tagCharacters.MatchString(key) || !tagCharacters.MatchString(value) {
+		return fmt.Errorf("tag %q can only contain letters, numbers, spaces and _.:/=+-@", key)
+	}
+	if strings.HasPrefix(strings.ToLower(key), "aws:") {
+		return fmt.Errorf("tag key %q must not start with aws:", key)
+	}
+	for _, reserved := range ReservedTagKeys {
+		// IAM tag keys are case insensitive
+		if strings.EqualFold(key, reserved) {
+			return fmt.Errorf("tag key %q is reserved for iam-manager", key)
+		}
+	}
+	return nil
+}
+
+// ValidateAdoptRoleARN validates the ARN of the pre-existing role to adopt against the account, the path of the
+// Iamrole, the path prefix and the adoption allow list. It returns the name of the role
+func ValidateAdoptRoleARN(roleARN string, path string) (string, error) {
+	rolePath, roleName, ok := splitRoleARN(roleARN)
+	if !ok {
+		return "", fmt.Errorf("%s is not an iam role ARN", roleARN)
+	}
+	if parsed, _ := arn.Parse(roleARN); parsed.AccountID != config.Props.AWSAccountID() {
+		return "", fmt.Errorf("role %s does not belong to account %s", roleARN, config.Props.AWSAccountID())
+	}
+	if path != "" && rolePath != path {
+		return "", fmt.Errorf("role %s is not at path %s", roleARN, path)
+	}
+	if !config.Props.IsRolePathAllowed(rolePath) {
+		return "", fmt.Errorf("role %s is not under path %s", roleARN, config.Props.RolePathPrefix())
+	}
+	if !config.Props.IsRoleAdoptionAllowed(roleName) {
+		return "", fmt.Errorf("role %s is not allowed to be adopted", roleName)
+	}
+	return roleName, nil
+}
+
+// ValidateTags validates every custom tag and the number of tags
+func ValidateTags(tags map[string]string) error {
+	if maxCustomTags := maxTagsPerRole - len(ReservedTagKeys); len(tags) > maxCustomTags {
+		return fmt.Errorf("at most %d tags are allowed", maxCustomTags)
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	seen := map[string]string{}
+	for _, key := range keys {
+		if err := ValidateTag(key, tags[key]); err != nil {
+			return err
+		}
+		if other, ok := seen[strings.ToLower(key)]; ok {
+			return fmt.Errorf("tag keys %q and %q differ only by case", other, key)
+		}
+		seen[strings.ToLower(key)] = key
+	}
+	return nil
+}
+
+// CustomTags returns the custom tags requested for the role.
+// spec.Tags take precedence over the deprecated iammanager.keikoproj.io/tags annotation which holds
+// "key1=value1;;key2=value2". Annotation entries which cannot be applied are returned as invalid
+func (r *Iamrole) CustomTags() (map[string]string, []string) {
+	tags := map[string]string{}
+	var invalid []string
+
+	if annotation, ok := r.Annotations[config.IamManagerTagsAnnotation]; ok && annotation != "" {
+		for _, entry := range strings.Split(annotation, ";;") {
+			key, value, found := strings.Cut(entry, "=")
+			if !found || ValidateTag(key, value) != nil {
+				invalid = append(invalid, entry)
+				continue
+			}
+			tags[key] = value
+		}
+	}
+
+	for key, value := range r.Spec.Tags {
+		for annotationKey := range tags {
+			if strings.EqualFold(annotationKey, key) {
+				delete(tags, annotationKey)
+			}
+		}
+		tags[key] = value
+	}
+	return tags, invalid
+}
+
+// ServiceAccountAnnotations are set by iam-manager on every IRSA service account and cannot be overridden
+var ServiceAccountAnnotations = []string{
+	"eks.amazonaws.com/role-arn",
+	config.IRSARegionalEndpointAnnotation,
+	config.IamManagerServiceAccountOwnerAnnotation,
+	config.IamManagerManagedAnnotationsAnnotation,
+	config.IamManagerManagedLabelsAnnotation,
+}
+
+// ServiceAccountLabels are set by iam-manager on the IRSA service accounts it creates and cannot be overridden
+var ServiceAccountLabels = []string{config.ManagedByLabel}
+
+// ValidateServiceAccount validates the service account name, annotations and labels
+func ValidateServiceAccount(sa ServiceAccount) error {
+	if errs := validation.IsDNS1123Subdomain(sa.Name); len(errs) > 0 {
+		return fmt.Errorf("service account name %q is invalid: %s", sa.Name, strings.Join(errs, ", "))
+	}
+	for key := range sa.Annotations {
+		if errs := validation.IsQualifiedName(strings.ToLower(key)); len(errs) > 0 {
+			return fmt.Errorf("annotation key %q of service account %s is invalid: %s", key, sa.Name, strings.Join(errs, ", "))
+		}
+		if slices.Contains(ServiceAccountAnnotations, key) {
+			return fmt.Errorf("annotation %s of service account %s is managed by iam-manager", key, sa.Name)
+		}
+	}
+	for key, value := range sa.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("label key %q of service account %s is invalid: %s", key, sa.Name, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("label %s of service account %s has an invalid value: %s", key, sa.Name, strings.Join(errs, ", "))
+		}
+		if slices.Contains(ServiceAccountLabels, key) {
+			return fmt.Errorf("label %s of service account %s is managed by iam-manager", key, sa.Name)
+		}
+	}
+	return nil
+}
+
+// RolePath returns spec.Path. When it is empty, a role which was already created or is adopted keeps the path of its
+// ARN, as IAM can't move roles, and a new role is created at the iam.role.path.prefix config map property
+func (r *Iamrole) RolePath() string {
+	if r.Spec.Path != "" {
+		return r.Spec.Path
+	}
+	for _, roleARN := range []string{r.Status.RoleARN, r.Spec.AdoptRoleARN} {
+		if path, _, ok := splitRoleARN(roleARN); ok {
+			return path
+		}
+	}
+	return config.Props.RolePathPrefix()
+}
+
+// splitRoleARN returns the path and the name of the role of an IAM role ARN
+func splitRoleARN(roleARN string) (string, string, bool) {
+	parsed, err := arn.Parse(roleARN)
+	if err != nil || parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+		return "", "", false
+	}
+	resource := strings.TrimPrefix(parsed.Resource, "role")
+	return resource[:strings.LastIndex(resource, "/")+1], resource[strings.LastIndex(resource, "/")+1:], true
+}
+
+// DeletionPolicy returns spec.DeletionPolicy or the cluster default iam.role.deletion.policy when it is empty
+func (r *Iamrole) DeletionPolicy() DeletionPolicy {
+	if r.Spec.DeletionPolicy != "" {
+		return r.Spec.DeletionPolicy
+	}
+	return DeletionPolicy(config.Props.DeletionPolicy())
+}
+
+// ServiceAccounts returns the IRSA service accounts of the role.
+// Names listed in the iam.amazonaws.com/irsa-service-account annotation come first, followed by spec.ServiceAccounts
+// which replace the annotation entries with the same name
+func (r *Iamrole) ServiceAccounts() []ServiceAccount {
+	var serviceAccounts []ServiceAccount
+	if annotation, ok := r.Annotations[config.IRSAAnnotation]; ok {
+		for _, name := range strings.Split(annotation, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" && !slices.ContainsFunc(serviceAccounts, func(sa ServiceAccount) bool { return sa.Name == name }) {
+				serviceAccounts = append(serviceAccounts, ServiceAccount{Name: name})
+			}
+		}
+	}
+	for _, sa := range r.Spec.ServiceAccounts {
+		if i := slices.IndexFunc(serviceAccounts, func(existing ServiceAccount) bool { return existing.Name == sa.Name }); i >= 0 {
+			serviceAccounts[i] = sa
+			continue
+		}
+		serviceAccounts = append(serviceAccounts, sa)
+	}
+	return serviceAccounts
+}
+
 // IamroleStatus defines the observed state of Iamrole
 type IamroleStatus struct {
 	//RoleName represents the name of the iam role created in AWS
@@ -191,6 +659,55 @@ type IamroleStatus struct {
 	//LastUpdatedTimestamp represents the last time the iam role has been modified
 	// +optional
 	LastUpdatedTimestamp metav1.Time `json:"lastUpdatedTimestamp,omitempty"`
+	//ManagedPolicyArns represents the managed policies attached to the role by iam-manager
+	// +optional
+	ManagedPolicyArns []string `json:"managedPolicyArns,omitempty"`
+	//InlinePolicyNames represents the additional inline policies put on the role by iam-manager
+	// +optional
+	InlinePolicyNames []string `json:"inlinePolicyNames,omitempty"`
+	//TagKeys represents the custom tags attached to the role by iam-manager
+	// +optional
+	TagKeys []string `json:"tagKeys,omitempty"`
+	//ServiceAccounts represents the IRSA service accounts handled by iam-manager
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ServiceAccounts []ServiceAccountStatus `json:"serviceAccounts,omitempty"`
+	//ObservedGeneration represents the generation of the spec the status was computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	//LastDrift represents the last time the iam role in AWS was found different from the spec, before it was reverted
+	// +optional
+	LastDrift *DriftStatus `json:"lastDrift,omitempty"`
+	//Conditions represent the latest observations of the iam role
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// ServiceAccountStatus describes an IRSA service account of the role
+type ServiceAccountStatus struct {
+	//Name of the service account
+	Name string `json:"name"`
+	//Created is true when the service account was created by iam-manager and false when an existing one was patched
+	// +optional
+	Created bool `json:"created,omitempty"`
+	//Synced is true when the service account exists with the expected annotations
+	Synced bool `json:"synced"`
+}
+
+// DriftStatus describes the differences found between the iam role in AWS and the spec
+type DriftStatus struct {
+	//DetectedTimestamp represents the time the drift was found
+	DetectedTimestamp metav1.Time `json:"detectedTimestamp"`
+	//Components which differed from the spec, e.g. policy, trust, boundary or tags
+	Components []string `json:"components"`
+	//Changes made to the iam role outside of iam-manager, e.g. an action added to a statement of an inline policy
+	// +optional
+	Changes []string `json:"changes,omitempty"`
 }
 
 type State string
@@ -203,8 +720,23 @@ const (
 	RoleNameNotAvailable State = "RoleNameNotAvailable"
 )
 
+// Condition types reported on the Iamrole status
+const (
+	// ConditionReady is true when the iam role matches the spec. It mirrors State
+	ConditionReady = "Ready"
+	// ConditionPolicyValid is true when the spec passed policy validation
+	ConditionPolicyValid = "PolicyValid"
+	// ConditionTrustPolicyApplied is true when the trust policy is applied to the iam role
+	ConditionTrustPolicyApplied = "TrustPolicyApplied"
+	// ConditionServiceAccountsSynced is true when the IRSA service accounts carry the role annotations
+	ConditionServiceAccountsSynced = "ServiceAccountsSynced"
+	// ConditionDriftDetected is true when the iam role in AWS was found different from the spec and could not be reverted yet
+	ConditionDriftDetected = "DriftDetected"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 // +kubebuilder:resource:path=iamroles,scope=Namespaced,shortName=iam,singular=iamrole
 // +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state",description="current state of the iam role"
 // +kubebuilder:printcolumn:name="RoleName",type="string",JSONPath=".status.roleName",description="Name of the role"
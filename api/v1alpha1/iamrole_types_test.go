@@ -1,13 +1,23 @@
 package v1alpha1
 
-import "testing"
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/keikoproj/iam-manager/internal/config"
+)
 
 func TestTrustPolicyStatement_Id(t *testing.T) {
 	type fields struct {
 		Effect    Effect
 		Action    string
 		Principal Principal
-		Condition *Condition
+		Condition PolicyCondition
 	}
 	tests := []struct {
 		name   string
@@ -33,9 +43,9 @@ func TestTrustPolicyStatement_Id(t *testing.T) {
 				Principal: Principal{
 					Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
 				},
-				Condition: &Condition{
-					StringEquals: map[string]string{
-						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": "system:serviceaccount:my-namespace:my-serviceaccount",
+				Condition: PolicyCondition{
+					"StringEquals": {
+						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": {"system:serviceaccount:my-namespace:my-serviceaccount"},
 					},
 				},
 			},
@@ -62,9 +72,9 @@ func TestTrustPolicyStatement_Id(t *testing.T) {
 				Principal: Principal{
 					Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
 				},
-				Condition: &Condition{
-					StringLike: map[string]string{
-						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": "system:serviceaccount:*:*",
+				Condition: PolicyCondition{
+					"StringLike": {
+						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": {"system:serviceaccount:*:*"},
 					},
 				},
 			},
@@ -78,14 +88,45 @@ func TestTrustPolicyStatement_Id(t *testing.T) {
 				Principal: Principal{
 					Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
 				},
-				Condition: &Condition{
-					StringLike: map[string]string{
-						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": "system:serviceaccount:*:default",
+				Condition: PolicyCondition{
+					"StringLike": {
+						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": {"system:serviceaccount:*:default"},
 					},
 				},
 			},
 			want: "AllowStsAssumeRoleWithWebIdentityef522ae8a57730a3",
 		},
+		{
+			name: "test6 - multiple service accounts and richer operators",
+			fields: fields{
+				Effect: "Allow",
+				Action: "sts:AssumeRoleWithWebIdentity",
+				Principal: Principal{
+					Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
+				},
+				Condition: PolicyCondition{
+					"StringEquals": {
+						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": {"system:serviceaccount:my-ns:sa1", "system:serviceaccount:my-ns:sa2"},
+					},
+					"ArnLike": {
+						"aws:SourceArn": {"arn:aws:eks:us-east-2:123456789012:cluster/*"},
+					},
+				},
+			},
+			want: "AllowStsAssumeRoleWithWebIdentityef522ae844494d0c",
+		},
+		{
+			name: "test4: an empty condition block keeps the id of the former empty Condition struct",
+			fields: fields{
+				Effect: "Allow",
+				Action: "sts:AssumeRoleWithWebIdentity",
+				Principal: Principal{
+					Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
+				},
+				Condition: PolicyCondition{},
+			},
+			want: "AllowStsAssumeRoleWithWebIdentityef522ae8137d0e57",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -107,7 +148,7 @@ func TestTrustPolicyStatement_HasCondition(t *testing.T) {
 		Effect    Effect
 		Action    string
 		Principal Principal
-		Condition *Condition
+		Condition PolicyCondition
 	}
 	tests := []struct {
 		name   string
@@ -133,14 +174,26 @@ func TestTrustPolicyStatement_HasCondition(t *testing.T) {
 				Principal: Principal{
 					Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
 				},
-				Condition: &Condition{
-					StringEquals: map[string]string{
-						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": "system:serviceaccount:my-namespace:my-serviceaccount",
+				Condition: PolicyCondition{
+					"StringEquals": {
+						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": {"system:serviceaccount:my-namespace:my-serviceaccount"},
 					},
 				},
 			},
 			want: true,
 		},
+		{
+			name: "test3: with an empty condition block",
+			fields: fields{
+				Effect: "Allow",
+				Action: "sts:AssumeRoleWithWebIdentity",
+				Principal: Principal{
+					Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
+				},
+				Condition: PolicyCondition{},
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -162,7 +215,7 @@ func TestTrustPolicyStatement_ConditionChecksum(t *testing.T) {
 		Effect    Effect
 		Action    string
 		Principal Principal
-		Condition *Condition
+		Condition PolicyCondition
 	}
 	tests := []struct {
 		name   string
@@ -188,14 +241,26 @@ func TestTrustPolicyStatement_ConditionChecksum(t *testing.T) {
 				Principal: Principal{
 					Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
 				},
-				Condition: &Condition{
-					StringEquals: map[string]string{
-						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": "system:serviceaccount:my-namespace:my-serviceaccount",
+				Condition: PolicyCondition{
+					"StringEquals": {
+						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": {"system:serviceaccount:my-namespace:my-serviceaccount"},
 					},
 				},
 			},
 			want: "d16a3945",
 		},
+		{
+			name: "test3: an empty condition block keeps the checksum of the former empty Condition struct",
+			fields: fields{
+				Effect: "Allow",
+				Action: "sts:AssumeRoleWithWebIdentity",
+				Principal: Principal{
+					Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
+				},
+				Condition: PolicyCondition{},
+			},
+			want: "137d0e57",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -217,7 +282,7 @@ func TestTrustPolicyStatement_IsConditionAnyServiceAccount(t *testing.T) {
 		Effect    Effect
 		Action    string
 		Principal Principal
-		Condition *Condition
+		Condition PolicyCondition
 	}
 	tests := []struct {
 		name   string
@@ -243,9 +308,9 @@ func TestTrustPolicyStatement_IsConditionAnyServiceAccount(t *testing.T) {
 				Principal: Principal{
 					Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
 				},
-				Condition: &Condition{
-					StringLike: map[string]string{
-						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": "system:serviceaccount:*:*",
+				Condition: PolicyCondition{
+					"StringLike": {
+						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": {"system:serviceaccount:*:*"},
 					},
 				},
 			},
@@ -259,9 +324,9 @@ func TestTrustPolicyStatement_IsConditionAnyServiceAccount(t *testing.T) {
 				Principal: Principal{
 					Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
 				},
-				Condition: &Condition{
-					StringLike: map[string]string{
-						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": "system:serviceaccount:*:default",
+				Condition: PolicyCondition{
+					"StringLike": {
+						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": {"system:serviceaccount:*:default"},
 					},
 				},
 			},
@@ -275,9 +340,9 @@ func TestTrustPolicyStatement_IsConditionAnyServiceAccount(t *testing.T) {
 				Principal: Principal{
 					Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
 				},
-				Condition: &Condition{
-					StringEquals: map[string]string{
-						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": "system:serviceaccount:my-ns:default",
+				Condition: PolicyCondition{
+					"StringEquals": {
+						"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": {"system:serviceaccount:my-ns:default"},
 					},
 				},
 			},
@@ -298,3 +363,410 @@ func TestTrustPolicyStatement_IsConditionAnyServiceAccount(t *testing.T) {
 		})
 	}
 }
+
+func TestIsValidConditionOperator(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		want     bool
+	}{
+		{name: "string equals", operator: "StringEquals", want: true},
+		{name: "for any value prefix", operator: "ForAnyValue:StringLike", want: true},
+		{name: "for all values prefix", operator: "ForAllValues:StringEquals", want: true},
+		{name: "if exists suffix", operator: "ArnLikeIfExists", want: true},
+		{name: "ip address", operator: "IpAddress", want: true},
+		{name: "null", operator: "Null", want: true},
+		{name: "null if exists", operator: "NullIfExists", want: false},
+		{name: "if exists only", operator: "IfExists", want: false},
+		{name: "unknown operator", operator: "StringMatches", want: false},
+		{name: "unknown prefix", operator: "ForSomeValues:StringLike", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValidConditionOperator(tt.operator); got != tt.want {
+				t.Errorf("IsValidConditionOperator(%s) = %v, want %v", tt.operator, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyCondition_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition PolicyCondition
+		wantErr   bool
+	}{
+		{
+			name:      "nil condition",
+			condition: nil,
+			wantErr:   false,
+		},
+		{
+			name: "valid condition",
+			condition: PolicyCondition{
+				"StringEquals": {
+					"aws:SourceVpce": {"vpce-1a2b3c4d"},
+				},
+				"ForAnyValue:StringLike": {
+					"s3:prefix": {"home/*", "shared/*"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid operator",
+			condition: PolicyCondition{
+				"StringMatches": {
+					"aws:SourceVpce": {"vpce-1a2b3c4d"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "operator without keys",
+			condition: PolicyCondition{
+				"StringEquals": {},
+			},
+			wantErr: true,
+		},
+		{
+			name: "key without values",
+			condition: PolicyCondition{
+				"StringEquals": {
+					"aws:RequestedRegion": {},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.condition.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyCondition_UnmarshalScalarValues(t *testing.T) {
+	// Iamroles written before conditions accepted lists hold a single string per condition key
+	spec := `{
+		"PolicyDocument": {
+			"Statement": [{
+				"Effect": "Allow",
+				"Action": ["s3:GetObject"],
+				"Resource": ["*"],
+				"Condition": {"StringEquals": {"aws:SourceVpce": "vpce-1a2b3c4d"}}
+			}]
+		},
+		"AssumeRolePolicyDocument": {
+			"Statement": [{
+				"Effect": "Allow",
+				"Action": "sts:AssumeRoleWithWebIdentity",
+				"Condition": {
+					"StringEquals": {"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLE:sub": "system:serviceaccount:my-ns:sa1"},
+					"StringLike": {"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLE:aud": ["sts.amazonaws.com", "sts.*"]}
+				}
+			}]
+		}
+	}`
+	var got IamroleSpec
+	if err := json.Unmarshal([]byte(spec), &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	wantPermission := PolicyCondition{
+		"StringEquals": {"aws:SourceVpce": {"vpce-1a2b3c4d"}},
+	}
+	if !reflect.DeepEqual(got.PolicyDocument.Statement[0].Condition, wantPermission) {
+		t.Errorf("permission policy Condition = %v, want %v", got.PolicyDocument.Statement[0].Condition, wantPermission)
+	}
+	wantTrust := PolicyCondition{
+		"StringEquals": {"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLE:sub": {"system:serviceaccount:my-ns:sa1"}},
+		"StringLike":   {"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLE:aud": {"sts.amazonaws.com", "sts.*"}},
+	}
+	if !reflect.DeepEqual(got.AssumeRolePolicyDocument.Statement[0].Condition, wantTrust) {
+		t.Errorf("trust policy Condition = %v, want %v", got.AssumeRolePolicyDocument.Statement[0].Condition, wantTrust)
+	}
+	for _, condition := range []PolicyCondition{got.PolicyDocument.Statement[0].Condition, got.AssumeRolePolicyDocument.Statement[0].Condition} {
+		if err := condition.Validate(); err != nil {
+			t.Errorf("Validate() error = %v", err)
+		}
+	}
+}
+
+func TestTrustPolicyStatement_IdIsValueOrderInsensitive(t *testing.T) {
+	principal := Principal{
+		Federated: "arn:aws:iam::123456789012:oidc-provider/oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041",
+	}
+	tps1 := &TrustPolicyStatement{
+		Effect:    "Allow",
+		Action:    "sts:AssumeRoleWithWebIdentity",
+		Principal: principal,
+		Condition: PolicyCondition{
+			"StringEquals": {
+				"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": {"system:serviceaccount:my-ns:sa1", "system:serviceaccount:my-ns:sa2"},
+			},
+		},
+	}
+	tps2 := &TrustPolicyStatement{
+		Effect:    "Allow",
+		Action:    "sts:AssumeRoleWithWebIdentity",
+		Principal: principal,
+		Condition: PolicyCondition{
+			"StringEquals": {
+				"oidc.eks.us-east-2.amazonaws.com/id/EXAMPLED539D4633E53DE1B716D3041:sub": {"system:serviceaccount:my-ns:sa2", "system:serviceaccount:my-ns:sa1"},
+			},
+		},
+	}
+	if tps1.Id() != tps2.Id() {
+		t.Errorf("Id() = %v and %v, want equal ids", tps1.Id(), tps2.Id())
+	}
+}
+
+func TestStatement_ValidateElements(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement Statement
+		wantErr   bool
+	}{
+		{
+			name:      "action and resource",
+			statement: Statement{Effect: "Allow", Action: []string{"s3:GetObject"}, Resource: []string{"*"}},
+			wantErr:   false,
+		},
+		{
+			name:      "not action and not resource",
+			statement: Statement{Effect: "Deny", NotAction: []string{"s3:GetObject"}, NotResource: []string{"arn:aws:s3:::bucket"}},
+			wantErr:   false,
+		},
+		{
+			name:      "action and not action",
+			statement: Statement{Effect: "Deny", Action: []string{"s3:GetObject"}, NotAction: []string{"iam:*"}, Resource: []string{"*"}},
+			wantErr:   true,
+		},
+		{
+			name:      "resource and not resource",
+			statement: Statement{Effect: "Deny", Action: []string{"s3:GetObject"}, Resource: []string{"*"}, NotResource: []string{"arn:aws:s3:::bucket"}},
+			wantErr:   true,
+		},
+		{
+			name:      "missing action",
+			statement: Statement{Effect: "Allow", Resource: []string{"*"}},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.statement.ValidateElements(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateElements() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStatement_ExcludesResourcesContaining(t *testing.T) {
+	statement := Statement{NotResource: []string{"*restricted*", "arn:aws:s3:::bucket"}}
+	if !statement.ExcludesResourcesContaining("restricted") {
+		t.Errorf("ExcludesResourcesContaining() = false, want true")
+	}
+	if statement.ExcludesResourcesContaining("bucket") {
+		t.Errorf("ExcludesResourcesContaining() = true, want false")
+	}
+	if !statement.ExcludesResource("arn:aws:s3:::bucket") {
+		t.Errorf("ExcludesResource() = false, want true")
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	tooMany := map[string]string{}
+	for i := 0; i < 48; i++ {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+	tests := []struct {
+		name    string
+		tags    map[string]string
+		wantErr bool
+	}{
+		{name: "no tags", tags: nil, wantErr: false},
+		{name: "valid tags", tags: map[string]string{"team": "payments", "cost-center": "a1:b2/c3", "empty": ""}, wantErr: false},
+		{name: "reserved key", tags: map[string]string{"Namespace": "other"}, wantErr: true},
+		{name: "reserved key with different case", tags: map[string]string{"managedby": "me"}, wantErr: true},
+		{name: "aws prefix", tags: map[string]string{"aws:team": "payments"}, wantErr: true},
+		{name: "invalid characters", tags: map[string]string{"team": "pay#ments"}, wantErr: true},
+		{name: "key too long", tags: map[string]string{strings.Repeat("k", 129): "v"}, wantErr: true},
+		{name: "value too long", tags: map[string]string{"team": strings.Repeat("v", 257)}, wantErr: true},
+		{name: "keys differ only by case", tags: map[string]string{"team": "a", "Team": "b"}, wantErr: true},
+		{name: "too many tags", tags: tooMany, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTags(tt.tags); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIamrole_CustomTags(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		tags        map[string]string
+		want        map[string]string
+		wantInvalid []string
+	}{
+		{
+			name: "spec tags only",
+			tags: map[string]string{"team": "payments"},
+			want: map[string]string{"team": "payments"},
+		},
+		{
+			name:        "annotation fallback",
+			annotations: map[string]string{"iammanager.keikoproj.io/tags": "team=payments;;query=a=b"},
+			want:        map[string]string{"team": "payments", "query": "a=b"},
+		},
+		{
+			name:        "spec tags take precedence",
+			annotations: map[string]string{"iammanager.keikoproj.io/tags": "Team=orders;;env=dev"},
+			tags:        map[string]string{"team": "payments"},
+			want:        map[string]string{"team": "payments", "env": "dev"},
+		},
+		{
+			name:        "invalid annotation entries",
+			annotations: map[string]string{"iammanager.keikoproj.io/tags": "team;;Cluster=other;;env=dev"},
+			want:        map[string]string{"env": "dev"},
+			wantInvalid: []string{"team", "Cluster=other"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Iamrole{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec:       IamroleSpec{Tags: tt.tags},
+			}
+			got, invalid := r.CustomTags()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CustomTags() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(invalid, tt.wantInvalid) {
+				t.Errorf("CustomTags() invalid = %v, want %v", invalid, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestValidateServiceAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		sa      ServiceAccount
+		wantErr bool
+	}{
+		{name: "valid", sa: ServiceAccount{Name: "app", Annotations: map[string]string{"example.com/team": "orders"}, Labels: map[string]string{"app": "orders"}}},
+		{name: "invalid name", sa: ServiceAccount{Name: "App_1"}, wantErr: true},
+		{name: "invalid annotation key", sa: ServiceAccount{Name: "app", Annotations: map[string]string{"bad key": "v"}}, wantErr: true},
+		{name: "role arn annotation is managed", sa: ServiceAccount{Name: "app", Annotations: map[string]string{"eks.amazonaws.com/role-arn": "arn"}}, wantErr: true},
+		{name: "regional endpoint annotation is managed", sa: ServiceAccount{Name: "app", Annotations: map[string]string{"eks.amazonaws.com/sts-regional-endpoints": "false"}}, wantErr: true},
+		{name: "invalid label value", sa: ServiceAccount{Name: "app", Labels: map[string]string{"app": "not valid"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateServiceAccount(tt.sa); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateServiceAccount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIamrole_ServiceAccounts(t *testing.T) {
+	noCreate := false
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		serviceAccounts []ServiceAccount
+		want            []ServiceAccount
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:        "annotation only",
+			annotations: map[string]string{"iam.amazonaws.com/irsa-service-account": "app, worker,,app"},
+			want:        []ServiceAccount{{Name: "app"}, {Name: "worker"}},
+		},
+		{
+			name:            "spec entries replace annotation entries",
+			annotations:     map[string]string{"iam.amazonaws.com/irsa-service-account": "app,worker"},
+			serviceAccounts: []ServiceAccount{{Name: "worker", CreateIfMissing: &noCreate}, {Name: "batch"}},
+			want:            []ServiceAccount{{Name: "app"}, {Name: "worker", CreateIfMissing: &noCreate}, {Name: "batch"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Iamrole{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec:       IamroleSpec{ServiceAccounts: tt.serviceAccounts},
+			}
+			if got := r.ServiceAccounts(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ServiceAccounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIamrole_RolePath(t *testing.T) {
+	if err := config.LoadProperties("", &v1.ConfigMap{Data: map[string]string{
+		"aws.accountId":          "123456789012",
+		"iam.role.path.prefix":   "/k8s/my-cluster/",
+		"iam.role.adopt.allowed": "k8s-*",
+	}}); err != nil {
+		t.Fatalf("LoadProperties() error = %v", err)
+	}
+	defer func() {
+		if err := config.LoadProperties("LOCAL"); err != nil {
+			t.Fatalf("LoadProperties() error = %v", err)
+		}
+	}()
+
+	tests := []struct {
+		name   string
+		spec   IamroleSpec
+		status IamroleStatus
+		want   string
+	}{
+		{
+			name: "spec path",
+			spec: IamroleSpec{Path: "/k8s/my-cluster/team/"},
+			want: "/k8s/my-cluster/team/",
+		},
+		{
+			name: "new role without a path is created at the prefix",
+			want: "/k8s/my-cluster/",
+		},
+		{
+			name:   "created role keeps its path",
+			status: IamroleStatus{RoleARN: "arn:aws:iam::123456789012:role/k8s-app"},
+			want:   "/",
+		},
+		{
+			name: "adopted role keeps its path",
+			spec: IamroleSpec{AdoptRoleARN: "arn:aws:iam::123456789012:role/k8s/my-cluster/legacy/k8s-app"},
+			want: "/k8s/my-cluster/legacy/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Iamrole{Spec: tt.spec, Status: tt.status}
+			if got := r.RolePath(); got != tt.want {
+				t.Errorf("RolePath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ValidateAdoptRoleARN("arn:aws:iam::123456789012:role/k8s-app", ""); err == nil || !strings.Contains(err.Error(), "is not under path") {
+		t.Errorf("ValidateAdoptRoleARN() error = %v, want a path prefix error", err)
+	}
+	if _, err := ValidateAdoptRoleARN("arn:aws:iam::123456789012:role/k8s/my-cluster/k8s-app", ""); err != nil {
+		t.Errorf("ValidateAdoptRoleARN() error = %v", err)
+	}
+}
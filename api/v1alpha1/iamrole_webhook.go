@@ -17,43 +17,53 @@ package v1alpha1
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"regexp"
 	"strings"
 
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	validationutils "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/keikoproj/iam-manager/internal/config"
-	"github.com/keikoproj/iam-manager/pkg/k8s"
 	"github.com/keikoproj/iam-manager/pkg/logging"
+	"github.com/keikoproj/iam-manager/pkg/tracing"
 )
 
 const (
 	version = "2012-10-17"
 )
 
-var wClient *k8s.Client
+// IamroleNamespaceIndex indexes Iamroles by namespace so the webhook counts them from the manager cache
+const IamroleNamespaceIndex = "iamrole.namespace"
 
-func NewWClient() {
-	log := logging.Logger(context.Background(), "v1alpha1", "NewWClient")
-	log.Info("loading k8s client")
-	k8sClient, err := k8s.NewK8sClient()
-	if err != nil {
-		log.Error(err, "unable to create new k8s client")
-		panic(err)
-	}
-	wClient = k8sClient
+// IamroleValidator validates the Iamroles. Client reads the Iamroles of the namespace to enforce the
+// role limits, the webhook uses the manager's cached client
+// +kubebuilder:object:generate=false
+type IamroleValidator struct {
+	Client client.Reader
+}
+
+// IndexIamroleNamespace returns the namespace of an Iamrole for the IamroleNamespaceIndex
+func IndexIamroleNamespace(obj client.Object) []string {
+	return []string{obj.GetNamespace()}
 }
 
 func (r *Iamrole) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &Iamrole{}, IamroleNamespaceIndex, IndexIamroleNamespace); err != nil {
+		return err
+	}
 	return ctrl.NewWebhookManagedBy(mgr, &Iamrole{}).
 		WithDefaulter(r).
-		WithValidator(r).
+		WithValidator(&IamroleValidator{Client: mgr.GetClient()}).
 		Complete()
 }
 
@@ -61,76 +71,184 @@ func (r *Iamrole) SetupWebhookWithManager(mgr ctrl.Manager) error {
 
 // Default implements webhook mutating admission so a webhook will be registered for the type
 func (r *Iamrole) Default(ctx context.Context, obj *Iamrole) error {
-	log := logging.Logger(ctx, "v1alpha1", "Default")
-	log.Info("setting default version", "name", obj.Name)
+	log := logging.Logger(admissionContext(ctx, obj), "v1alpha1", "Default")
+	log.Info("setting default version")
 
-	//Set the default value for Version
-	if obj.Spec.PolicyDocument.Version == "" {
-		obj.Spec.PolicyDocument.Version = version
-	}
+	obj.setDefaultVersions()
+	obj.setLastModifiedBy(ctx)
 	return nil
 }
 
+// setDefaultVersions sets the default value for Version
+func (r *Iamrole) setDefaultVersions() {
+	if r.Spec.PolicyDocument.Version == "" {
+		r.Spec.PolicyDocument.Version = version
+	}
+	for i := range r.Spec.InlinePolicies {
+		if r.Spec.InlinePolicies[i].PolicyDocument.Version == "" {
+			r.Spec.InlinePolicies[i].PolicyDocument.Version = version
+		}
+	}
+}
+
+// setLastModifiedBy records the user of the admission request in the last-modified-by annotation for the audit log
+// when the Iamrole changes. Otherwise the previous value is kept, so it can't be changed by hand
+func (r *Iamrole) setLastModifiedBy(ctx context.Context) {
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return
+	}
+	lastModifiedBy := req.UserInfo.Username
+	if req.Operation == admissionv1.Update {
+		old := &Iamrole{}
+		// The old Iamrole may predate the webhook, so it is defaulted the same way before comparing
+		err := json.Unmarshal(req.OldObject.Raw, old)
+		old.setDefaultVersions()
+		if err == nil && !r.changedFrom(old) {
+			lastModifiedBy = old.Annotations[config.IamManagerLastModifiedByAnnotation]
+		}
+	}
+	if lastModifiedBy == "" {
+		delete(r.Annotations, config.IamManagerLastModifiedByAnnotation)
+		return
+	}
+	if r.Annotations == nil {
+		r.Annotations = map[string]string{}
+	}
+	r.Annotations[config.IamManagerLastModifiedByAnnotation] = lastModifiedBy
+}
+
+// changedFrom tells if the spec or the annotations, which also shape the role, differ from the old Iamrole
+func (r *Iamrole) changedFrom(old *Iamrole) bool {
+	if !equality.Semantic.DeepEqual(r.Spec, old.Spec) {
+		return true
+	}
+	annotations, oldAnnotations := maps.Clone(r.Annotations), maps.Clone(old.Annotations)
+	delete(annotations, config.IamManagerLastModifiedByAnnotation)
+	delete(oldAnnotations, config.IamManagerLastModifiedByAnnotation)
+	return !maps.Equal(annotations, oldAnnotations)
+}
+
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
 // +kubebuilder:webhook:verbs=create;update,path=/validate-iammanager-keikoproj-io-v1alpha1-iamrole,mutating=false,failurePolicy=fail,groups=iammanager.keikoproj.io,resources=iamroles,versions=v1alpha1,name=viamrole.kb.io,sideEffects=none,admissionReviewVersions=v1
 
 // ValidateCreate implements webhook validating admission so a webhook will be registered for the type
-func (r *Iamrole) ValidateCreate(ctx context.Context, obj *Iamrole) (admission.Warnings, error) {
+func (v *IamroleValidator) ValidateCreate(ctx context.Context, obj *Iamrole) (_ admission.Warnings, err error) {
+	ctx, span := tracing.Start(admissionContext(ctx, obj), "ValidateCreate")
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "v1alpha1", "ValidateCreate")
-	log.Info("validating create request", "name", obj.Name)
+	log.Info("validating create request")
 
-	return []string{}, obj.validateIAMPolicy(false)
+	warnings, err := obj.validateIAMPolicy(ctx, v.Client, false)
+	return append(obj.tagsAnnotationWarnings(), warnings...), err
 }
 
 // ValidateUpdate implements webhook validating admission so a webhook will be registered for the type
-func (r *Iamrole) ValidateUpdate(ctx context.Context, oldObj, newObj *Iamrole) (admission.Warnings, error) {
+func (v *IamroleValidator) ValidateUpdate(ctx context.Context, oldObj, newObj *Iamrole) (_ admission.Warnings, err error) {
+	ctx, span := tracing.Start(admissionContext(ctx, newObj), "ValidateUpdate")
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "v1alpha1", "ValidateUpdate")
-	log.Info("validate update", "name", newObj.Name)
+	log.Info("validate update")
 
-	return []string{}, newObj.validateIAMPolicy(true)
+	warnings, err := newObj.validateIAMPolicy(ctx, v.Client, true)
+	return append(newObj.tagsAnnotationWarnings(), warnings...), err
 }
 
 // ValidateDelete implements webhook validating admission so a webhook will be registered for the type
-func (r *Iamrole) ValidateDelete(ctx context.Context, obj *Iamrole) (admission.Warnings, error) {
-	log := logging.Logger(ctx, "v1alpha1", "ValidateDelete")
-	log.Info("validate delete", "name", obj.Name)
+func (v *IamroleValidator) ValidateDelete(ctx context.Context, obj *Iamrole) (admission.Warnings, error) {
+	log := logging.Logger(admissionContext(ctx, obj), "v1alpha1", "ValidateDelete")
+	log.Info("validate delete")
 
 	// TODO(user): fill in your validation logic upon object deletion.
 	return []string{}, nil
 }
 
-func (r *Iamrole) validateIAMPolicy(isItUpdate bool) error {
-	log := logging.Logger(context.Background(), "v1alpha1", "validateIAMPolicy")
-	log.Info("validating IAM policy", "name", r.Name)
+// admissionContext returns the context of an admission request for the Iamrole. Its logs and spans carry the uid
+// of the admission request as request id and the namespace and name of the Iamrole
+func admissionContext(ctx context.Context, obj *Iamrole) context.Context {
+	if req, err := admission.RequestFromContext(ctx); err == nil {
+		ctx = logging.WithRequestID(ctx, string(req.UID))
+	}
+	ctx = logging.WithValues(ctx, "namespace", obj.Namespace, "iamrole", obj.Name)
+	return tracing.WithAttributes(ctx, tracing.NamespaceKey.String(obj.Namespace), tracing.IamroleKey.String(obj.Name))
+}
+
+func (r *Iamrole) validateIAMPolicy(ctx context.Context, c client.Reader, isItUpdate bool) (admission.Warnings, error) {
+	log := logging.Logger(ctx, "v1alpha1", "validateIAMPolicy")
+	log.Info("validating IAM policy")
 	var allErrs field.ErrorList
 	if err := r.validateCustomResourceName(); err != nil {
 		allErrs = append(allErrs, err)
 	}
-	if err := r.validateIAMPolicyAction(); err != nil {
+	if err := r.validateInlinePolicyNames(); err != nil {
 		allErrs = append(allErrs, err)
 	}
-	if err := r.validateIAMPolicyResource(); err != nil {
+	// PolicyDocument and every additional inline policy go through the same checks
+	paths := []*field.Path{field.NewPath("spec").Child("PolicyDocument")}
+	pDocs := []PolicyDocument{r.Spec.PolicyDocument}
+	for i, inlinePolicy := range r.Spec.InlinePolicies {
+		paths = append(paths, field.NewPath("spec").Child("InlinePolicies").Index(i).Child("PolicyDocument"))
+		pDocs = append(pDocs, inlinePolicy.PolicyDocument)
+	}
+	for i := range pDocs {
+		if err := r.validateIAMPolicyAction(paths[i], pDocs[i]); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		if err := r.validateIAMPolicyResource(paths[i], pDocs[i]); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		if err := r.validateIAMPolicyCondition(paths[i], pDocs[i]); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	if err := r.validateAssumeRolePolicyCondition(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := r.validateManagedPolicyArns(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := r.validateTags(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := r.validateMaxSessionDuration(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := r.validateRolePath(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := r.validateAdoptRoleARN(); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := r.validateServiceAccounts(); err != nil {
 		allErrs = append(allErrs, err)
 	}
 
-	if err := r.validateNumberOfRoles(isItUpdate); err != nil {
+	warnings, err := r.validateNumberOfRoles(ctx, c, isItUpdate)
+	if err != nil {
 		allErrs = append(allErrs, err)
 	}
 	if err := r.validateRoleNameSuffixAnnotation(); err != nil {
 		allErrs = append(allErrs, err)
 	}
 	if len(allErrs) == 0 {
-		return nil
+		return warnings, nil
 	}
 
-	return apierrors.NewInvalid(
+	return warnings, apierrors.NewInvalid(
 		schema.GroupKind{Group: "iammanager.keikoproj.io", Kind: "Iamrole"},
 		r.Name, allErrs)
 }
 
-func (r *Iamrole) validateIAMPolicyAction() *field.Error {
+func (r *Iamrole) validateIAMPolicyAction(path *field.Path, pDoc PolicyDocument) *field.Error {
 	//Check the incoming policy actions
-	for _, statement := range r.Spec.PolicyDocument.Statement {
+	for i, statement := range pDoc.Statement {
+		if err := statement.ValidateElements(); err != nil {
+			return field.Invalid(path.Child("Statement").Index(i), statement.Sid, err.Error())
+		}
+		//NotAction in an Allow statement grants every action outside of the list which bypasses the allowed action prefixes
+		if statement.Effect == "Allow" && len(statement.NotAction) > 0 && !config.Props.IsNotActionAllowed() {
+			return field.Forbidden(path.Child("Statement").Index(i).Child("NotAction"), "NotAction is not allowed in Allow statements")
+		}
 		for _, action := range statement.Action {
 			isAllowed := false
 			for _, prefix := range config.Props.AllowedPolicyAction() {
@@ -143,10 +261,15 @@ func (r *Iamrole) validateIAMPolicyAction() *field.Error {
 			}
 			//This line shouldn't be executed unless if there is restricted action or end of the loop
 			if !isAllowed {
-				return field.Forbidden(field.NewPath("spec").Child("PolicyDocument").Child("Action"), fmt.Sprintf("restricted action %s included in the request", action))
+				return field.Forbidden(path.Child("Action"), fmt.Sprintf("restricted action %s included in the request", action))
 			}
 			//This is special case-- May be only for Intuit
 			if strings.HasPrefix(action, "s3:") {
+				for _, res := range config.Props.RestrictedS3Resources() {
+					if statement.Effect == "Allow" && len(statement.NotResource) > 0 && res != "" && !statement.ExcludesResource(res) {
+						return field.Forbidden(path.Child("Statement").Index(i).Child("NotResource"), fmt.Sprintf("NotResource must exclude restricted resource %s", res))
+					}
+				}
 				for _, resource := range statement.Resource {
 					for _, res := range config.Props.RestrictedS3Resources() {
 						isAllowed := false
@@ -157,7 +280,7 @@ func (r *Iamrole) validateIAMPolicyAction() *field.Error {
 
 						//This line shouldn't be executed unless if there is restricted action or end of the loop
 						if !isAllowed {
-							return field.Forbidden(field.NewPath("spec").Child("PolicyDocument").Child("Resource"), fmt.Sprintf("restricted resource %s included in the request", resource))
+							return field.Forbidden(path.Child("Resource"), fmt.Sprintf("restricted resource %s included in the request", resource))
 						}
 					}
 				}
@@ -167,9 +290,17 @@ func (r *Iamrole) validateIAMPolicyAction() *field.Error {
 	return nil
 }
 
-func (r *Iamrole) validateIAMPolicyResource() *field.Error {
+func (r *Iamrole) validateIAMPolicyResource(path *field.Path, pDoc PolicyDocument) *field.Error {
 	//Check the incoming policy resource
-	for _, statement := range r.Spec.PolicyDocument.Statement {
+	for i, statement := range pDoc.Statement {
+		//NotResource in an Allow statement matches every resource outside of the list, so it must exclude all the restricted ones
+		if statement.Effect == "Allow" && len(statement.NotResource) > 0 {
+			for _, res := range config.Props.RestrictedPolicyResources() {
+				if res != "" && !statement.ExcludesResourcesContaining(res) {
+					return field.Forbidden(path.Child("Statement").Index(i).Child("NotResource"), fmt.Sprintf("NotResource must exclude restricted resource %s", res))
+				}
+			}
+		}
 		for _, resource := range statement.Resource {
 			isAllowed := true
 			for _, res := range config.Props.RestrictedPolicyResources() {
@@ -181,13 +312,116 @@ func (r *Iamrole) validateIAMPolicyResource() *field.Error {
 			}
 			//This line shouldn't be executed unless if there is restricted action or end of the loop
 			if !isAllowed {
-				return field.Forbidden(field.NewPath("spec").Child("PolicyDocument").Child("Resource"), fmt.Sprintf("restricted resource %s included in the request", resource))
+				return field.Forbidden(path.Child("Resource"), fmt.Sprintf("restricted resource %s included in the request", resource))
 			}
 		}
 	}
 	return nil
 }
 
+func (r *Iamrole) validateIAMPolicyCondition(path *field.Path, pDoc PolicyDocument) *field.Error {
+	//Check the incoming permission policy conditions
+	for i, statement := range pDoc.Statement {
+		if err := statement.Condition.Validate(); err != nil {
+			return field.Invalid(path.Child("Statement").Index(i).Child("Condition"), statement.Condition, err.Error())
+		}
+	}
+	return nil
+}
+
+func (r *Iamrole) validateAssumeRolePolicyCondition() *field.Error {
+	//Check the incoming trust policy conditions
+	if r.Spec.AssumeRolePolicyDocument != nil {
+		for i, statement := range r.Spec.AssumeRolePolicyDocument.Statement {
+			if err := statement.Condition.Validate(); err != nil {
+				return field.Invalid(field.NewPath("spec").Child("AssumeRolePolicyDocument").Child("Statement").Index(i).Child("Condition"), statement.Condition, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Iamrole) validateInlinePolicyNames() *field.Error {
+	//The name of the inline policy created from PolicyDocument is reserved
+	for i, inlinePolicy := range r.Spec.InlinePolicies {
+		if inlinePolicy.Name == config.InlinePolicyName {
+			return field.Invalid(field.NewPath("spec").Child("InlinePolicies").Index(i).Child("Name"), inlinePolicy.Name, fmt.Sprintf("inline policy name %s is reserved", config.InlinePolicyName))
+		}
+	}
+	return nil
+}
+
+func (r *Iamrole) validateManagedPolicyArns() *field.Error {
+	//Check the incoming managed policies against the allow list
+	for i, policyArn := range r.Spec.ManagedPolicyArns {
+		if !config.Props.IsManagedPolicyAllowed(policyArn) {
+			return field.Forbidden(field.NewPath("spec").Child("ManagedPolicyArns").Index(i), fmt.Sprintf("managed policy %s is not allowed to be attached", policyArn))
+		}
+	}
+	return nil
+}
+
+func (r *Iamrole) validateTags() *field.Error {
+	if err := ValidateTags(r.Spec.Tags); err != nil {
+		return field.Invalid(field.NewPath("spec").Child("Tags"), r.Spec.Tags, err.Error())
+	}
+	return nil
+}
+
+func (r *Iamrole) validateServiceAccounts() *field.Error {
+	for i, sa := range r.Spec.ServiceAccounts {
+		if err := ValidateServiceAccount(sa); err != nil {
+			return field.Invalid(field.NewPath("spec").Child("ServiceAccounts").Index(i), sa.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// tagsAnnotationWarnings warns about the deprecated tags annotation and the entries which are not applied
+func (r *Iamrole) tagsAnnotationWarnings() admission.Warnings {
+	warnings := admission.Warnings{}
+	if _, ok := r.Annotations[config.IamManagerTagsAnnotation]; !ok {
+		return warnings
+	}
+	warnings = append(warnings, fmt.Sprintf("annotation %s is deprecated, use spec.Tags instead", config.IamManagerTagsAnnotation))
+	if _, invalid := r.CustomTags(); len(invalid) > 0 {
+		warnings = append(warnings, fmt.Sprintf("annotation %s entries %q are ignored", config.IamManagerTagsAnnotation, invalid))
+	}
+	return warnings
+}
+
+func (r *Iamrole) validateMaxSessionDuration() *field.Error {
+	//Empty value falls back to the configured maximum
+	if r.Spec.MaxSessionDuration == 0 {
+		return nil
+	}
+	if !config.Props.IsSessionDurationAllowed(r.Spec.MaxSessionDuration) {
+		return field.Invalid(field.NewPath("spec").Child("MaxSessionDuration"), r.Spec.MaxSessionDuration, fmt.Sprintf("must be between %d and %d seconds", config.Props.MinSessionDuration(), config.Props.MaxSessionDuration()))
+	}
+	return nil
+}
+
+func (r *Iamrole) validateRolePath() *field.Error {
+	// Without spec.Path the role is created at the prefix
+	if r.Spec.Path == "" {
+		return nil
+	}
+	if !config.Props.IsRolePathAllowed(r.Spec.Path) {
+		return field.Forbidden(field.NewPath("spec").Child("Path"), fmt.Sprintf("path %s must start with %s", r.Spec.Path, config.Props.RolePathPrefix()))
+	}
+	return nil
+}
+
+func (r *Iamrole) validateAdoptRoleARN() *field.Error {
+	if r.Spec.AdoptRoleARN == "" {
+		return nil
+	}
+	if _, err := ValidateAdoptRoleARN(r.Spec.AdoptRoleARN, r.Spec.Path); err != nil {
+		return field.Forbidden(field.NewPath("spec").Child("AdoptRoleARN"), err.Error())
+	}
+	return nil
+}
+
 /*
 Validating the length of a string field can be done declaratively by
 the validation schema.
@@ -229,12 +463,16 @@ func (r *Iamrole) validateRoleNameSuffixAnnotation() *field.Error {
 	return nil
 }
 
-//Lets do a cheesy way to talk to API server
-
-func (r *Iamrole) validateNumberOfRoles(isItUpdate bool) *field.Error {
-	nonAdditional, additional, err := wClient.IamrolesCount(context.Background(), r.ObjectMeta.Namespace)
+// validateNumberOfRoles enforces the per namespace role limits. When the roles can't be counted,
+// the Iamrole is denied or admitted with a warning as per webhook.role.count.failure.policy
+func (r *Iamrole) validateNumberOfRoles(ctx context.Context, c client.Reader, isItUpdate bool) (admission.Warnings, *field.Error) {
+	namespacePath := field.NewPath("metadata").Child("namespace")
+	if c == nil {
+		return r.roleCountFailure(ctx, namespacePath, fmt.Errorf("k8s client is not initialized"))
+	}
+	nonAdditional, additional, err := countIamroles(ctx, c, r.ObjectMeta.Namespace)
 	if err != nil {
-		panic(err)
+		return r.roleCountFailure(ctx, namespacePath, err)
 	}
 
 	_, isAdditional := r.Annotations[config.IamManagerRoleNameSuffixAnnotation]
@@ -244,13 +482,45 @@ func (r *Iamrole) validateNumberOfRoles(isItUpdate bool) *field.Error {
 	// counted, so the same existing role is allowed to pass through.
 	if isAdditional {
 		if (!isItUpdate && additional >= 1) || (isItUpdate && additional > 1) {
-			return field.Invalid(field.NewPath("metadata").Child("namespace"), r.ObjectMeta.Namespace, "only 1 additional (sandbox) role is allowed per namespace")
+			return nil, field.Invalid(namespacePath, r.ObjectMeta.Namespace, "only 1 additional (sandbox) role is allowed per namespace")
 		}
-		return nil
+		return nil, nil
 	}
 
 	if (!isItUpdate && nonAdditional >= config.Props.MaxRolesAllowed()) || (isItUpdate && nonAdditional > config.Props.MaxRolesAllowed()) {
-		return field.Invalid(field.NewPath("metadata").Child("namespace"), r.ObjectMeta.Namespace, "only 1 role is allowed per namespace")
+		return nil, field.Invalid(namespacePath, r.ObjectMeta.Namespace, "only 1 role is allowed per namespace")
 	}
-	return nil
+	return nil, nil
+}
+
+// roleCountFailure denies the Iamrole, or fails open with a warning if the failure policy is Ignore
+func (r *Iamrole) roleCountFailure(ctx context.Context, namespacePath *field.Path, err error) (admission.Warnings, *field.Error) {
+	log := logging.Logger(ctx, "v1alpha1", "validateNumberOfRoles")
+	if config.Props.RoleCountFailurePolicy() == config.RoleCountFailurePolicyIgnore {
+		log.Error(err, "unable to count the iamroles, admitting the request as per the failure policy")
+		return admission.Warnings{fmt.Sprintf("unable to count the Iamroles of namespace %s, the role limit was not enforced: %v", r.ObjectMeta.Namespace, err)}, nil
+	}
+	log.Error(err, "unable to count the iamroles, denying the request as per the failure policy")
+	return nil, field.InternalError(namespacePath, fmt.Errorf("unable to count the Iamroles of namespace %s to enforce the role limit, please retry: %v", r.ObjectMeta.Namespace, err))
+}
+
+// countIamroles returns the counts of non-additional (standard) roles and additional roles of the namespace
+// separately, so the limits of each are enforced independently
+func countIamroles(ctx context.Context, c client.Reader, ns string) (int, int, error) {
+	log := logging.Logger(ctx, "v1alpha1", "countIamroles")
+	var iamRoles IamroleList
+	if err := c.List(ctx, &iamRoles, client.MatchingFields{IamroleNamespaceIndex: ns}); err != nil {
+		return 0, 0, err
+	}
+
+	nonAdditional, additional := 0, 0
+	for _, item := range iamRoles.Items {
+		if _, ok := item.Annotations[config.IamManagerRoleNameSuffixAnnotation]; ok {
+			additional++
+			continue
+		}
+		nonAdditional++
+	}
+	log.Info("Iamrole count for limit enforcement", "non_additional_count", nonAdditional, "additional_count", additional, "total", len(iamRoles.Items))
+	return nonAdditional, additional, nil
 }
@@ -0,0 +1,148 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/keikoproj/iam-manager/internal/config"
+)
+
+func newIamrole(name, ns string, annotations map[string]string) *Iamrole {
+	return &Iamrole{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Annotations: annotations},
+		Spec: IamroleSpec{
+			PolicyDocument: PolicyDocument{
+				Statement: []Statement{{Effect: "Allow", Action: []string{"s3:Get*"}, Resource: []string{"arn:aws:s3:::bucket/*"}}},
+			},
+		},
+	}
+}
+
+func TestIamroleValidator_NumberOfRoles(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	additional := map[string]string{config.IamManagerRoleNameSuffixAnnotation: "sbx"}
+	listFailure := interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			return errors.New("connection refused")
+		},
+	}
+	tests := []struct {
+		name          string
+		existing      []client.Object
+		iamRole       *Iamrole
+		isItUpdate    bool
+		funcs         interceptor.Funcs
+		failurePolicy string
+		wantErr       bool
+		wantWarnings  int
+	}{
+		{name: "first role of the namespace", iamRole: newIamrole("iamrole", "default", nil)},
+		{name: "roles of other namespaces are not counted", existing: []client.Object{newIamrole("other", "other", nil)}, iamRole: newIamrole("iamrole", "default", nil)},
+		{name: "at the limit", existing: []client.Object{newIamrole("other", "default", nil)}, iamRole: newIamrole("iamrole", "default", nil), wantErr: true},
+		{name: "update of the existing role", existing: []client.Object{newIamrole("iamrole", "default", nil)}, iamRole: newIamrole("iamrole", "default", nil), isItUpdate: true},
+		{name: "additional role next to a standard role", existing: []client.Object{newIamrole("other", "default", nil)}, iamRole: newIamrole("iamrole", "default", additional)},
+		{name: "second additional role", existing: []client.Object{newIamrole("other", "default", additional)}, iamRole: newIamrole("iamrole", "default", additional), wantErr: true},
+		{name: "count failure is denied", iamRole: newIamrole("iamrole", "default", nil), funcs: listFailure, wantErr: true},
+		{name: "count failure is admitted with a warning", iamRole: newIamrole("iamrole", "default", nil), funcs: listFailure, failurePolicy: "Ignore", wantWarnings: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := config.LoadProperties("", &v1.ConfigMap{Data: map[string]string{
+				"aws.accountId":                      "123456789012",
+				"iam.policy.action.prefix.whitelist": "s3:",
+				"iam.policy.resource.blacklist":      "kops",
+				"iam.policy.s3.restricted.resource":  "s3-resource",
+				"iam.role.max.limit.per.namespace":   "1",
+				"webhook.role.count.failure.policy":  tt.failurePolicy,
+			}}); err != nil {
+				t.Fatalf("LoadProperties() error = %v", err)
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.existing...).
+				WithIndex(&Iamrole{}, IamroleNamespaceIndex, IndexIamroleNamespace).
+				WithInterceptorFuncs(tt.funcs).Build()
+			v := &IamroleValidator{Client: c}
+
+			var warnings []string
+			var err error
+			if tt.isItUpdate {
+				warnings, err = v.ValidateUpdate(context.Background(), tt.iamRole, tt.iamRole)
+			} else {
+				warnings, err = v.ValidateCreate(context.Background(), tt.iamRole)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !apierrors.IsInvalid(err) {
+				t.Errorf("validate error = %v, want an invalid error", err)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("validate warnings = %v, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+	if err := config.LoadProperties("LOCAL"); err != nil {
+		t.Fatalf("LoadProperties() error = %v", err)
+	}
+}
+
+func TestIamrole_DefaultLastModifiedBy(t *testing.T) {
+	// Default sets the annotation in place, so every Iamrole gets its own annotations
+	lastModifiedBy := func() map[string]string {
+		return map[string]string{config.IamManagerLastModifiedByAnnotation: "alice"}
+	}
+	changed := newIamrole("iamrole", "default", lastModifiedBy())
+	changed.Spec.PolicyDocument.Statement[0].Action = []string{"s3:*"}
+	tests := []struct {
+		name      string
+		operation admissionv1.Operation
+		old       *Iamrole
+		iamRole   *Iamrole
+		want      string
+	}{
+		{name: "create", operation: admissionv1.Create, iamRole: newIamrole("iamrole", "default", nil), want: "bob"},
+		{name: "create with the annotation set by hand", operation: admissionv1.Create, iamRole: newIamrole("iamrole", "default", map[string]string{config.IamManagerLastModifiedByAnnotation: "mallory"}), want: "bob"},
+		{name: "spec change", operation: admissionv1.Update, old: newIamrole("iamrole", "default", lastModifiedBy()), iamRole: changed, want: "bob"},
+		{name: "annotation change", operation: admissionv1.Update, old: newIamrole("iamrole", "default", lastModifiedBy()), iamRole: newIamrole("iamrole", "default", map[string]string{config.IamManagerLastModifiedByAnnotation: "alice", config.IamManagerTagsAnnotation: "team=platform"}), want: "bob"},
+		{name: "finalizer change", operation: admissionv1.Update, old: newIamrole("iamrole", "default", lastModifiedBy()), iamRole: newIamrole("iamrole", "default", lastModifiedBy()), want: "alice"},
+		{name: "annotation changed by hand", operation: admissionv1.Update, old: newIamrole("iamrole", "default", lastModifiedBy()), iamRole: newIamrole("iamrole", "default", map[string]string{config.IamManagerLastModifiedByAnnotation: "mallory"}), want: "alice"},
+		{name: "annotation removed by hand", operation: admissionv1.Update, old: newIamrole("iamrole", "default", lastModifiedBy()), iamRole: newIamrole("iamrole", "default", nil), want: "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: tt.operation,
+				UserInfo:  authenticationv1.UserInfo{Username: "bob"},
+			}}
+			if tt.old != nil {
+				raw, err := json.Marshal(tt.old)
+				if err != nil {
+					t.Fatalf("Marshal() error = %v", err)
+				}
+				req.OldObject.Raw = raw
+			}
+			ctx := admission.NewContextWithRequest(context.Background(), req)
+			if err := tt.iamRole.Default(ctx, tt.iamRole); err != nil {
+				t.Fatalf("Default() error = %v", err)
+			}
+			if got := tt.iamRole.Annotations[config.IamManagerLastModifiedByAnnotation]; got != tt.want {
+				t.Errorf("last modified by = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -46,30 +47,27 @@ func (in *AssumeRolePolicyDocument) DeepCopy() *AssumeRolePolicyDocument {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Condition) DeepCopyInto(out *Condition) {
+func (in *DriftStatus) DeepCopyInto(out *DriftStatus) {
 	*out = *in
-	if in.StringEquals != nil {
-		in, out := &in.StringEquals, &out.StringEquals
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	in.DetectedTimestamp.DeepCopyInto(&out.DetectedTimestamp)
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.StringLike != nil {
-		in, out := &in.StringLike, &out.StringLike
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
-func (in *Condition) DeepCopy() *Condition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftStatus.
+func (in *DriftStatus) DeepCopy() *DriftStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Condition)
+	out := new(DriftStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -142,6 +140,32 @@ func (in *IamroleSpec) DeepCopyInto(out *IamroleSpec) {
 		*out = new(AssumeRolePolicyDocument)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ManagedPolicyArns != nil {
+		in, out := &in.ManagedPolicyArns, &out.ManagedPolicyArns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InlinePolicies != nil {
+		in, out := &in.InlinePolicies, &out.InlinePolicies
+		*out = make([]InlinePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = make([]ServiceAccount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamroleSpec.
@@ -158,6 +182,38 @@ func (in *IamroleSpec) DeepCopy() *IamroleSpec {
 func (in *IamroleStatus) DeepCopyInto(out *IamroleStatus) {
 	*out = *in
 	in.LastUpdatedTimestamp.DeepCopyInto(&out.LastUpdatedTimestamp)
+	if in.ManagedPolicyArns != nil {
+		in, out := &in.ManagedPolicyArns, &out.ManagedPolicyArns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InlinePolicyNames != nil {
+		in, out := &in.InlinePolicyNames, &out.InlinePolicyNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TagKeys != nil {
+		in, out := &in.TagKeys, &out.TagKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = make([]ServiceAccountStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastDrift != nil {
+		in, out := &in.LastDrift, &out.LastDrift
+		*out = new(DriftStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IamroleStatus.
@@ -170,6 +226,63 @@ func (in *IamroleStatus) DeepCopy() *IamroleStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InlinePolicy) DeepCopyInto(out *InlinePolicy) {
+	*out = *in
+	in.PolicyDocument.DeepCopyInto(&out.PolicyDocument)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InlinePolicy.
+func (in *InlinePolicy) DeepCopy() *InlinePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(InlinePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in PolicyCondition) DeepCopyInto(out *PolicyCondition) {
+	{
+		in := &in
+		*out = make(PolicyCondition, len(*in))
+		for key, val := range *in {
+			var outVal map[string]StringOrStrings
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(map[string]StringOrStrings, len(*in))
+				for key, val := range *in {
+					var outVal []string
+					if val == nil {
+						(*out)[key] = nil
+					} else {
+						inVal := (*in)[key]
+						in, out := &inVal, &outVal
+						*out = make(StringOrStrings, len(*in))
+						copy(*out, *in)
+					}
+					(*out)[key] = outVal
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyCondition.
+func (in PolicyCondition) DeepCopy() PolicyCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyCondition)
+	in.DeepCopyInto(out)
+	return *out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PolicyDocument) DeepCopyInto(out *PolicyDocument) {
 	*out = *in
@@ -212,6 +325,55 @@ func (in *Principal) DeepCopy() *Principal {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccount) DeepCopyInto(out *ServiceAccount) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CreateIfMissing != nil {
+		in, out := &in.CreateIfMissing, &out.CreateIfMissing
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccount.
+func (in *ServiceAccount) DeepCopy() *ServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccountStatus) DeepCopyInto(out *ServiceAccountStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccountStatus.
+func (in *ServiceAccountStatus) DeepCopy() *ServiceAccountStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAccountStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Statement) DeepCopyInto(out *Statement) {
 	*out = *in
@@ -220,11 +382,48 @@ func (in *Statement) DeepCopyInto(out *Statement) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NotAction != nil {
+		in, out := &in.NotAction, &out.NotAction
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Resource != nil {
 		in, out := &in.Resource, &out.Resource
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NotResource != nil {
+		in, out := &in.NotResource, &out.NotResource
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Condition != nil {
+		in, out := &in.Condition, &out.Condition
+		*out = make(PolicyCondition, len(*in))
+		for key, val := range *in {
+			var outVal map[string]StringOrStrings
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(map[string]StringOrStrings, len(*in))
+				for key, val := range *in {
+					var outVal []string
+					if val == nil {
+						(*out)[key] = nil
+					} else {
+						inVal := (*in)[key]
+						in, out := &inVal, &outVal
+						*out = make(StringOrStrings, len(*in))
+						copy(*out, *in)
+					}
+					(*out)[key] = outVal
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Statement.
@@ -243,8 +442,30 @@ func (in *TrustPolicyStatement) DeepCopyInto(out *TrustPolicyStatement) {
 	in.Principal.DeepCopyInto(&out.Principal)
 	if in.Condition != nil {
 		in, out := &in.Condition, &out.Condition
-		*out = new(Condition)
-		(*in).DeepCopyInto(*out)
+		*out = make(PolicyCondition, len(*in))
+		for key, val := range *in {
+			var outVal map[string]StringOrStrings
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(map[string]StringOrStrings, len(*in))
+				for key, val := range *in {
+					var outVal []string
+					if val == nil {
+						(*out)[key] = nil
+					} else {
+						inVal := (*in)[key]
+						in, out := &inVal, &outVal
+						*out = make(StringOrStrings, len(*in))
+						copy(*out, *in)
+					}
+					(*out)[key] = outVal
+				}
+			}
+			(*out)[key] = outVal
+		}
 	}
 }
 
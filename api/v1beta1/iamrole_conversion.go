@@ -0,0 +1,353 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/keikoproj/iam-manager/api/v1alpha1"
+	"github.com/keikoproj/iam-manager/internal/config"
+)
+
+// ConversionDataAnnotation keeps the v1alpha1 annotations and fields which cannot be rebuilt
+// from the v1beta1 spec, so that converting to v1beta1 and back is lossless
+const ConversionDataAnnotation = "iammanager.keikoproj.io/v1alpha1-conversion-data"
+
+// promotedAnnotations are the v1alpha1 annotations which are spec fields in v1beta1
+var promotedAnnotations = []string{config.IRSAAnnotation, config.IamManagerRoleNameSuffixAnnotation, config.IamManagerTagsAnnotation}
+
+// v1alpha1Data is the v1alpha1 representation of the promoted fields
+type v1alpha1Data struct {
+	Annotations     map[string]string         `json:"annotations,omitempty"`
+	Tags            map[string]string         `json:"tags,omitempty"`
+	ServiceAccounts []v1alpha1.ServiceAccount `json:"serviceAccounts,omitempty"`
+}
+
+// promotedFields is the v1beta1 representation of the promoted fields
+type promotedFields struct {
+	ServiceAccounts []ServiceAccount
+	RoleNameSuffix  string
+	Tags            map[string]string
+}
+
+// promote converts the v1alpha1 annotations and tags the same way the controller interprets them
+func (d v1alpha1Data) promote() promotedFields {
+	fields := promotedFields{RoleNameSuffix: d.Annotations[config.IamManagerRoleNameSuffixAnnotation]}
+	role := v1alpha1.Iamrole{ObjectMeta: metav1.ObjectMeta{Annotations: d.Annotations}, Spec: v1alpha1.IamroleSpec{Tags: d.Tags, ServiceAccounts: d.ServiceAccounts}}
+	for _, sa := range role.ServiceAccounts() {
+		fields.ServiceAccounts = append(fields.ServiceAccounts, ServiceAccount{
+			Name:            sa.Name,
+			Annotations:     maps.Clone(sa.Annotations),
+			Labels:          maps.Clone(sa.Labels),
+			CreateIfMissing: cloneBool(sa.CreateIfMissing),
+		})
+	}
+	if tags, _ := role.CustomTags(); len(tags) > 0 {
+		fields.Tags = tags
+	}
+	return fields
+}
+
+// demote builds the v1alpha1 annotations and tags for the promoted fields
+func (f promotedFields) demote() v1alpha1Data {
+	data := v1alpha1Data{}
+	setAnnotation := func(key, value string) {
+		if data.Annotations == nil {
+			data.Annotations = map[string]string{}
+		}
+		data.Annotations[key] = value
+	}
+	// Service accounts which only have a name are kept in the irsa annotation understood by every iam-manager version
+	plain := !slices.ContainsFunc(f.ServiceAccounts, func(sa ServiceAccount) bool {
+		return len(sa.Annotations) > 0 || len(sa.Labels) > 0 || sa.CreateIfMissing != nil
+	})
+	if plain && len(f.ServiceAccounts) > 0 {
+		names := make([]string, 0, len(f.ServiceAccounts))
+		for _, sa := range f.ServiceAccounts {
+			names = append(names, sa.Name)
+		}
+		setAnnotation(config.IRSAAnnotation, strings.Join(names, ","))
+	} else {
+		for _, sa := range f.ServiceAccounts {
+			data.ServiceAccounts = append(data.ServiceAccounts, v1alpha1.ServiceAccount{
+				Name:            sa.Name,
+				Annotations:     maps.Clone(sa.Annotations),
+				Labels:          maps.Clone(sa.Labels),
+				CreateIfMissing: cloneBool(sa.CreateIfMissing),
+			})
+		}
+	}
+	if f.RoleNameSuffix != "" {
+		setAnnotation(config.IamManagerRoleNameSuffixAnnotation, f.RoleNameSuffix)
+	}
+	if len(f.Tags) > 0 {
+		data.Tags = maps.Clone(f.Tags)
+	}
+	return data
+}
+
+// ConvertTo converts this Iamrole to the Hub version (v1alpha1)
+func (src *Iamrole) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.Iamrole)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = v1alpha1.IamroleSpec{
+		PolicyDocument:           policyDocumentToV1alpha1(src.Spec.PolicyDocument),
+		AssumeRolePolicyDocument: assumeRolePolicyDocumentToV1alpha1(src.Spec.AssumeRolePolicyDocument),
+		RoleName:                 src.Spec.RoleName,
+		ManagedPolicyArns:        slices.Clone(src.Spec.ManagedPolicyArns),
+		MaxSessionDuration:       src.Spec.MaxSessionDuration,
+		Description:              src.Spec.Description,
+		Path:                     src.Spec.Path,
+		AdoptRoleARN:             src.Spec.AdoptRoleARN,
+		DeletionPolicy:           v1alpha1.DeletionPolicy(src.Spec.DeletionPolicy),
+	}
+	for _, inlinePolicy := range src.Spec.InlinePolicies {
+		dst.Spec.InlinePolicies = append(dst.Spec.InlinePolicies, v1alpha1.InlinePolicy{Name: inlinePolicy.Name, PolicyDocument: policyDocumentToV1alpha1(inlinePolicy.PolicyDocument)})
+	}
+
+	// Prefer the original v1alpha1 representation as long as the promoted fields were not changed in v1beta1
+	fields := promotedFields{ServiceAccounts: src.Spec.ServiceAccounts, RoleNameSuffix: src.Spec.RoleNameSuffix, Tags: src.Spec.Tags}
+	data := fields.demote()
+	if stashed, ok := src.Annotations[ConversionDataAnnotation]; ok {
+		original := v1alpha1Data{}
+		if err := json.Unmarshal([]byte(stashed), &original); err == nil && equality.Semantic.DeepEqual(original.promote(), fields) {
+			data = original
+		}
+	}
+	delete(dst.Annotations, ConversionDataAnnotation)
+	for _, key := range promotedAnnotations {
+		delete(dst.Annotations, key)
+	}
+	for key, value := range data.Annotations {
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[key] = value
+	}
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+	dst.Spec.Tags = data.Tags
+	dst.Spec.ServiceAccounts = data.ServiceAccounts
+
+	dst.Status = v1alpha1.IamroleStatus{
+		RoleName:             src.Status.RoleName,
+		RoleARN:              src.Status.RoleARN,
+		RoleID:               src.Status.RoleID,
+		State:                v1alpha1.State(src.Status.State),
+		RetryCount:           src.Status.RetryCount,
+		ErrorDescription:     src.Status.ErrorDescription,
+		LastUpdatedTimestamp: src.Status.LastUpdatedTimestamp,
+		ManagedPolicyArns:    slices.Clone(src.Status.ManagedPolicyArns),
+		InlinePolicyNames:    slices.Clone(src.Status.InlinePolicyNames),
+		TagKeys:              slices.Clone(src.Status.TagKeys),
+		ObservedGeneration:   src.Status.ObservedGeneration,
+		LastDrift:            (*v1alpha1.DriftStatus)(src.Status.LastDrift.DeepCopy()),
+		Conditions:           slices.Clone(src.Status.Conditions),
+	}
+	for _, sa := range src.Status.ServiceAccounts {
+		dst.Status.ServiceAccounts = append(dst.Status.ServiceAccounts, v1alpha1.ServiceAccountStatus(sa))
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha1) to this version
+func (dst *Iamrole) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.Iamrole)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = IamroleSpec{
+		PolicyDocument:           policyDocumentFromV1alpha1(src.Spec.PolicyDocument),
+		AssumeRolePolicyDocument: assumeRolePolicyDocumentFromV1alpha1(src.Spec.AssumeRolePolicyDocument),
+		RoleName:                 src.Spec.RoleName,
+		ManagedPolicyArns:        slices.Clone(src.Spec.ManagedPolicyArns),
+		MaxSessionDuration:       src.Spec.MaxSessionDuration,
+		Description:              src.Spec.Description,
+		Path:                     src.Spec.Path,
+		AdoptRoleARN:             src.Spec.AdoptRoleARN,
+		DeletionPolicy:           DeletionPolicy(src.Spec.DeletionPolicy),
+	}
+	for _, inlinePolicy := range src.Spec.InlinePolicies {
+		dst.Spec.InlinePolicies = append(dst.Spec.InlinePolicies, InlinePolicy{Name: inlinePolicy.Name, PolicyDocument: policyDocumentFromV1alpha1(inlinePolicy.PolicyDocument)})
+	}
+
+	data := v1alpha1Data{Tags: maps.Clone(src.Spec.Tags), ServiceAccounts: src.Spec.ServiceAccounts}
+	for _, key := range promotedAnnotations {
+		if value, ok := src.Annotations[key]; ok {
+			if data.Annotations == nil {
+				data.Annotations = map[string]string{}
+			}
+			data.Annotations[key] = value
+		}
+		delete(dst.Annotations, key)
+	}
+	delete(dst.Annotations, ConversionDataAnnotation)
+	fields := data.promote()
+	dst.Spec.ServiceAccounts = fields.ServiceAccounts
+	dst.Spec.RoleNameSuffix = fields.RoleNameSuffix
+	dst.Spec.Tags = fields.Tags
+
+	// Keep the v1alpha1 representation when it cannot be rebuilt from the v1beta1 spec
+	if !equality.Semantic.DeepEqual(fields.demote(), data) {
+		stashed, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[ConversionDataAnnotation] = string(stashed)
+	}
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+
+	dst.Status = IamroleStatus{
+		RoleName:             src.Status.RoleName,
+		RoleARN:              src.Status.RoleARN,
+		RoleID:               src.Status.RoleID,
+		State:                State(src.Status.State),
+		RetryCount:           src.Status.RetryCount,
+		ErrorDescription:     src.Status.ErrorDescription,
+		LastUpdatedTimestamp: src.Status.LastUpdatedTimestamp,
+		ManagedPolicyArns:    slices.Clone(src.Status.ManagedPolicyArns),
+		InlinePolicyNames:    slices.Clone(src.Status.InlinePolicyNames),
+		TagKeys:              slices.Clone(src.Status.TagKeys),
+		ObservedGeneration:   src.Status.ObservedGeneration,
+		LastDrift:            (*DriftStatus)(src.Status.LastDrift.DeepCopy()),
+		Conditions:           slices.Clone(src.Status.Conditions),
+	}
+	for _, sa := range src.Status.ServiceAccounts {
+		dst.Status.ServiceAccounts = append(dst.Status.ServiceAccounts, ServiceAccountStatus(sa))
+	}
+	return nil
+}
+
+func cloneBool(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	clone := *b
+	return &clone
+}
+
+func policyDocumentToV1alpha1(src PolicyDocument) v1alpha1.PolicyDocument {
+	dst := v1alpha1.PolicyDocument{Version: src.Version}
+	for _, statement := range src.Statement {
+		dst.Statement = append(dst.Statement, v1alpha1.Statement{
+			Effect:      v1alpha1.Effect(statement.Effect),
+			Action:      slices.Clone(statement.Action),
+			NotAction:   slices.Clone(statement.NotAction),
+			Resource:    slices.Clone(statement.Resource),
+			NotResource: slices.Clone(statement.NotResource),
+			Sid:         statement.Sid,
+			Condition:   conditionToV1alpha1(statement.Condition),
+		})
+	}
+	return dst
+}
+
+func policyDocumentFromV1alpha1(src v1alpha1.PolicyDocument) PolicyDocument {
+	dst := PolicyDocument{Version: src.Version}
+	for _, statement := range src.Statement {
+		dst.Statement = append(dst.Statement, Statement{
+			Effect:      Effect(statement.Effect),
+			Action:      slices.Clone(statement.Action),
+			NotAction:   slices.Clone(statement.NotAction),
+			Resource:    slices.Clone(statement.Resource),
+			NotResource: slices.Clone(statement.NotResource),
+			Sid:         statement.Sid,
+			Condition:   conditionFromV1alpha1(statement.Condition),
+		})
+	}
+	return dst
+}
+
+func assumeRolePolicyDocumentToV1alpha1(src *AssumeRolePolicyDocument) *v1alpha1.AssumeRolePolicyDocument {
+	if src == nil {
+		return nil
+	}
+	dst := &v1alpha1.AssumeRolePolicyDocument{Version: src.Version}
+	for _, statement := range src.Statement {
+		dst.Statement = append(dst.Statement, v1alpha1.TrustPolicyStatement{
+			Effect: v1alpha1.Effect(statement.Effect),
+			Action: statement.Action,
+			Principal: v1alpha1.Principal{
+				AWS:       v1alpha1.StringOrStrings(slices.Clone(statement.Principal.AWS)),
+				Service:   statement.Principal.Service,
+				Federated: statement.Principal.Federated,
+			},
+			Condition: conditionToV1alpha1(statement.Condition),
+		})
+	}
+	return dst
+}
+
+func assumeRolePolicyDocumentFromV1alpha1(src *v1alpha1.AssumeRolePolicyDocument) *AssumeRolePolicyDocument {
+	if src == nil {
+		return nil
+	}
+	dst := &AssumeRolePolicyDocument{Version: src.Version}
+	for _, statement := range src.Statement {
+		dst.Statement = append(dst.Statement, TrustPolicyStatement{
+			Effect: Effect(statement.Effect),
+			Action: statement.Action,
+			Principal: Principal{
+				AWS:       slices.Clone([]string(statement.Principal.AWS)),
+				Service:   statement.Principal.Service,
+				Federated: statement.Principal.Federated,
+			},
+			Condition: conditionFromV1alpha1(statement.Condition),
+		})
+	}
+	return dst
+}
+
+func conditionToV1alpha1(src PolicyCondition) v1alpha1.PolicyCondition {
+	if src == nil {
+		return nil
+	}
+	dst := v1alpha1.PolicyCondition{}
+	for operator, keys := range src {
+		dst[operator] = map[string]v1alpha1.StringOrStrings{}
+		for key, values := range keys {
+			dst[operator][key] = v1alpha1.StringOrStrings(slices.Clone(values))
+		}
+	}
+	return dst
+}
+
+func conditionFromV1alpha1(src v1alpha1.PolicyCondition) PolicyCondition {
+	if src == nil {
+		return nil
+	}
+	dst := PolicyCondition{}
+	for operator, keys := range src {
+		dst[operator] = map[string][]string{}
+		for key, values := range keys {
+			dst[operator][key] = slices.Clone([]string(values))
+		}
+	}
+	return dst
+}
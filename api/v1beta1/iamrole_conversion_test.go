@@ -0,0 +1,229 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/keikoproj/iam-manager/api/v1alpha1"
+	"github.com/keikoproj/iam-manager/internal/config"
+)
+
+func v1alpha1Role(annotations map[string]string, tags map[string]string) *v1alpha1.Iamrole {
+	return &v1alpha1.Iamrole{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "k8s-namespace-dev", Generation: 2, Annotations: annotations},
+		Spec: v1alpha1.IamroleSpec{
+			PolicyDocument: v1alpha1.PolicyDocument{
+				Version: "2012-10-17",
+				Statement: []v1alpha1.Statement{
+					{
+						Effect:   v1alpha1.AllowPolicy,
+						Action:   []string{"s3:GetObject"},
+						Resource: []string{"arn:aws:s3:::bucket/*"},
+						Condition: v1alpha1.PolicyCondition{
+							"StringEquals": {"aws:SourceVpce": v1alpha1.StringOrStrings{"vpce-1a2b3c4d"}},
+						},
+					},
+				},
+			},
+			AssumeRolePolicyDocument: &v1alpha1.AssumeRolePolicyDocument{
+				Statement: []v1alpha1.TrustPolicyStatement{
+					{
+						Effect:    v1alpha1.AllowPolicy,
+						Action:    "sts:AssumeRole",
+						Principal: v1alpha1.Principal{AWS: v1alpha1.StringOrStrings{"arn:aws:iam::123456789012:role/node"}},
+					},
+				},
+			},
+			ManagedPolicyArns: []string{"arn:aws:iam::aws:policy/ReadOnlyAccess"},
+			InlinePolicies: []v1alpha1.InlinePolicy{
+				{Name: "dynamodb", PolicyDocument: v1alpha1.PolicyDocument{Statement: []v1alpha1.Statement{{Effect: v1alpha1.AllowPolicy, Action: []string{"dynamodb:GetItem"}, Resource: []string{"*"}}}}},
+			},
+			MaxSessionDuration: 7200,
+			Description:        "app role",
+			Path:               "/k8s/",
+			AdoptRoleARN:       "arn:aws:iam::123456789012:role/k8s/k8s-app",
+			DeletionPolicy:     v1alpha1.DeletionPolicyRetain,
+			Tags:               tags,
+		},
+		Status: v1alpha1.IamroleStatus{
+			RoleName:           "k8s-app",
+			State:              v1alpha1.Ready,
+			TagKeys:            []string{"team"},
+			ObservedGeneration: 2,
+			LastDrift:          &v1alpha1.DriftStatus{Components: []string{"policy"}, Changes: []string{"inline policy default statement 1 Action: added s3:DeleteObject"}},
+			Conditions:         []metav1.Condition{{Type: v1alpha1.ConditionReady, Status: metav1.ConditionTrue, Reason: string(v1alpha1.Ready)}},
+		},
+	}
+}
+
+func TestIamrole_ConvertFrom_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name            string
+		alpha           *v1alpha1.Iamrole
+		serviceAccounts []ServiceAccount
+		roleNameSuffix  string
+		tags            map[string]string
+		stashed         bool
+	}{
+		{
+			name:  "no promoted annotations",
+			alpha: v1alpha1Role(nil, nil),
+		},
+		{
+			name: "promoted annotations",
+			alpha: v1alpha1Role(map[string]string{
+				config.IRSAAnnotation:                     "app,worker",
+				config.IamManagerRoleNameSuffixAnnotation: "v2",
+				"unrelated": "value",
+			}, map[string]string{"team": "orders"}),
+			serviceAccounts: []ServiceAccount{{Name: "app"}, {Name: "worker"}},
+			roleNameSuffix:  "v2",
+			tags:            map[string]string{"team": "orders"},
+		},
+		{
+			name: "tags annotation",
+			alpha: v1alpha1Role(map[string]string{
+				config.IamManagerTagsAnnotation: "team=orders;;cost-center=1234",
+			}, map[string]string{"team": "payments"}),
+			tags:    map[string]string{"team": "payments", "cost-center": "1234"},
+			stashed: true,
+		},
+		{
+			name: "spec service accounts",
+			alpha: func() *v1alpha1.Iamrole {
+				role := v1alpha1Role(map[string]string{config.IRSAAnnotation: "app"}, nil)
+				role.Spec.ServiceAccounts = []v1alpha1.ServiceAccount{{Name: "worker", Labels: map[string]string{"team": "orders"}}}
+				return role
+			}(),
+			serviceAccounts: []ServiceAccount{{Name: "app"}, {Name: "worker", Labels: map[string]string{"team": "orders"}}},
+			stashed:         true,
+		},
+		{
+			name: "irsa annotation which is not normalized",
+			alpha: v1alpha1Role(map[string]string{
+				config.IRSAAnnotation: " app, app ,",
+			}, nil),
+			serviceAccounts: []ServiceAccount{{Name: "app"}},
+			stashed:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			beta := &Iamrole{}
+			if err := beta.ConvertFrom(tt.alpha); err != nil {
+				t.Fatalf("ConvertFrom() error = %v", err)
+			}
+			if !equality.Semantic.DeepEqual(beta.Spec.ServiceAccounts, tt.serviceAccounts) {
+				t.Errorf("ServiceAccounts = %v, want %v", beta.Spec.ServiceAccounts, tt.serviceAccounts)
+			}
+			if beta.Spec.RoleNameSuffix != tt.roleNameSuffix {
+				t.Errorf("RoleNameSuffix = %v, want %v", beta.Spec.RoleNameSuffix, tt.roleNameSuffix)
+			}
+			if !equality.Semantic.DeepEqual(beta.Spec.Tags, tt.tags) {
+				t.Errorf("Tags = %v, want %v", beta.Spec.Tags, tt.tags)
+			}
+			for _, key := range promotedAnnotations {
+				if _, ok := beta.Annotations[key]; ok {
+					t.Errorf("annotation %s was not promoted", key)
+				}
+			}
+			if _, ok := beta.Annotations[ConversionDataAnnotation]; ok != tt.stashed {
+				t.Errorf("conversion data stashed = %v, want %v", ok, tt.stashed)
+			}
+
+			alpha := &v1alpha1.Iamrole{}
+			if err := beta.ConvertTo(alpha); err != nil {
+				t.Fatalf("ConvertTo() error = %v", err)
+			}
+			if !equality.Semantic.DeepEqual(alpha, tt.alpha) {
+				t.Errorf("round trip = %+v, want %+v", alpha, tt.alpha)
+			}
+		})
+	}
+}
+
+func TestIamrole_ConvertTo_RoundTrip(t *testing.T) {
+	beta := &Iamrole{}
+	if err := beta.ConvertFrom(v1alpha1Role(nil, nil)); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	beta.Spec.ServiceAccounts = []ServiceAccount{{Name: "app"}, {Name: "worker"}}
+	beta.Spec.RoleNameSuffix = "v2"
+	beta.Spec.Tags = map[string]string{"team": "orders"}
+
+	alpha := &v1alpha1.Iamrole{}
+	if err := beta.ConvertTo(alpha); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if got := alpha.Annotations[config.IRSAAnnotation]; got != "app,worker" {
+		t.Errorf("irsa annotation = %v, want app,worker", got)
+	}
+	if got := alpha.Annotations[config.IamManagerRoleNameSuffixAnnotation]; got != "v2" {
+		t.Errorf("suffix annotation = %v, want v2", got)
+	}
+
+	got := &Iamrole{}
+	if err := got.ConvertFrom(alpha); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if !equality.Semantic.DeepEqual(got, beta) {
+		t.Errorf("round trip = %+v, want %+v", got, beta)
+	}
+}
+
+func TestIamrole_ConvertTo_StaleConversionData(t *testing.T) {
+	beta := &Iamrole{}
+	if err := beta.ConvertFrom(v1alpha1Role(map[string]string{config.IamManagerTagsAnnotation: "team=orders"}, nil)); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if _, ok := beta.Annotations[ConversionDataAnnotation]; !ok {
+		t.Fatalf("expected conversion data to be stashed")
+	}
+
+	// Changing the tags in v1beta1 must win over the stashed v1alpha1 annotation
+	beta.Spec.Tags = map[string]string{"team": "payments"}
+	alpha := &v1alpha1.Iamrole{}
+	if err := beta.ConvertTo(alpha); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if _, ok := alpha.Annotations[config.IamManagerTagsAnnotation]; ok {
+		t.Errorf("stale tags annotation was restored")
+	}
+	if _, ok := alpha.Annotations[ConversionDataAnnotation]; ok {
+		t.Errorf("conversion data annotation was not removed")
+	}
+	if alpha.Spec.Tags["team"] != "payments" {
+		t.Errorf("Tags = %v, want team=payments", alpha.Spec.Tags)
+	}
+}
+
+func TestIamrole_ConvertTo_ServiceAccounts(t *testing.T) {
+	noCreate := false
+	beta := &Iamrole{}
+	if err := beta.ConvertFrom(v1alpha1Role(nil, nil)); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	beta.Spec.ServiceAccounts = []ServiceAccount{{Name: "app"}, {Name: "worker", Annotations: map[string]string{"example.com/team": "orders"}, CreateIfMissing: &noCreate}}
+	beta.Status.ServiceAccounts = []ServiceAccountStatus{{Name: "app", Created: true, Synced: true}}
+
+	alpha := &v1alpha1.Iamrole{}
+	if err := beta.ConvertTo(alpha); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if _, ok := alpha.Annotations[config.IRSAAnnotation]; ok {
+		t.Errorf("irsa annotation must not be used for service accounts with settings")
+	}
+	if len(alpha.Spec.ServiceAccounts) != 2 || alpha.Spec.ServiceAccounts[1].ShouldCreate() {
+		t.Errorf("ServiceAccounts = %+v", alpha.Spec.ServiceAccounts)
+	}
+
+	got := &Iamrole{}
+	if err := got.ConvertFrom(alpha); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if !equality.Semantic.DeepEqual(got, beta) {
+		t.Errorf("round trip = %+v, want %+v", got, beta)
+	}
+}
@@ -0,0 +1,333 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IamroleSpec defines the desired state of Iamrole
+// +kubebuilder:validation:XValidation:rule="has(self.path) == has(oldSelf.path) && (!has(self.path) || self.path == oldSelf.path)",message="Path is immutable"
+type IamroleSpec struct {
+	// PolicyDocument is the inline permission policy of the role
+	PolicyDocument PolicyDocument `json:"policyDocument"`
+	// AssumeRolePolicyDocument is the trust policy of the role. The cluster default trust policy is used when empty
+	// +optional
+	AssumeRolePolicyDocument *AssumeRolePolicyDocument `json:"assumeRolePolicyDocument,omitempty"`
+	// RoleName can be passed only for privileged namespaces. This will be respected only during new iamrole creation and will be ignored during iamrole update
+	// +optional
+	RoleName string `json:"roleName,omitempty"`
+	// RoleNameSuffix is appended to the templated role name so that a namespace can host one additional role
+	// +kubebuilder:validation:Pattern=`^[\w+=,.@-]{1,3}$`
+	// +optional
+	RoleNameSuffix string `json:"roleNameSuffix,omitempty"`
+	// ManagedPolicyArns lists managed IAM policies to attach to the role in addition to the cluster wide managed policies
+	// Every ARN must be allowed by iam.managed.policies.allowed config map property
+	// +optional
+	ManagedPolicyArns []string `json:"managedPolicyArns,omitempty"`
+	// InlinePolicies lists additional named inline policies attached to the role next to PolicyDocument
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	InlinePolicies []InlinePolicy `json:"inlinePolicies,omitempty"`
+	// MaxSessionDuration is the maximum session duration in seconds for the role.
+	// It must be within iam.role.max.session.duration.min and iam.role.max.session.duration.max config map properties.
+	// By default, iam.role.max.session.duration.max is used
+	// +kubebuilder:validation:Minimum=3600
+	// +kubebuilder:validation:Maximum=43200
+	// +optional
+	MaxSessionDuration int64 `json:"maxSessionDuration,omitempty"`
+	// Description of the role. By default, "#DO NOT DELETE#. Managed by iam-manager" is used
+	// +kubebuilder:validation:MaxLength=1000
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Path of the role. It must start with iam.role.path.prefix config map property and cannot be changed once set.
+	// By default, iam.role.path.prefix is used
+	// +kubebuilder:validation:MaxLength=512
+	// +kubebuilder:validation:Pattern=`^(/|/[!-~]+/)$`
+	// +optional
+	Path string `json:"path,omitempty"`
+	// AdoptRoleARN is the ARN of a pre-existing role which iam-manager takes over. The role name must match the generated
+	// role name and be allowed by iam.role.adopt.allowed config map property
+	// +kubebuilder:validation:Pattern=`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`
+	// +optional
+	AdoptRoleARN string `json:"adoptRoleARN,omitempty"`
+	// DeletionPolicy decides what happens to the role when the Iamrole is deleted. By default, iam.role.deletion.policy
+	// config map property is used
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// Tags are custom tags attached to the role. managedBy, Namespace and Cluster keys are reserved
+	// +kubebuilder:validation:MaxProperties=47
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+	// ServiceAccounts which can assume the role through IRSA
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ServiceAccounts []ServiceAccount `json:"serviceAccounts,omitempty"`
+}
+
+// DeletionPolicy describes what happens to the role when the Iamrole is deleted
+// +kubebuilder:validation:Enum=Delete;Retain;RetainWithoutPolicies
+type DeletionPolicy string
+
+const (
+	//DeletionPolicyDelete deletes the role
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+
+	//DeletionPolicyRetain keeps the role with its policies
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+
+	//DeletionPolicyRetainWithoutPolicies keeps the role but removes its inline and managed policies
+	DeletionPolicyRetainWithoutPolicies DeletionPolicy = "RetainWithoutPolicies"
+)
+
+// ServiceAccount defines a service account which can assume the role through IRSA
+type ServiceAccount struct {
+	// Name of the service account in the namespace of the Iamrole
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// Annotations are added to the service account next to the role annotations
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Labels are added to the service account
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// CreateIfMissing creates the service account when it does not exist. Defaults to true.
+	// Otherwise only an existing service account is annotated
+	// +optional
+	CreateIfMissing *bool `json:"createIfMissing,omitempty"`
+}
+
+// InlinePolicy type defines a named inline IAM policy
+type InlinePolicy struct {
+	// Name of the inline policy. The name used for PolicyDocument is reserved
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=128
+	// +kubebuilder:validation:Pattern=`^[\w+=,.@-]+$`
+	Name string `json:"name"`
+
+	PolicyDocument PolicyDocument `json:"policyDocument"`
+}
+
+// PolicyDocument type defines IAM policy struct
+type PolicyDocument struct {
+	// Version specifies IAM policy version
+	// By default, this value is "2012-10-17"
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Statement allows list of statement object
+	Statement []Statement `json:"statement"`
+}
+
+// Statement type defines the AWS IAM policy statement
+type Statement struct {
+	//Effect allowed/denied
+	Effect Effect `json:"effect"`
+
+	//Action allowed on specific resources
+	// +optional
+	Action []string `json:"action,omitempty"`
+
+	//NotAction matches every action except the listed ones. It cannot be used together with Action
+	// +optional
+	NotAction []string `json:"notAction,omitempty"`
+
+	//Resource defines target resources which IAM policy will be applied
+	// +optional
+	Resource []string `json:"resource,omitempty"`
+
+	//NotResource matches every resource except the listed ones. It cannot be used together with Resource
+	// +optional
+	NotResource []string `json:"notResource,omitempty"`
+
+	// Sid is an optional field which describes the specific statement action
+	// +optional
+	Sid string `json:"sid,omitempty"`
+
+	// Condition specifies the circumstances under which the statement is in effect
+	// +optional
+	Condition PolicyCondition `json:"condition,omitempty"`
+}
+
+// PolicyCondition holds an IAM condition block keyed by condition operator and then by condition key
+type PolicyCondition map[string]map[string][]string
+
+// Effect describes whether to allow or deny the specific action
+// +kubebuilder:validation:Enum=Allow;Deny
+type Effect string
+
+const (
+	//AllowPolicy allows policy
+	AllowPolicy Effect = "Allow"
+
+	//DenyPolicy denies policy
+	DenyPolicy Effect = "Deny"
+)
+
+// AssumeRolePolicyDocument type defines the trust policy of the role
+type AssumeRolePolicyDocument struct {
+	// Version specifies IAM policy version
+	// By default, this value is "2012-10-17"
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Statement allows list of TrustPolicyStatement objects
+	// +optional
+	Statement []TrustPolicyStatement `json:"statement,omitempty"`
+}
+
+// TrustPolicyStatement struct holds Trust policy statement
+type TrustPolicyStatement struct {
+	//Effect allowed/denied
+	// +optional
+	Effect Effect `json:"effect,omitempty"`
+	//Action can be performed
+	// +optional
+	Action string `json:"action,omitempty"`
+	// +optional
+	Principal Principal `json:"principal,omitempty"`
+	// Condition holds the trust policy condition block keyed by condition operator and then by condition key
+	// +optional
+	Condition PolicyCondition `json:"condition,omitempty"`
+}
+
+// Principal struct holds AWS principal
+type Principal struct {
+	// +optional
+	AWS []string `json:"aws,omitempty"`
+	// +optional
+	Service string `json:"service,omitempty"`
+	// +optional
+	Federated string `json:"federated,omitempty"`
+}
+
+// ServiceAccountStatus describes an IRSA service account of the role
+type ServiceAccountStatus struct {
+	//Name of the service account
+	Name string `json:"name"`
+	//Created is true when the service account was created by iam-manager and false when an existing one was patched
+	// +optional
+	Created bool `json:"created,omitempty"`
+	//Synced is true when the service account exists with the expected annotations
+	Synced bool `json:"synced"`
+}
+
+// DriftStatus describes the differences found between the iam role in AWS and the spec
+type DriftStatus struct {
+	//DetectedTimestamp represents the time the drift was found
+	DetectedTimestamp metav1.Time `json:"detectedTimestamp"`
+	//Components which differed from the spec, e.g. policy, trust, boundary or tags
+	Components []string `json:"components"`
+	//Changes made to the iam role outside of iam-manager, e.g. an action added to a statement of an inline policy
+	// +optional
+	Changes []string `json:"changes,omitempty"`
+}
+
+type State string
+
+const (
+	Ready                State = "Ready"
+	Error                State = "Error"
+	PolicyNotAllowed     State = "PolicyNotAllowed"
+	RolesMaxLimitReached State = "RolesMaxLimitReached"
+	RoleNameNotAvailable State = "RoleNameNotAvailable"
+)
+
+// IamroleStatus defines the observed state of Iamrole
+type IamroleStatus struct {
+	//RoleName represents the name of the iam role created in AWS
+	// +optional
+	RoleName string `json:"roleName,omitempty"`
+	//RoleARN represents the ARN of an IAM role
+	// +optional
+	RoleARN string `json:"roleARN,omitempty"`
+	//RoleID represents the unique ID of the role which can be used in S3 policy etc
+	// +optional
+	RoleID string `json:"roleID,omitempty"`
+	//State of the resource. It is kept for v1alpha1 compatibility, prefer the Ready condition
+	// +optional
+	State State `json:"state,omitempty"`
+	//RetryCount in case of error
+	// +optional
+	RetryCount int `json:"retryCount,omitempty"`
+	//ErrorDescription in case of error
+	// +optional
+	ErrorDescription string `json:"errorDescription,omitempty"`
+	//LastUpdatedTimestamp represents the last time the iam role has been modified
+	// +optional
+	LastUpdatedTimestamp metav1.Time `json:"lastUpdatedTimestamp,omitempty"`
+	//ManagedPolicyArns represents the managed policies attached to the role by iam-manager
+	// +optional
+	ManagedPolicyArns []string `json:"managedPolicyArns,omitempty"`
+	//InlinePolicyNames represents the additional inline policies put on the role by iam-manager
+	// +optional
+	InlinePolicyNames []string `json:"inlinePolicyNames,omitempty"`
+	//TagKeys represents the custom tags attached to the role by iam-manager
+	// +optional
+	TagKeys []string `json:"tagKeys,omitempty"`
+	//ServiceAccounts represents the IRSA service accounts handled by iam-manager
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ServiceAccounts []ServiceAccountStatus `json:"serviceAccounts,omitempty"`
+	//ObservedGeneration represents the generation of the spec the status was computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	//LastDrift represents the last time the iam role in AWS was found different from the spec, before it was reverted
+	// +optional
+	LastDrift *DriftStatus `json:"lastDrift,omitempty"`
+	//Conditions represent the latest observations of the iam role
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=iamroles,scope=Namespaced,shortName=iam,singular=iamrole
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="whether the iam role matches the spec"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state",description="current state of the iam role"
+// +kubebuilder:printcolumn:name="RoleName",type="string",JSONPath=".status.roleName",description="Name of the role"
+// +kubebuilder:printcolumn:name="LastUpdatedTimestamp",type="string",format="date-time",JSONPath=".status.lastUpdatedTimestamp",description="last updated iam role timestamp"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="time passed since iamrole creation"
+// Iamrole is the Schema for the iamroles API
+type Iamrole struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IamroleSpec   `json:"spec,omitempty"`
+	Status IamroleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IamroleList contains a list of Iamrole
+type IamroleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Iamrole `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Iamrole{}, &IamroleList{})
+}
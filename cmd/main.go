@@ -18,14 +18,18 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
+	"time"
 
 	// +kubebuilder:scaffold:imports
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -35,13 +39,17 @@ import (
 	// +kubebuilder:scaffold:imports
 
 	iammanagerv1alpha1 "github.com/keikoproj/iam-manager/api/v1alpha1"
+	iammanagerv1beta1 "github.com/keikoproj/iam-manager/api/v1beta1"
 	"github.com/keikoproj/iam-manager/internal/config"
 	appconfig "github.com/keikoproj/iam-manager/internal/config"
 	"github.com/keikoproj/iam-manager/internal/controllers"
 	"github.com/keikoproj/iam-manager/internal/utils"
+	"github.com/keikoproj/iam-manager/pkg/audit"
 	"github.com/keikoproj/iam-manager/pkg/awsapi"
+	fakeiam "github.com/keikoproj/iam-manager/pkg/awsapi/fake"
 	"github.com/keikoproj/iam-manager/pkg/k8s"
 	"github.com/keikoproj/iam-manager/pkg/logging"
+	"github.com/keikoproj/iam-manager/pkg/tracing"
 	_ "go.uber.org/mock/mockgen/model"
 )
 
@@ -53,29 +61,73 @@ func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 
 	_ = iammanagerv1alpha1.AddToScheme(scheme)
+	_ = iammanagerv1beta1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
-	var debug bool
+	var fakeAWS bool
+	var otlpEndpoint string
+	var otlpInsecure bool
+	var logOpts logging.Options
+	var auditLog string
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
-	flag.BoolVar(&debug, "debug", false, "Enable Debug?")
+	flag.BoolVar(&fakeAWS, "fake-aws", false, "Keep IAM roles in memory instead of AWS. Meant for local development only")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "", "The host:port of the OTLP/gRPC collector to send traces to. Tracing is disabled when neither this nor OTEL_EXPORTER_OTLP_ENDPOINT is set")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "Send traces to the OTLP collector without TLS")
+	flag.StringVar(&auditLog, "audit-log", "", "Write an audit record of every IAM change as JSON lines to this file, or to the standard output for -. Disabled when empty")
+	logOpts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
-	logging.New()
+	if err := logging.New(logOpts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	log := logging.Logger(context.Background(), "main", "setup")
 
-	go config.RunConfigMapInformer(context.Background())
+	shutdownTracing, err := tracing.Setup(context.Background(), otlpEndpoint, otlpInsecure)
+	if err != nil {
+		log.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	if auditLog != "" {
+		sink, closeAuditLog, err := audit.Open(auditLog)
+		if err != nil {
+			log.Error(err, "unable to open the audit log", "file", auditLog)
+			os.Exit(1)
+		}
+		defer closeAuditLog()
+		audit.SetSink(sink)
+		log.Info("Writing the audit log", "file", auditLog)
+	}
+
+	// flushTracing sends the spans still buffered before the process exits
+	flushTracing := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error(err, "unable to flush the traces")
+		}
+	}
+
+	// A config map file given in offline mode must not be overwritten by the config map in the cluster
+	if config.Props.OfflineConfigFile() == "" {
+		go config.RunConfigMapInformer(context.Background())
+	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
 		Cache: cache.Options{
 			SyncPeriod: config.Props.ResyncPeriod(),
 		},
+		// The service accounts are watched through their metadata only. Reading them must not cache them all
+		Client: client.Options{
+			Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.ServiceAccount{}}},
+		},
 		Metrics: metricsserver.Options{
 			BindAddress:    metricsAddr,
 			SecureServing:  true,
@@ -94,7 +146,22 @@ func main() {
 	log.V(1).Info("Setting up reconciler with manager")
 	config.Props.LogStartupConfig(log)
 
-	iamClient := awsapi.NewIAM(appconfig.Props.AWSRegion(), appconfig.Props.DisallowSameAccountDynamoDBAccess())
+	var iamClient *awsapi.IAM
+	switch {
+	case appconfig.Props.IsOffline():
+		fakeIAM, err := fakeiam.NewFileIAM(appconfig.Props.AWSAccountID(), appconfig.Props.OfflineIAMStateFile())
+		if err != nil {
+			log.Error(err, "unable to load the offline IAM state", "file", appconfig.Props.OfflineIAMStateFile())
+			os.Exit(1)
+		}
+		log.Info("Running offline, roles are kept in the state file instead of AWS", "file", appconfig.Props.OfflineIAMStateFile())
+		iamClient = &awsapi.IAM{Client: fakeIAM, DisallowSameAccountDynamoDBAccess: appconfig.Props.DisallowSameAccountDynamoDBAccess()}
+	case fakeAWS:
+		log.Info("Using the in-memory IAM backend, no role is created in AWS")
+		iamClient = &awsapi.IAM{Client: fakeiam.NewIAM(appconfig.Props.AWSAccountID()), DisallowSameAccountDynamoDBAccess: appconfig.Props.DisallowSameAccountDynamoDBAccess()}
+	default:
+		iamClient = awsapi.NewIAM(appconfig.Props.AWSRegion(), appconfig.Props.DisallowSameAccountDynamoDBAccess())
+	}
 	if err := handleOIDCSetupForIRSA(context.Background(), iamClient); err != nil {
 		log.Error(err, "unable to complete/verify oidc setup for IRSA")
 	}
@@ -118,8 +185,15 @@ func main() {
 		os.Exit(1)
 	}
 
-	//Get the client
-	iammanagerv1alpha1.NewWClient()
+	if config.Props.IsOrphanRoleGCEnabled() {
+		if err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			return controller.StartOrphanRoleGC(ctx)
+		})); err != nil {
+			log.Error(err, "unable to add StartOrphanRoleGC runnable to manager")
+			os.Exit(1)
+		}
+	}
+
 	if config.Props.IsWebHookEnabled() {
 		log.Info("Registering webhook")
 		if err = (&iammanagerv1alpha1.Iamrole{}).SetupWebhookWithManager(mgr); err != nil {
@@ -133,25 +207,31 @@ func main() {
 	log.Info("Registering controller")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		log.Error(err, "problem running manager")
+		flushTracing()
 		os.Exit(1)
 	}
+	flushTracing()
 }
 
 // handleOIDCSetupForIRSA will be used to setup the OIDC in AWS IAM
-func handleOIDCSetupForIRSA(ctx context.Context, iamClient *awsapi.IAM) error {
+func handleOIDCSetupForIRSA(ctx context.Context, iamClient awsapi.IAMIface) error {
 	log := logging.Logger(ctx, "main", "handleOIDCSetupForIRSA")
 
 	//Creating OIDC provider if config map has an entry
 
 	if config.Props.IsIRSAEnabled() {
-		//Fetch cert thumb print
-		thumbprint, err := utils.GetIdpServerCertThumbprint(context.Background(), config.Props.OIDCIssuerUrl())
-		if err != nil {
-			log.Error(err, "unable to get the OIDC IDP server thumbprint")
-			return err
+		//Fetch cert thumb print, there is no issuer to dial in offline mode
+		thumbprint := config.OfflineOIDCThumbprint
+		if !config.Props.IsOffline() {
+			var err error
+			thumbprint, err = utils.GetIdpServerCertThumbprint(context.Background(), config.Props.OIDCIssuerUrl())
+			if err != nil {
+				log.Error(err, "unable to get the OIDC IDP server thumbprint")
+				return err
+			}
 		}
 
-		err = iamClient.CreateOIDCProvider(ctx, config.Props.OIDCIssuerUrl(), config.OIDCAudience, thumbprint)
+		err := iamClient.CreateOIDCProvider(ctx, config.Props.OIDCIssuerUrl(), config.OIDCAudience, thumbprint)
 		if err != nil {
 			log.Error(err, "unable to setup OIDC with the url", "url", config.Props.OIDCIssuerUrl())
 			return err
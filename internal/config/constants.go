@@ -31,6 +31,12 @@ const (
 	// user managed policies
 	propertyManagedPolicies = "iam.managed.policies"
 
+	// managed policy ARNs (or * patterns) which can be attached through spec.ManagedPolicyArns
+	propertyAllowedManagedPolicies = "iam.managed.policies.allowed"
+
+	// role names (or * patterns) of pre-existing roles which can be adopted through spec.AdoptRoleARN
+	propertyAdoptableRoles = "iam.role.adopt.allowed"
+
 	// user managed permission boundary policy
 	propertyPermissionBoundary = "iam.managed.permission.boundary.policy"
 
@@ -43,6 +49,18 @@ const (
 	//max allowed aws iam roles per namespace
 	propertyMaxIamRoles = "iam.role.max.limit.per.namespace"
 
+	//propertyRoleCountFailurePolicy decides if the webhook denies (Fail) or admits (Ignore) an Iamrole when the roles of the namespace can't be counted
+	propertyRoleCountFailurePolicy = "webhook.role.count.failure.policy"
+
+	//lower bound in seconds for spec.MaxSessionDuration
+	propertyMinSessionDuration = "iam.role.max.session.duration.min"
+
+	//upper bound in seconds for spec.MaxSessionDuration. It is also used when spec.MaxSessionDuration is not provided
+	propertyMaxSessionDuration = "iam.role.max.session.duration.max"
+
+	//prefix every spec.Path must start with
+	propertyRolePathPrefix = "iam.role.path.prefix"
+
 	//propertyDesiredStateFrequency is a configurable param to make sure to check the external state (in seconds). default to 30 mins (1800 seconds)
 	propertyDesiredStateFrequency = "controller.desired.frequency"
 
@@ -69,6 +87,24 @@ const (
 
 	//propertyDisallowSameAccountDynamoDBAccess can be used to enable validation that prevents adding same-account DynamoDB access when it wasn't previously allowed
 	propertyDisallowSameAccountDynamoDBAccess = "iam.policy.dynamodb.same.account.disallow"
+
+	//propertyAllowNotAction can be used to permit Allow statements with NotAction, which otherwise bypass the action allow list
+	propertyAllowNotAction = "iam.policy.notaction.allow"
+
+	//propertyDeletionPolicy is the default deletion policy of the roles: Delete, Retain or RetainWithoutPolicies
+	propertyDeletionPolicy = "iam.role.deletion.policy"
+
+	//propertyOrphanRoleGCEnabled enables the garbage collector for iam roles of this cluster which have no Iamrole anymore
+	propertyOrphanRoleGCEnabled = "iam.role.gc.enabled"
+
+	//propertyOrphanRoleGCInterval is the time in seconds between two garbage collector runs
+	propertyOrphanRoleGCInterval = "iam.role.gc.interval"
+
+	//propertyOrphanRoleGCGracePeriod is the time in seconds a role must stay orphaned before it is deleted
+	propertyOrphanRoleGCGracePeriod = "iam.role.gc.grace.period"
+
+	//propertyOrphanRoleGCDryRun only reports orphaned roles. It must be set to false to delete them
+	propertyOrphanRoleGCDryRun = "iam.role.gc.dry.run"
 )
 
 const (
@@ -89,6 +125,21 @@ const (
 	// names without changing the cluster-wide naming template. Opt-in
 	// per CR; CRs without this annotation are unaffected.
 	IamManagerRoleNameSuffixAnnotation = "iammanager.keikoproj.io/additional-role"
+
+	// IamManagerServiceAccountOwnerAnnotation names the Iamrole which annotated an IRSA service account
+	IamManagerServiceAccountOwnerAnnotation = "iammanager.keikoproj.io/iamrole"
+
+	// IamManagerManagedAnnotationsAnnotation and IamManagerManagedLabelsAnnotation list the annotations and labels
+	// iam-manager added to an IRSA service account. Only these are removed when the service account is released
+	IamManagerManagedAnnotationsAnnotation = "iammanager.keikoproj.io/managed-annotations"
+	IamManagerManagedLabelsAnnotation      = "iammanager.keikoproj.io/managed-labels"
+
+	// IamManagerLastModifiedByAnnotation is the Kubernetes user who made the last change to an Iamrole.
+	// It is set by the mutating webhook and written to the audit log
+	IamManagerLastModifiedByAnnotation = "iammanager.keikoproj.io/last-modified-by"
+
+	// ManagedByLabel is set to iam-manager on the IRSA service accounts created by iam-manager
+	ManagedByLabel = "app.kubernetes.io/managed-by"
 )
 
 const (
@@ -99,4 +150,40 @@ const (
 
 	// DefaultResyncPeriodSeconds is the default cache resync period in seconds (10 hours). Use 0 in config to disable resync.
 	DefaultResyncPeriodSeconds = 36000
+
+	// DefaultMinSessionDuration and DefaultMaxSessionDuration are the limits AWS allows for a role max session duration
+	DefaultMinSessionDuration = 3600
+	DefaultMaxSessionDuration = 43200
+
+	// DefaultRolePathPrefix allows any IAM path
+	DefaultRolePathPrefix = "/"
+
+	// DefaultRoleDescription is used when spec.Description is not provided
+	DefaultRoleDescription = "#DO NOT DELETE#. Managed by iam-manager"
+
+	// DefaultDeletionPolicy deletes the role together with the Iamrole
+	DefaultDeletionPolicy = "Delete"
+
+	// RoleCountFailurePolicyFail denies the Iamrole and RoleCountFailurePolicyIgnore admits it when the roles can't be counted
+	RoleCountFailurePolicyFail   = "Fail"
+	RoleCountFailurePolicyIgnore = "Ignore"
+
+	// DefaultOrphanRoleGCInterval (1 hour) and DefaultOrphanRoleGCGracePeriod (1 day) are in seconds
+	DefaultOrphanRoleGCInterval    = 3600
+	DefaultOrphanRoleGCGracePeriod = 86400
+
+	// OrphanRoleGCMinimumInterval prevents listing every role of the account too often
+	OrphanRoleGCMinimumInterval = 300
+
+	// OfflineAWSAccountID is used in offline mode when aws.accountId is not set, instead of asking STS
+	OfflineAWSAccountID = "000000000000"
+
+	// OfflineOIDCIssuerUrl is used in offline mode when IRSA is enabled without k8s.cluster.oidc.issuer.url, instead of asking EKS
+	OfflineOIDCIssuerUrl = "https://oidc.iam-manager.local"
+
+	// OfflineOIDCThumbprint is registered with the OIDC provider in offline mode, as there is no issuer to dial
+	OfflineOIDCThumbprint = "0000000000000000000000000000000000000000"
+
+	// DefaultOfflineIAMStateFile keeps the roles of the in-memory IAM backend in offline mode
+	DefaultOfflineIAMStateFile = "iam-manager-offline-iam.json"
 )
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/keikoproj/iam-manager/pkg/awsapi"
@@ -28,10 +30,16 @@ type Properties struct {
 	restrictedS3Resources             []string
 	awsAccountID                      string
 	managedPolicies                   []string
+	allowedManagedPolicies            []string
+	adoptableRoles                    []string
 	managedPermissionBoundaryPolicy   string
 	awsRegion                         string
 	isWebhookEnabled                  string
 	maxRolesAllowed                   int
+	roleCountFailurePolicy            string
+	minSessionDuration                int64
+	maxSessionDuration                int64
+	rolePathPrefix                    string
 	controllerDesiredFrequency        int
 	maxConcurrentReconciles           int
 	resyncPeriodSeconds               int
@@ -42,6 +50,15 @@ type Properties struct {
 	iamRolePattern                    string
 	isIRSARegionalEndpointDisabled    string
 	disallowSameAccountDynamoDBAccess string
+	isNotActionAllowed                string
+	deletionPolicy                    string
+	isOrphanRoleGCEnabled             string
+	orphanRoleGCIntervalSeconds       int
+	orphanRoleGCGracePeriodSeconds    int
+	isOrphanRoleGCDryRun              string
+	isOffline                         string
+	offlineConfigFile                 string
+	offlineIAMStateFile               string
 }
 
 func init() {
@@ -57,12 +74,31 @@ func init() {
 		return
 	}
 
+	// Offline mode can take the config map from a file so that no cluster is needed to start
+	if file := os.Getenv("OFFLINE_CONFIG_FILE"); os.Getenv("OFFLINE") == "true" && file != "" {
+		res, err := ReadConfigMapFile(file)
+		if err != nil {
+			log.Error(err, "unable to read the config map file", "file", file)
+			panic(err)
+		}
+		if err := LoadProperties("", res); err != nil {
+			log.Error(err, "failed to load properties")
+			panic(err)
+		}
+		log.Info("Loaded properties in init func from file for offline mode", "file", file)
+		return
+	}
+
 	k8sClient, err := k8s.NewK8sClient()
 	if err != nil {
 		log.Error(err, "unable to create new k8s client")
 		panic(err)
 	}
-	res := k8sClient.GetConfigMap(context.Background(), IamManagerNamespaceName, IamManagerConfigMapName)
+	res, err := k8sClient.GetConfigMap(context.Background(), IamManagerNamespaceName, IamManagerConfigMapName)
+	if err != nil {
+		log.Error(err, "unable to get the config map", "namespace", IamManagerNamespaceName, "name", IamManagerConfigMapName)
+		panic(err)
+	}
 
 	// load properties into a global variable
 	err = LoadProperties("", res)
@@ -84,6 +120,8 @@ func LoadProperties(env string, cm ...*v1.ConfigMap) error {
 			restrictedS3Resources:           strings.Split(os.Getenv("RESTRICTED_S3_RESOURCES"), separator),
 			awsAccountID:                    os.Getenv("AWS_ACCOUNT_ID"),
 			managedPolicies:                 strings.Split(os.Getenv("MANAGED_POLICIES"), separator),
+			allowedManagedPolicies:          strings.Split(os.Getenv("ALLOWED_MANAGED_POLICIES"), separator),
+			adoptableRoles:                  strings.Split(os.Getenv("ADOPTABLE_ROLES"), separator),
 			managedPermissionBoundaryPolicy: os.Getenv("MANAGED_PERMISSION_BOUNDARY_POLICY"),
 			awsRegion:                       os.Getenv("AWS_REGION"),
 			isWebhookEnabled:                os.Getenv("ENABLE_WEBHOOK"),
@@ -92,6 +130,16 @@ func LoadProperties(env string, cm ...*v1.ConfigMap) error {
 			defaultTrustPolicy:              os.Getenv("DEFAULT_TRUST_POLICY"),
 			iamRolePattern:                  os.Getenv("IAM_ROLE_PATTERN"),
 			isIRSARegionalEndpointDisabled:  os.Getenv("IRSA_REGIONAL_ENDPOINT_DISABLED"),
+			isNotActionAllowed:              os.Getenv("ALLOW_NOT_ACTION"),
+			minSessionDuration:              DefaultMinSessionDuration,
+			maxSessionDuration:              DefaultMaxSessionDuration,
+			rolePathPrefix:                  DefaultRolePathPrefix,
+			deletionPolicy:                  DefaultDeletionPolicy,
+			roleCountFailurePolicy:          RoleCountFailurePolicyFail,
+			isOrphanRoleGCEnabled:           os.Getenv("ORPHAN_ROLE_GC_ENABLED"),
+			orphanRoleGCIntervalSeconds:     DefaultOrphanRoleGCInterval,
+			orphanRoleGCGracePeriodSeconds:  DefaultOrphanRoleGCGracePeriod,
+			isOrphanRoleGCDryRun:            os.Getenv("ORPHAN_ROLE_GC_DRY_RUN"),
 		}
 		return nil
 	}
@@ -140,6 +188,42 @@ func LoadProperties(env string, cm ...*v1.ConfigMap) error {
 		Props.maxRolesAllowed = 1
 	}
 
+	Props.roleCountFailurePolicy = RoleCountFailurePolicyFail
+	if roleCountFailurePolicy := cm[0].Data[propertyRoleCountFailurePolicy]; roleCountFailurePolicy != "" {
+		if roleCountFailurePolicy != RoleCountFailurePolicyFail && roleCountFailurePolicy != RoleCountFailurePolicyIgnore {
+			return fmt.Errorf("%s must be one of Fail or Ignore", propertyRoleCountFailurePolicy)
+		}
+		Props.roleCountFailurePolicy = roleCountFailurePolicy
+	}
+
+	Props.minSessionDuration = DefaultMinSessionDuration
+	if minSessionDuration := cm[0].Data[propertyMinSessionDuration]; minSessionDuration != "" {
+		n, parseErr := strconv.ParseInt(minSessionDuration, 10, 64)
+		if parseErr != nil {
+			return parseErr
+		}
+		Props.minSessionDuration = max(n, DefaultMinSessionDuration)
+	}
+
+	Props.maxSessionDuration = DefaultMaxSessionDuration
+	if maxSessionDuration := cm[0].Data[propertyMaxSessionDuration]; maxSessionDuration != "" {
+		n, parseErr := strconv.ParseInt(maxSessionDuration, 10, 64)
+		if parseErr != nil {
+			return parseErr
+		}
+		Props.maxSessionDuration = min(n, DefaultMaxSessionDuration)
+	}
+	if Props.minSessionDuration > Props.maxSessionDuration {
+		return fmt.Errorf("%s must not be greater than %s", propertyMinSessionDuration, propertyMaxSessionDuration)
+	}
+
+	rolePathPrefix := cm[0].Data[propertyRolePathPrefix]
+	if rolePathPrefix == "" {
+		Props.rolePathPrefix = DefaultRolePathPrefix
+	} else {
+		Props.rolePathPrefix = rolePathPrefix
+	}
+
 	controllerDesiredFreq := cm[0].Data[propertyDesiredStateFrequency]
 	if controllerDesiredFreq != "" {
 		controllerDesiredFreq, err := strconv.Atoi(controllerDesiredFreq)
@@ -179,7 +263,22 @@ func LoadProperties(env string, cm ...*v1.ConfigMap) error {
 		Props.resyncPeriodSeconds = DefaultResyncPeriodSeconds
 	}
 
+	// Offline mode runs the controller without AWS, e.g. against kind, so STS and EKS are never called
+	if os.Getenv("OFFLINE") == "true" {
+		Props.isOffline = "true"
+		Props.offlineConfigFile = os.Getenv("OFFLINE_CONFIG_FILE")
+		Props.offlineIAMStateFile = os.Getenv("OFFLINE_IAM_STATE_FILE")
+		if Props.offlineIAMStateFile == "" {
+			Props.offlineIAMStateFile = DefaultOfflineIAMStateFile
+		}
+	} else {
+		Props.isOffline = "false"
+	}
+
 	awsAccountID := cm[0].Data[propertyAWSAccountID]
+	if awsAccountID == "" && Props.IsOffline() {
+		awsAccountID = OfflineAWSAccountID
+	}
 	// Load AWS account ID
 	if Props.awsAccountID == "" && awsAccountID == "" {
 		awsAccountID, err := awsapi.NewSTS(Props.awsRegion).GetAccountID(context.Background())
@@ -220,6 +319,18 @@ func LoadProperties(env string, cm ...*v1.ConfigMap) error {
 	}
 	Props.managedPolicies = managedPolicies
 
+	allowedManagedPolicies := strings.Split(cm[0].Data[propertyAllowedManagedPolicies], separator)
+	for i := range allowedManagedPolicies {
+		if allowedManagedPolicies[i] != "" {
+			if !strings.HasPrefix(allowedManagedPolicies[i], "arn:aws:iam::") {
+				allowedManagedPolicies[i] = fmt.Sprintf(PolicyARNFormat, awsAccountID, allowedManagedPolicies[i])
+			}
+		}
+	}
+	Props.allowedManagedPolicies = allowedManagedPolicies
+
+	Props.adoptableRoles = strings.Split(cm[0].Data[propertyAdoptableRoles], separator)
+
 	isIRSAEnabled := cm[0].Data[propertyIRSAEnabled]
 	if isIRSAEnabled == "true" {
 		Props.isIRSAEnabled = "true"
@@ -228,6 +339,9 @@ func LoadProperties(env string, cm ...*v1.ConfigMap) error {
 	}
 
 	oidcUrl := cm[0].Data[propertyK8sClusterOIDCIssuerUrl]
+	if isIRSAEnabled == "true" && oidcUrl == "" && Props.IsOffline() {
+		oidcUrl = OfflineOIDCIssuerUrl
+	}
 	if isIRSAEnabled == "true" && oidcUrl == "" {
 		if clusterName == "" {
 			return fmt.Errorf("cluster name must be provided when IRSA is enabled to retrieve the OIDC url")
@@ -255,6 +369,53 @@ func LoadProperties(env string, cm ...*v1.ConfigMap) error {
 		Props.disallowSameAccountDynamoDBAccess = "false"
 	}
 
+	isNotActionAllowed := cm[0].Data[propertyAllowNotAction]
+	if isNotActionAllowed == "true" {
+		Props.isNotActionAllowed = "true"
+	} else {
+		Props.isNotActionAllowed = "false"
+	}
+
+	Props.deletionPolicy = DefaultDeletionPolicy
+	if deletionPolicy := cm[0].Data[propertyDeletionPolicy]; deletionPolicy != "" {
+		if deletionPolicy != "Delete" && deletionPolicy != "Retain" && deletionPolicy != "RetainWithoutPolicies" {
+			return fmt.Errorf("%s must be one of Delete, Retain or RetainWithoutPolicies", propertyDeletionPolicy)
+		}
+		Props.deletionPolicy = deletionPolicy
+	}
+
+	isOrphanRoleGCEnabled := cm[0].Data[propertyOrphanRoleGCEnabled]
+	if isOrphanRoleGCEnabled == "true" {
+		Props.isOrphanRoleGCEnabled = "true"
+	} else {
+		Props.isOrphanRoleGCEnabled = "false"
+	}
+
+	Props.orphanRoleGCIntervalSeconds = DefaultOrphanRoleGCInterval
+	if orphanRoleGCInterval := cm[0].Data[propertyOrphanRoleGCInterval]; orphanRoleGCInterval != "" {
+		n, parseErr := strconv.Atoi(orphanRoleGCInterval)
+		if parseErr != nil {
+			return parseErr
+		}
+		Props.orphanRoleGCIntervalSeconds = max(n, OrphanRoleGCMinimumInterval)
+	}
+
+	Props.orphanRoleGCGracePeriodSeconds = DefaultOrphanRoleGCGracePeriod
+	if orphanRoleGCGracePeriod := cm[0].Data[propertyOrphanRoleGCGracePeriod]; orphanRoleGCGracePeriod != "" {
+		n, parseErr := strconv.Atoi(orphanRoleGCGracePeriod)
+		if parseErr != nil {
+			return parseErr
+		}
+		Props.orphanRoleGCGracePeriodSeconds = max(n, 0)
+	}
+
+	// Deleting roles must be explicitly requested
+	if cm[0].Data[propertyOrphanRoleGCDryRun] == "false" {
+		Props.isOrphanRoleGCDryRun = "false"
+	} else {
+		Props.isOrphanRoleGCDryRun = "true"
+	}
+
 	return nil
 }
 
@@ -274,6 +435,56 @@ func (p *Properties) ManagedPolicies() []string {
 	return p.managedPolicies
 }
 
+func (p *Properties) AllowedManagedPolicies() []string {
+	return p.allowedManagedPolicies
+}
+
+// IsManagedPolicyAllowed returns true if the policy ARN can be attached through spec.ManagedPolicyArns.
+// Cluster wide managed policies are always allowed and allow list entries can use * as a wildcard
+func (p *Properties) IsManagedPolicyAllowed(policyArn string) bool {
+	for _, policy := range p.managedPolicies {
+		if policy != "" && policy == policyArn {
+			return true
+		}
+	}
+	return matchesAnyPattern(p.allowedManagedPolicies, policyArn)
+}
+
+// AdoptableRoles returns the role names (or * patterns) of pre-existing roles which can be adopted
+func (p *Properties) AdoptableRoles() []string {
+	return p.adoptableRoles
+}
+
+// IsRoleAdoptionAllowed returns true if a pre-existing role with this name can be adopted through spec.AdoptRoleARN.
+// Allow list entries can use * as a wildcard
+func (p *Properties) IsRoleAdoptionAllowed(roleName string) bool {
+	return matchesAnyPattern(p.adoptableRoles, roleName)
+}
+
+// matchesAnyPattern returns true if the value matches one of the patterns where * is a wildcard
+func matchesAnyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		if matched, _ := regexp.MatchString(expr, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSessionDurationAllowed returns true if the max session duration in seconds is within the configured bounds
+func (p *Properties) IsSessionDurationAllowed(duration int64) bool {
+	return duration >= p.minSessionDuration && duration <= p.maxSessionDuration
+}
+
+// IsRolePathAllowed returns true if the IAM path starts with the configured role path prefix
+func (p *Properties) IsRolePathAllowed(path string) bool {
+	return strings.HasPrefix(path, p.rolePathPrefix)
+}
+
 func (p *Properties) AWSAccountID() string {
 	return p.awsAccountID
 }
@@ -302,6 +513,26 @@ func (p *Properties) MaxRolesAllowed() int {
 	return p.maxRolesAllowed
 }
 
+// RoleCountFailurePolicy returns Fail or Ignore, what the webhook does when it can't count the roles of a namespace
+func (p *Properties) RoleCountFailurePolicy() string {
+	return p.roleCountFailurePolicy
+}
+
+// MinSessionDuration returns the lower bound in seconds for the role max session duration
+func (p *Properties) MinSessionDuration() int64 {
+	return p.minSessionDuration
+}
+
+// MaxSessionDuration returns the upper bound in seconds for the role max session duration
+func (p *Properties) MaxSessionDuration() int64 {
+	return p.maxSessionDuration
+}
+
+// RolePathPrefix returns the prefix every role path must start with
+func (p *Properties) RolePathPrefix() string {
+	return p.rolePathPrefix
+}
+
 func (p *Properties) ControllerDesiredFrequency() int {
 	return p.controllerDesiredFrequency
 }
@@ -338,6 +569,7 @@ func (p *Properties) LogStartupConfig(log logr.Logger) {
 		"cluster.name", p.ClusterName(),
 		"k8s.cluster.oidc.issuer.url", p.OIDCIssuerUrl(),
 		"webhook.enabled", p.IsWebHookEnabled(),
+		"webhook.role.count.failure.policy", p.RoleCountFailurePolicy(),
 		"iam.irsa.enabled", p.IsIRSAEnabled(),
 		"iam.irsa.regional.endpoint.disabled", p.IsIRSARegionalEndpointDisabled(),
 		"iam.role.pattern", p.IamRolePattern(),
@@ -351,10 +583,52 @@ func (p *Properties) LogStartupConfig(log logr.Logger) {
 		"restricted.policy.resources", p.RestrictedPolicyResources(),
 		"restricted.s3.resources", p.RestrictedS3Resources(),
 		"managed.policies", p.ManagedPolicies(),
+		"managed.policies.allowed", p.AllowedManagedPolicies(),
+		"iam.role.max.session.duration.min", p.MinSessionDuration(),
+		"iam.role.max.session.duration.max", p.MaxSessionDuration(),
+		"iam.role.path.prefix", p.RolePathPrefix(),
 		"iam.policy.dynamodb.same.account.disallow", p.DisallowSameAccountDynamoDBAccess(),
+		"iam.policy.notaction.allow", p.IsNotActionAllowed(),
+		"iam.role.adopt.allowed", p.AdoptableRoles(),
+		"iam.role.deletion.policy", p.DeletionPolicy(),
+		"iam.role.gc.enabled", p.IsOrphanRoleGCEnabled(),
+		"iam.role.gc.interval", p.orphanRoleGCIntervalSeconds,
+		"iam.role.gc.grace.period", p.orphanRoleGCGracePeriodSeconds,
+		"iam.role.gc.dry.run", p.IsOrphanRoleGCDryRun(),
+		"offline", p.IsOffline(),
 	)
 }
 
+// IsOffline returns true when the controller runs without AWS, set with the OFFLINE=true environment variable
+func (p *Properties) IsOffline() bool {
+	return p.isOffline == "true"
+}
+
+// OfflineConfigFile returns the config map file loaded in offline mode instead of the config map in the cluster
+func (p *Properties) OfflineConfigFile() string {
+	return p.offlineConfigFile
+}
+
+// OfflineIAMStateFile returns the file which keeps the roles of the in-memory IAM backend in offline mode
+func (p *Properties) OfflineIAMStateFile() string {
+	return p.offlineIAMStateFile
+}
+
+// ReadConfigMapFile reads a config map manifest in YAML or JSON
+func ReadConfigMapFile(path string) (*v1.ConfigMap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cm := &v1.ConfigMap{}
+	if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(cm); err != nil {
+		return nil, fmt.Errorf("unable to decode config map %s: %v", path, err)
+	}
+	return cm, nil
+}
+
 func (p *Properties) IsIRSAEnabled() bool {
 	resp := false
 	if p.isIRSAEnabled == "true" {
@@ -391,6 +665,40 @@ func (p *Properties) DisallowSameAccountDynamoDBAccess() bool {
 	return resp
 }
 
+// IsNotActionAllowed returns true if Allow statements are permitted to use NotAction
+func (p *Properties) IsNotActionAllowed() bool {
+	resp := false
+	if p.isNotActionAllowed == "true" {
+		resp = true
+	}
+	return resp
+}
+
+// DeletionPolicy returns the deletion policy of the roles which don't set spec.DeletionPolicy
+func (p *Properties) DeletionPolicy() string {
+	return p.deletionPolicy
+}
+
+// IsOrphanRoleGCEnabled returns true if iam roles of this cluster without an Iamrole are garbage collected
+func (p *Properties) IsOrphanRoleGCEnabled() bool {
+	return p.isOrphanRoleGCEnabled == "true"
+}
+
+// OrphanRoleGCInterval returns the time between two garbage collector runs
+func (p *Properties) OrphanRoleGCInterval() time.Duration {
+	return time.Duration(p.orphanRoleGCIntervalSeconds) * time.Second
+}
+
+// OrphanRoleGCGracePeriod returns the time a role must stay orphaned before the garbage collector deletes it
+func (p *Properties) OrphanRoleGCGracePeriod() time.Duration {
+	return time.Duration(p.orphanRoleGCGracePeriodSeconds) * time.Second
+}
+
+// IsOrphanRoleGCDryRun returns true if orphaned roles are only reported. This is the default
+func (p *Properties) IsOrphanRoleGCDryRun() bool {
+	return p.isOrphanRoleGCDryRun != "false"
+}
+
 func RunConfigMapInformer(ctx context.Context) {
 	log := logging.Logger(context.Background(), "internal.config.properties", "RunConfigMapInformer")
 	cmInformer := k8s.GetConfigMapInformer(ctx, IamManagerNamespaceName, IamManagerConfigMapName)
@@ -2,8 +2,11 @@ package config
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"go.uber.org/mock/gomock"
 	"gopkg.in/check.v1"
@@ -142,6 +145,71 @@ func (s *PropertiesSuite) TestLoadPropertiesSuccessWithCustom(c *check.C) {
 	c.Assert(Props.IsIRSARegionalEndpointDisabled(), check.Equals, true)
 }
 
+func (s *PropertiesSuite) TestIsManagedPolicyAllowed(c *check.C) {
+	Props = nil
+	cm := &v1.ConfigMap{
+		Data: map[string]string{
+			"aws.accountId":                "123456789012",
+			"iam.managed.policies":         "DescribeEC2",
+			"iam.managed.policies.allowed": "team-*,arn:aws:iam::aws:policy/ReadOnlyAccess",
+		},
+	}
+	err := LoadProperties("", cm)
+	c.Assert(err, check.IsNil)
+	c.Assert(Props.IsManagedPolicyAllowed("arn:aws:iam::123456789012:policy/DescribeEC2"), check.Equals, true)
+	c.Assert(Props.IsManagedPolicyAllowed("arn:aws:iam::123456789012:policy/team-a/reader"), check.Equals, true)
+	c.Assert(Props.IsManagedPolicyAllowed("arn:aws:iam::aws:policy/ReadOnlyAccess"), check.Equals, true)
+	c.Assert(Props.IsManagedPolicyAllowed("arn:aws:iam::aws:policy/AdministratorAccess"), check.Equals, false)
+	c.Assert(Props.IsManagedPolicyAllowed("arn:aws:iam::210987654321:policy/team-a"), check.Equals, false)
+}
+
+func (s *PropertiesSuite) TestRoleSettingsProperties(c *check.C) {
+	Props = nil
+	cm := &v1.ConfigMap{
+		Data: map[string]string{
+			"aws.accountId":                     "123456789012",
+			"iam.role.max.session.duration.min": "7200",
+			"iam.role.max.session.duration.max": "14400",
+			"iam.role.path.prefix":              "/k8s/my-cluster/",
+		},
+	}
+	err := LoadProperties("", cm)
+	c.Assert(err, check.IsNil)
+	c.Assert(Props.MaxSessionDuration(), check.Equals, int64(14400))
+	c.Assert(Props.IsSessionDurationAllowed(7200), check.Equals, true)
+	c.Assert(Props.IsSessionDurationAllowed(3600), check.Equals, false)
+	c.Assert(Props.IsSessionDurationAllowed(43200), check.Equals, false)
+	c.Assert(Props.IsRolePathAllowed("/k8s/my-cluster/team/"), check.Equals, true)
+	c.Assert(Props.IsRolePathAllowed("/k8s/other-cluster/"), check.Equals, false)
+}
+
+func (s *PropertiesSuite) TestRoleSettingsPropertiesDefaults(c *check.C) {
+	Props = nil
+	cm := &v1.ConfigMap{
+		Data: map[string]string{
+			"aws.accountId": "123456789012",
+		},
+	}
+	err := LoadProperties("", cm)
+	c.Assert(err, check.IsNil)
+	c.Assert(Props.MinSessionDuration(), check.Equals, int64(DefaultMinSessionDuration))
+	c.Assert(Props.MaxSessionDuration(), check.Equals, int64(DefaultMaxSessionDuration))
+	c.Assert(Props.RolePathPrefix(), check.Equals, DefaultRolePathPrefix)
+}
+
+func (s *PropertiesSuite) TestRoleSettingsPropertiesInvalidBounds(c *check.C) {
+	Props = nil
+	cm := &v1.ConfigMap{
+		Data: map[string]string{
+			"aws.accountId":                     "123456789012",
+			"iam.role.max.session.duration.min": "14400",
+			"iam.role.max.session.duration.max": "7200",
+		},
+	}
+	err := LoadProperties("", cm)
+	c.Assert(err, check.NotNil)
+}
+
 func (s *PropertiesSuite) TestGetAllowedPolicyAction(c *check.C) {
 	value := Props.AllowedPolicyAction()
 	c.Assert(value, check.NotNil)
@@ -212,3 +280,110 @@ func (s *PropertiesSuite) TestIsIRSARegionalEndpointDisabled(c *check.C) {
 	value := Props.IsIRSARegionalEndpointDisabled()
 	c.Assert(value, check.Equals, false)
 }
+
+func (s *PropertiesSuite) TestLoadPropertiesOrphanRoleGC(c *check.C) {
+	Props = nil
+	cm := &v1.ConfigMap{
+		Data: map[string]string{
+			"aws.accountId":            "123456789012",
+			"iam.role.gc.enabled":      "true",
+			"iam.role.gc.interval":     "60",
+			"iam.role.gc.grace.period": "7200",
+		},
+	}
+	err := LoadProperties("", cm)
+	c.Assert(err, check.IsNil)
+	c.Assert(Props.IsOrphanRoleGCEnabled(), check.Equals, true)
+	c.Assert(Props.OrphanRoleGCInterval(), check.Equals, 300*time.Second)
+	c.Assert(Props.OrphanRoleGCGracePeriod(), check.Equals, 2*time.Hour)
+	c.Assert(Props.IsOrphanRoleGCDryRun(), check.Equals, true)
+
+	cm.Data["iam.role.gc.dry.run"] = "false"
+	err = LoadProperties("", cm)
+	c.Assert(err, check.IsNil)
+	c.Assert(Props.IsOrphanRoleGCDryRun(), check.Equals, false)
+}
+
+func (s *PropertiesSuite) TestLoadPropertiesDeletionPolicy(c *check.C) {
+	Props = nil
+	cm := &v1.ConfigMap{
+		Data: map[string]string{
+			"aws.accountId": "123456789012",
+		},
+	}
+	err := LoadProperties("", cm)
+	c.Assert(err, check.IsNil)
+	c.Assert(Props.DeletionPolicy(), check.Equals, "Delete")
+
+	cm.Data["iam.role.deletion.policy"] = "Retain"
+	err = LoadProperties("", cm)
+	c.Assert(err, check.IsNil)
+	c.Assert(Props.DeletionPolicy(), check.Equals, "Retain")
+
+	cm.Data["iam.role.deletion.policy"] = "Orphan"
+	err = LoadProperties("", cm)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *PropertiesSuite) TestLoadPropertiesRoleCountFailurePolicy(c *check.C) {
+	Props = nil
+	cm := &v1.ConfigMap{
+		Data: map[string]string{
+			"aws.accountId": "123456789012",
+		},
+	}
+	err := LoadProperties("", cm)
+	c.Assert(err, check.IsNil)
+	c.Assert(Props.RoleCountFailurePolicy(), check.Equals, "Fail")
+
+	cm.Data["webhook.role.count.failure.policy"] = "Ignore"
+	err = LoadProperties("", cm)
+	c.Assert(err, check.IsNil)
+	c.Assert(Props.RoleCountFailurePolicy(), check.Equals, "Ignore")
+
+	cm.Data["webhook.role.count.failure.policy"] = "Allow"
+	err = LoadProperties("", cm)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *PropertiesSuite) TestLoadPropertiesOffline(c *check.C) {
+	Props = nil
+	c.Assert(os.Setenv("OFFLINE", "true"), check.IsNil)
+	defer os.Unsetenv("OFFLINE")
+
+	cm := &v1.ConfigMap{
+		Data: map[string]string{
+			"iam.irsa.enabled": "true",
+			"k8s.cluster.name": "kind",
+		},
+	}
+	err := LoadProperties("", cm)
+	c.Assert(err, check.IsNil)
+	c.Assert(Props.IsOffline(), check.Equals, true)
+	c.Assert(Props.AWSAccountID(), check.Equals, OfflineAWSAccountID)
+	c.Assert(Props.OIDCIssuerUrl(), check.Equals, OfflineOIDCIssuerUrl)
+	c.Assert(Props.OfflineIAMStateFile(), check.Equals, DefaultOfflineIAMStateFile)
+	c.Assert(Props.ManagedPermissionBoundaryPolicy(), check.Equals, "arn:aws:iam::000000000000:policy/k8s-iam-manager-cluster-permission-boundary")
+}
+
+func (s *PropertiesSuite) TestReadConfigMapFile(c *check.C) {
+	path := filepath.Join(c.MkDir(), "configmap.yaml")
+	err := os.WriteFile(path, []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: iam-manager-iamroles-v1alpha1-configmap
+  namespace: iam-manager-system
+data:
+  aws.accountId: "123456789012"
+  iam.role.max.limit.per.namespace: "5"
+`), 0600)
+	c.Assert(err, check.IsNil)
+
+	cm, err := ReadConfigMapFile(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(cm.Data["aws.accountId"], check.Equals, "123456789012")
+	c.Assert(cm.Data["iam.role.max.limit.per.namespace"], check.Equals, "5")
+
+	_, err = ReadConfigMapFile(filepath.Join(c.MkDir(), "missing.yaml"))
+	c.Assert(err, check.NotNil)
+}
@@ -20,68 +20,103 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"math"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/pborman/uuid"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	iammanagerv1alpha1 "github.com/keikoproj/iam-manager/api/v1alpha1"
 	"github.com/keikoproj/iam-manager/internal/config"
 	"github.com/keikoproj/iam-manager/internal/utils"
+	"github.com/keikoproj/iam-manager/pkg/audit"
 	"github.com/keikoproj/iam-manager/pkg/awsapi"
 	"github.com/keikoproj/iam-manager/pkg/k8s"
 	"github.com/keikoproj/iam-manager/pkg/logging"
+	"github.com/keikoproj/iam-manager/pkg/tracing"
 	"github.com/keikoproj/iam-manager/pkg/validation"
 )
 
 const (
 	finalizerName      = "iamrole.finalizers.iammanager.keikoproj.io"
-	requestId          = "request_id"
 	maxWaitTime        = 300000 // 5 minutes
 	defaultRequeueTime = 3000   // 30s
+
+	// maxDriftChanges caps the changes kept in status.lastDrift and in the DriftDetected event
+	maxDriftChanges = 20
+
+	// IamroleServiceAccountIndex indexes Iamroles by the names of their IRSA service accounts
+	IamroleServiceAccountIndex = "iamrole.serviceAccounts"
 )
 
 // IamroleReconciler reconciles a Iamrole object
 type IamroleReconciler struct {
 	client.Client
-	IAMClient *awsapi.IAM
+	IAMClient awsapi.IAMIface
 	Recorder  record.EventRecorder
 }
 
-// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups=iammanager.keikoproj.io,resources=iamroles,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=iammanager.keikoproj.io,resources=iamroles/status,verbs=get;update;patch
 
-func (r *IamroleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *IamroleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	start := time.Now()
+	ctx = reconcileContext(ctx, req)
+	ctx, span := tracing.Start(ctx, "Reconcile")
+	// Registered first so the span ends with the error of a recovered panic
+	defer func() { tracing.End(span, err) }()
+	log := logging.Logger(ctx, "controllers", "iamrole_controller", "Reconcile")
+
+	//Get the resource
+	var iamRole iammanagerv1alpha1.Iamrole
+
+	// The branch is only known once the Iamrole is found
+	branch := ""
 	defer func() {
-		if err := recover(); err != nil {
-			fmt.Printf("panic occured %v", err)
+		if branch != "" {
+			observeReconcile(branch, &iamRole, err, start)
 		}
 	}()
 
-	ctx = context.WithValue(ctx, requestId, uuid.New())
-	log := logging.Logger(ctx, "controllers", "iamrole_controller", "Reconcile")
-	log.WithValues("iamrole", req.NamespacedName)
-	log.Info("Start of the request")
+	// A panic must not take the controller down. Report it and requeue the request
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			reconcilePanicsCounter.Inc()
+			log.Error(fmt.Errorf("%v", recovered), "recovered from panic", "stacktrace", string(debug.Stack()))
+			result, err = ctrl.Result{}, fmt.Errorf("recovered from panic: %v", recovered)
+		}
+	}()
 
-	//Get the resource
-	var iamRole iammanagerv1alpha1.Iamrole
+	log.Info("Start of the request")
 
 	if err := r.Get(ctx, req.NamespacedName, &iamRole); err != nil {
 		return ctrl.Result{}, ignoreNotFound(err)
 	}
+	branch = reconcileBranch(&iamRole)
+	ctx = auditContext(ctx, &iamRole)
 
 	// Is it being deleted?
 	if iamRole.ObjectMeta.DeletionTimestamp.IsZero() {
@@ -90,7 +125,9 @@ func (r *IamroleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		if !validation.ContainsString(iamRole.ObjectMeta.Finalizers, finalizerName) {
 			log.Info("New iamrole resource. Adding the finalizer", "finalizer", finalizerName)
 			iamRole.ObjectMeta.Finalizers = append(iamRole.ObjectMeta.Finalizers, finalizerName)
-			r.UpdateMeta(ctx, &iamRole)
+			if err := r.UpdateMeta(ctx, &iamRole); err != nil {
+				return ctrl.Result{}, ignoreNotFound(err)
+			}
 		}
 		return r.HandleReconcile(ctx, req, &iamRole)
 
@@ -104,11 +141,21 @@ func (r *IamroleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 		// If PolicyNotAllowed, we should not have any role created.
 		// If RoleNameNotAvailable, the role should be deleted.
+		deletionPolicy := iamRole.DeletionPolicy()
 		if iamRole.Status.State != iammanagerv1alpha1.PolicyNotAllowed && iamRole.Status.RoleName != "" {
 			//Get the roleName from status
 			roleName := iamRole.Status.RoleName
-			if err := r.IAMClient.DeleteRole(ctx, roleName); err != nil {
-				log.Error(err, "Unable to delete the role")
+			var err error
+			switch deletionPolicy {
+			case iammanagerv1alpha1.DeletionPolicyRetain:
+				err = r.IAMClient.RetainRole(ctx, roleName, false)
+			case iammanagerv1alpha1.DeletionPolicyRetainWithoutPolicies:
+				err = r.IAMClient.RetainRole(ctx, roleName, true)
+			default:
+				err = r.IAMClient.DeleteRole(ctx, roleName)
+			}
+			if err != nil {
+				log.Error(err, "Unable to delete the role", "deletionPolicy", deletionPolicy)
 				//i got to fix this
 				if _, statusErr := r.UpdateStatus(ctx, &iamRole, iammanagerv1alpha1.IamroleStatus{RoleName: roleName, RetryCount: iamRole.Status.RetryCount + 1, LastUpdatedTimestamp: metav1.Now(), ErrorDescription: err.Error(), State: iammanagerv1alpha1.Error}, defaultRequeueTime); statusErr != nil {
 					log.Error(statusErr, "failed to update status after delete role error")
@@ -119,10 +166,27 @@ func (r *IamroleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			}
 		}
 
+		// A deleted role can't be assumed anymore, so the IRSA service accounts must not point to it.
+		// A retained role still exists and the service accounts keep using it
+		if deletionPolicy == iammanagerv1alpha1.DeletionPolicyDelete {
+			if err := r.releaseServiceAccounts(ctx, &iamRole); err != nil {
+				log.Error(err, "Unable to clean up the service accounts")
+				r.Recorder.Event(&iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.Error), "unable to clean up the service accounts due to "+err.Error())
+				return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+			}
+		}
+
 		// Ok. Lets delete the finalizer so controller can delete the custom object
 		log.Info("Removing finalizer from Iamrole")
 		iamRole.ObjectMeta.Finalizers = validation.RemoveString(iamRole.ObjectMeta.Finalizers, finalizerName)
-		r.UpdateMeta(ctx, &iamRole)
+		if err := r.UpdateMeta(ctx, &iamRole); err != nil {
+			return ctrl.Result{}, ignoreNotFound(err)
+		}
+		if deletionPolicy != iammanagerv1alpha1.DeletionPolicyDelete && iamRole.Status.State != iammanagerv1alpha1.PolicyNotAllowed && iamRole.Status.RoleName != "" {
+			log.Info("Successfully retained iam role", "deletionPolicy", deletionPolicy)
+			r.Recorder.Event(&iamRole, v1.EventTypeNormal, "Retained", fmt.Sprintf("Retained iam role %s as per deletion policy %s", iamRole.Status.RoleName, deletionPolicy))
+			return successRequeueIt()
+		}
 		log.Info("Successfully deleted iam role")
 		r.Recorder.Event(&iamRole, v1.EventTypeNormal, "Deleted", "Successfully deleted iam role")
 	}
@@ -130,10 +194,32 @@ func (r *IamroleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	return successRequeueIt()
 }
 
+// reconcileContext returns the context of a reconcile of the Iamrole. Its logs and spans carry a new request id
+// and the namespace and name of the Iamrole
+func reconcileContext(ctx context.Context, req ctrl.Request) context.Context {
+	ctx = logging.WithRequestID(ctx, uuid.New())
+	ctx = logging.WithValues(ctx, "namespace", req.Namespace, "iamrole", req.Name)
+	return tracing.WithAttributes(ctx, tracing.NamespaceKey.String(req.Namespace), tracing.IamroleKey.String(req.Name))
+}
+
+// auditContext returns a context whose audit records carry the Iamrole and the user who last changed it.
+// Without the webhook anybody can write the last-modified-by annotation, so the user is left out
+func auditContext(ctx context.Context, iamRole *iammanagerv1alpha1.Iamrole) context.Context {
+	record := audit.Iamrole{
+		Namespace:  iamRole.Namespace,
+		Name:       iamRole.Name,
+		UID:        string(iamRole.UID),
+		Generation: iamRole.Generation,
+	}
+	if config.Props.IsWebHookEnabled() {
+		record.User = iamRole.Annotations[config.IamManagerLastModifiedByAnnotation]
+	}
+	return audit.WithIamrole(ctx, record)
+}
+
 // HandleReconcile function handles all the reconcile
 func (r *IamroleReconciler) HandleReconcile(ctx context.Context, req ctrl.Request, iamRole *iammanagerv1alpha1.Iamrole) (ctrl.Result, error) {
 	log := logging.Logger(ctx, "controllers", "iamrole_controller", "HandleReconcile")
-	log = log.WithValues("iam_role_cr", iamRole.Name)
 	log.Info("state of the custom resource ", "state", iamRole.Status.State)
 	ns := v1.Namespace{}
 	if iamRole.Status.RoleName == "" && iamRole.Spec.RoleName != "" {
@@ -163,10 +249,16 @@ func (r *IamroleReconciler) HandleReconcile(ctx context.Context, req ctrl.Reques
 			r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.Error), "Unable to construct iam role due to error "+err.Error())
 			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 		}
-		return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RoleName: status.RoleName, ErrorDescription: status.ErrorDescription, State: status.State, LastUpdatedTimestamp: metav1.Now()}, defaultRequeueTime)
+		// Trust policy errors are reported as Error, everything else failed the policy validation
+		condition := newCondition(iamRole, iammanagerv1alpha1.ConditionPolicyValid, metav1.ConditionFalse, string(status.State), status.ErrorDescription)
+		if status.State == iammanagerv1alpha1.Error {
+			condition = newCondition(iamRole, iammanagerv1alpha1.ConditionTrustPolicyApplied, metav1.ConditionFalse, "InvalidTrustPolicy", status.ErrorDescription)
+		}
+		return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RoleName: status.RoleName, ErrorDescription: status.ErrorDescription, State: status.State, LastUpdatedTimestamp: metav1.Now(), Conditions: []metav1.Condition{condition}}, defaultRequeueTime)
 	}
 
 	requeueTime := float64(defaultRequeueTime) // default requeue time would be 3000ms
+	conditions := []metav1.Condition{newCondition(iamRole, iammanagerv1alpha1.ConditionPolicyValid, metav1.ConditionTrue, "PolicyValid", "Policies passed validation")}
 
 	switch iamRole.Status.State {
 	case iammanagerv1alpha1.Ready:
@@ -180,39 +272,58 @@ func (r *IamroleReconciler) HandleReconcile(ctx context.Context, req ctrl.Reques
 			log.Error(err, "error in verifying the status of the iam role with state of the world")
 			log.Info("retry count error", "count", iamRole.Status.RetryCount)
 			r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.Error), "Unable to create/update iam role due to error "+err.Error())
-			return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: iamRole.Status.RetryCount + 1, RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.Error, LastUpdatedTimestamp: metav1.Now()}, requeueTime)
+			return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: iamRole.Status.RetryCount + 1, RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.Error, LastUpdatedTimestamp: metav1.Now(), Conditions: conditions}, requeueTime)
 
 		}
 
-		targetPolicy, err := r.IAMClient.GetRolePolicy(ctx, *input)
+		policyNames := append([]string{input.PolicyName}, slices.Sorted(maps.Keys(input.InlinePolicies))...)
+		targetPolicies, err := r.IAMClient.GetRolePolicies(ctx, *input, append(policyNames, input.PreviousInlinePolicies...))
 		if err != nil {
 			// THIS SHOULD NEVER HAPPEN
 			// Just requeue in case if it happens
 			log.Error(err, "error in verifying the status of the iam role with state of the world")
 			log.Info("retry count error", "count", iamRole.Status.RetryCount)
 			r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.Error), "Unable to create/update iam role due to error "+err.Error())
-			return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: iamRole.Status.RetryCount + 1, RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.Error}, requeueTime)
+			return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: iamRole.Status.RetryCount + 1, RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.Error, Conditions: conditions}, requeueTime)
 
 		}
 
-		// If IRSA is enabled, make sure the service account has the needed annotations
-		saConsistent := false
-		if saExists, saNames := utils.ParseIRSAAnnotation(ctx, iamRole); saExists {
-			for i := 0; i < len(saNames); i++ {
-				// Get the service account in kubernetes
-				// If it exists, check the annotations are correct
-				if saSpec := k8s.NewK8sManagerClient(r.Client).GetServiceAccount(ctx, iamRole.Namespace, saNames[i]); saSpec != nil {
-					saConsistent = validation.CompareRoleIRSA(ctx, saSpec, *config.Props)
-					if !saConsistent {
-						break
-					}
-				}
-			}
+		attachedPolicies, err := r.IAMClient.ListAttachedRolePolicies(ctx, roleName)
+		if err != nil {
+			log.Error(err, "error in verifying the attached managed policies with state of the world")
+			r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.Error), "Unable to create/update iam role due to error "+err.Error())
+			return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: iamRole.Status.RetryCount + 1, RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.Error, Conditions: conditions}, requeueTime)
+		}
+
+		// If IRSA is enabled, make sure the service account exists and has the needed annotations
+		serviceAccounts, saConsistent := r.checkServiceAccounts(ctx, iamRole, aws.StringValue(targetRole.Role.Arn))
+
+		var drifted []string
+		roleDrift := validation.RoleDrift(ctx, *input, targetRole, targetPolicies)
+		if len(roleDrift) > 0 {
+			drifted = append(drifted, "role")
+		}
+		if !validation.CompareManagedPolicies(ctx, *input, attachedPolicies) {
+			drifted = append(drifted, "managed policies")
+			roleDrift = append(roleDrift, validation.DriftManagedPolicies)
+		}
+		if !saConsistent {
+			drifted = append(drifted, "service accounts")
+			roleDrift = append(roleDrift, driftServiceAccounts)
 		}
-		if validation.CompareRole(ctx, *input, targetRole, *targetPolicy) && saConsistent {
+		observeDrift(roleDrift)
+		if len(drifted) == 0 {
 			log.Info("No change in the incoming policy compare to state of the world(external AWS IAM) policy")
-			return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: 0, RoleName: roleName, ErrorDescription: "", RoleID: aws.StringValue(targetRole.Role.RoleId), RoleARN: aws.StringValue(targetRole.Role.Arn), LastUpdatedTimestamp: iamRole.Status.LastUpdatedTimestamp, State: iammanagerv1alpha1.Ready}, requeueTime)
+			conditions = append(conditions,
+				newCondition(iamRole, iammanagerv1alpha1.ConditionTrustPolicyApplied, metav1.ConditionTrue, "TrustPolicyApplied", "Trust policy is applied"),
+				serviceAccountsCondition(iamRole, serviceAccounts),
+				newCondition(iamRole, iammanagerv1alpha1.ConditionDriftDetected, metav1.ConditionFalse, "NoDrift", "Iam role matches the spec"),
+			)
+			return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: 0, RoleName: roleName, ErrorDescription: "", RoleID: aws.StringValue(targetRole.Role.RoleId), RoleARN: aws.StringValue(targetRole.Role.Arn), LastUpdatedTimestamp: iamRole.Status.LastUpdatedTimestamp, State: iammanagerv1alpha1.Ready, ManagedPolicyArns: input.ManagedPolicies, InlinePolicyNames: inlinePolicyNames(input), TagKeys: customTagKeys(input), ServiceAccounts: serviceAccounts, Conditions: conditions}, requeueTime)
 		}
+		log.Info("Iam role differs from the spec", "drifted", drifted)
+		r.recordDrift(ctx, iamRole, validation.DescribeDrift(ctx, *input, targetRole, targetPolicies, attachedPolicies), roleDrift, serviceAccounts)
+		conditions = append(conditions, newCondition(iamRole, iammanagerv1alpha1.ConditionDriftDetected, metav1.ConditionTrue, "DriftDetected", "Drift detected in "+strings.Join(drifted, ", ")))
 		fallthrough
 
 	case iammanagerv1alpha1.Error:
@@ -266,50 +377,76 @@ func (r *IamroleReconciler) HandleReconcile(ctx context.Context, req ctrl.Reques
 				errMsg := "maximum number of additional (sandbox) roles reached. Only 1 additional role is allowed per namespace"
 				log.Error(errors.New(errMsg), errMsg)
 				r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.RolesMaxLimitReached), errMsg)
-				return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: errMsg, State: iammanagerv1alpha1.RolesMaxLimitReached, LastUpdatedTimestamp: metav1.Now()}, requeueTime)
+				return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: errMsg, State: iammanagerv1alpha1.RolesMaxLimitReached, LastUpdatedTimestamp: metav1.Now(), Conditions: conditions}, requeueTime)
 			}
 		} else {
 			if config.Props.MaxRolesAllowed() < nonAdditionalRoles {
 				errMsg := "maximum number of allowed roles reached. You must delete any existing role before proceeding further"
 				log.Error(errors.New(errMsg), errMsg)
 				r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.RolesMaxLimitReached), errMsg)
-				return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: errMsg, State: iammanagerv1alpha1.RolesMaxLimitReached, LastUpdatedTimestamp: metav1.Now()}, requeueTime)
+				return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: errMsg, State: iammanagerv1alpha1.RolesMaxLimitReached, LastUpdatedTimestamp: metav1.Now(), Conditions: conditions}, requeueTime)
 			}
 		}
 		fallthrough
 	default:
 
 		// Default behavior on new Iamrole resource state is to go off and create it
-		resp, err := r.IAMClient.EnsureRole(ctx, *input)
+		// A pre-existing role named by spec.AdoptRoleARN is described before it gets overwritten
+		adopting, overwrites, err := r.describeAdoption(ctx, iamRole, input)
+		var resp *awsapi.IAMRoleResponse
+		if err == nil {
+			resp, err = r.IAMClient.EnsureRole(ctx, *input)
+		}
 		if err != nil {
 			log.Error(err, "error in creating a role")
 			state := iammanagerv1alpha1.Error
 
-			// This check verifies whether or not the IAM Role somehow already exists, but is allocated to another namespace based on the tag applied to it.
-			if strings.Contains(err.Error(), awsapi.RoleExistsAlreadyForOtherNamespace) {
+			// This check verifies whether or not the IAM Role somehow already exists, but is allocated to another namespace based on the tag applied to it
+			// or was not created by iam-manager and must be adopted explicitly.
+			if strings.Contains(err.Error(), awsapi.RoleExistsAlreadyForOtherNamespace) || strings.Contains(err.Error(), awsapi.RoleNotManagedByIamManager) {
 				state = iammanagerv1alpha1.RoleNameNotAvailable
 
 				//Role itself is not created
 				roleName = ""
 			}
 			r.Recorder.Event(iamRole, v1.EventTypeWarning, string(state), "Unable to create/update iam role due to error "+err.Error())
-			return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: iamRole.Status.RetryCount + 1, RoleName: roleName, ErrorDescription: err.Error(), State: state, LastUpdatedTimestamp: metav1.Now()}, requeueTime)
+			// Some of the desired managed and inline policies might have been applied before the failure
+			managedPolicyArns := append(slices.Clone(input.PreviousManagedPolicies), input.ManagedPolicies...)
+			slices.Sort(managedPolicyArns)
+			policyNames := append(slices.Clone(input.PreviousInlinePolicies), inlinePolicyNames(input)...)
+			slices.Sort(policyNames)
+			tagKeys := append(slices.Clone(input.PreviousTags), customTagKeys(input)...)
+			slices.Sort(tagKeys)
+			return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: iamRole.Status.RetryCount + 1, RoleName: roleName, ErrorDescription: err.Error(), State: state, LastUpdatedTimestamp: metav1.Now(), ManagedPolicyArns: slices.Compact(managedPolicyArns), InlinePolicyNames: slices.Compact(policyNames), TagKeys: slices.Compact(tagKeys), Conditions: conditions}, requeueTime)
 		}
 
 		//OK. Successful!!
+		conditions = append(conditions, newCondition(iamRole, iammanagerv1alpha1.ConditionTrustPolicyApplied, metav1.ConditionTrue, "TrustPolicyApplied", "Trust policy is applied"))
 		// Is this IRSA role? If yes, Create/update Service Account with required annotation
-		if saFlag, saNames := utils.ParseIRSAAnnotation(ctx, iamRole); saFlag {
-			for i := 0; i < len(saNames); i++ {
-				if err := k8s.NewK8sManagerClient(r.Client).CreateOrUpdateServiceAccount(ctx, saNames[i], iamRole.Namespace, resp.RoleARN, config.Props.IsIRSARegionalEndpointDisabled()); err != nil {
-					log.Error(err, "error in updating service account for IRSA role")
-					r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.Error), "Unable to create/update service account for IRSA role due to error "+err.Error())
-					return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: iamRole.Status.RetryCount + 1, RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.Error, LastUpdatedTimestamp: metav1.Now()}, requeueTime)
-				}
-			}
+		serviceAccounts, err := r.syncServiceAccounts(ctx, iamRole, resp.RoleARN)
+		if err != nil {
+			log.Error(err, "error in updating service account for IRSA role")
+			r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.Error), "Unable to create/update service account for IRSA role due to error "+err.Error())
+			conditions = append(conditions, newCondition(iamRole, iammanagerv1alpha1.ConditionServiceAccountsSynced, metav1.ConditionFalse, string(iammanagerv1alpha1.Error), err.Error()))
+			return r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: iamRole.Status.RetryCount + 1, RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.Error, LastUpdatedTimestamp: metav1.Now(), ServiceAccounts: serviceAccounts, Conditions: conditions}, requeueTime)
+		}
+		conditions = append(conditions, serviceAccountsCondition(iamRole, serviceAccounts))
+		if meta.IsStatusConditionTrue(conditions, iammanagerv1alpha1.ConditionDriftDetected) {
+			conditions = append(conditions, newCondition(iamRole, iammanagerv1alpha1.ConditionDriftDetected, metav1.ConditionFalse, "DriftReverted", "Drift was reverted to match the spec"))
+		} else {
+			conditions = append(conditions, newCondition(iamRole, iammanagerv1alpha1.ConditionDriftDetected, metav1.ConditionFalse, "NoDrift", "Iam role matches the spec"))
 		}
 
+		if adopting {
+			message := fmt.Sprintf("Adopted iam role %s. Nothing was overwritten", resp.RoleARN)
+			if len(overwrites) > 0 {
+				message = fmt.Sprintf("Adopted iam role %s. Overwrote %s", resp.RoleARN, strings.Join(overwrites, ", "))
+			}
+			log.Info("Adopted pre-existing iam role", "roleARN", resp.RoleARN, "overwrites", overwrites)
+			r.Recorder.Event(iamRole, v1.EventTypeNormal, "Adopted", message)
+		}
 		r.Recorder.Event(iamRole, v1.EventTypeNormal, string(iammanagerv1alpha1.Ready), "Successfully created/updated iam role")
-		result, err := r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: 0, RoleName: roleName, ErrorDescription: "", RoleID: resp.RoleID, RoleARN: resp.RoleARN, LastUpdatedTimestamp: metav1.Now(), State: iammanagerv1alpha1.Ready}, requeueTime)
+		result, err := r.UpdateStatus(ctx, iamRole, iammanagerv1alpha1.IamroleStatus{RetryCount: 0, RoleName: roleName, ErrorDescription: "", RoleID: resp.RoleID, RoleARN: resp.RoleARN, LastUpdatedTimestamp: metav1.Now(), State: iammanagerv1alpha1.Ready, ManagedPolicyArns: input.ManagedPolicies, InlinePolicyNames: inlinePolicyNames(input), TagKeys: customTagKeys(input), ServiceAccounts: serviceAccounts, Conditions: conditions}, requeueTime)
 		if err != nil {
 			return result, err
 		}
@@ -322,20 +459,83 @@ func (r *IamroleReconciler) HandleReconcile(ctx context.Context, req ctrl.Reques
 // ConstructInput function constructs input for
 func (r *IamroleReconciler) ConstructCreateIAMRoleInput(ctx context.Context, iamRole *iammanagerv1alpha1.Iamrole, roleName string) (*awsapi.IAMRoleRequest, *iammanagerv1alpha1.IamroleStatus, error) {
 	log := logging.Logger(ctx, "controllers", "iamrole_controller", "ConstructInput")
-	log.WithValues("iamrole", iamRole.Name)
 	role, _ := json.Marshal(iamRole.Spec.PolicyDocument)
 
-	//Validate IAM Policy and Resource
-	if err := validation.ValidateIAMPolicyAction(ctx, iamRole.Spec.PolicyDocument); err != nil {
+	if err := validation.ValidateInlinePolicyNames(ctx, iamRole.Spec.InlinePolicies); err != nil {
 		r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.PolicyNotAllowed), "Unable to create/update iam role due to error "+err.Error())
 		return nil, &iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.PolicyNotAllowed}, err
 	}
 
-	if err := validation.ValidateIAMPolicyResource(ctx, iamRole.Spec.PolicyDocument); err != nil {
+	policyDocuments := []iammanagerv1alpha1.PolicyDocument{iamRole.Spec.PolicyDocument}
+	inlinePolicies := map[string]string{}
+	for _, inlinePolicy := range iamRole.Spec.InlinePolicies {
+		policyDocuments = append(policyDocuments, inlinePolicy.PolicyDocument)
+		policy, _ := json.Marshal(inlinePolicy.PolicyDocument)
+		inlinePolicies[inlinePolicy.Name] = string(policy)
+	}
+
+	for _, policyDocument := range policyDocuments {
+		//Validate IAM Policy and Resource
+		if err := validation.ValidateIAMPolicyAction(ctx, policyDocument); err != nil {
+			r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.PolicyNotAllowed), "Unable to create/update iam role due to error "+err.Error())
+			return nil, &iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.PolicyNotAllowed}, err
+		}
+
+		if err := validation.ValidateIAMPolicyResource(ctx, policyDocument); err != nil {
+			r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.PolicyNotAllowed), "Unable to create/update iam role due to error "+err.Error())
+			return nil, &iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.PolicyNotAllowed}, err
+		}
+
+		if err := validation.ValidateIAMPolicyCondition(ctx, policyDocument); err != nil {
+			r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.PolicyNotAllowed), "Unable to create/update iam role due to error "+err.Error())
+			return nil, &iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.PolicyNotAllowed}, err
+		}
+	}
+
+	if err := validation.ValidateAssumeRolePolicyCondition(ctx, iamRole.Spec.AssumeRolePolicyDocument); err != nil {
+		r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.PolicyNotAllowed), "Unable to create/update iam role due to error "+err.Error())
+		return nil, &iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.PolicyNotAllowed}, err
+	}
+
+	if err := validation.ValidateManagedPolicyArns(ctx, iamRole.Spec.ManagedPolicyArns); err != nil {
+		r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.PolicyNotAllowed), "Unable to create/update iam role due to error "+err.Error())
+		return nil, &iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.PolicyNotAllowed}, err
+	}
+
+	sessionDuration := iamRole.Spec.MaxSessionDuration
+	if sessionDuration == 0 {
+		sessionDuration = config.Props.MaxSessionDuration()
+	}
+	if err := validation.ValidateTags(ctx, iamRole.Spec.Tags); err != nil {
+		r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.PolicyNotAllowed), "Unable to create/update iam role due to error "+err.Error())
+		return nil, &iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.PolicyNotAllowed}, err
+	}
+
+	if err := validation.ValidateServiceAccounts(ctx, iamRole.Spec.ServiceAccounts); err != nil {
+		r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.PolicyNotAllowed), "Unable to create/update iam role due to error "+err.Error())
+		return nil, &iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.PolicyNotAllowed}, err
+	}
+
+	if err := validation.ValidateMaxSessionDuration(ctx, sessionDuration); err != nil {
+		r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.PolicyNotAllowed), "Unable to create/update iam role due to error "+err.Error())
+		return nil, &iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.PolicyNotAllowed}, err
+	}
+
+	if err := validation.ValidateRolePath(ctx, iamRole.Spec.Path); err != nil {
+		r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.PolicyNotAllowed), "Unable to create/update iam role due to error "+err.Error())
+		return nil, &iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.PolicyNotAllowed}, err
+	}
+
+	if err := validation.ValidateAdoptRoleARN(ctx, iamRole.Spec.AdoptRoleARN, roleName, iamRole.Spec.Path); err != nil {
 		r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.PolicyNotAllowed), "Unable to create/update iam role due to error "+err.Error())
 		return nil, &iammanagerv1alpha1.IamroleStatus{RoleName: roleName, ErrorDescription: err.Error(), State: iammanagerv1alpha1.PolicyNotAllowed}, err
 	}
 
+	description := iamRole.Spec.Description
+	if description == "" {
+		description = config.DefaultRoleDescription
+	}
+
 	trustPolicy, err := utils.GetTrustPolicy(ctx, iamRole)
 	if err != nil {
 		r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.Error), "Unable to create/update iam role due to error "+err.Error())
@@ -352,34 +552,40 @@ func (r *IamroleReconciler) ConstructCreateIAMRoleInput(ctx context.Context, iam
 		tags["Cluster"] = config.Props.ClusterName()
 	}
 
-	// Custom tags value should be a string of comma seperated key/value pairs
-	// example annotation: "iammanager.keikoproj.io/tags": "key1=value1;;key2=value2"
-	if ok, customTagsString := utils.ParseTagsAnnotation(ctx, iamRole); ok {
-		// Retrieve each key/value pair
-		keyValueList := strings.Split(customTagsString, ";;")
-		for _, entry := range keyValueList {
-			// Should get slice of [key,value]
-			keyValuePair := strings.Split(entry, "=")
-			// Make sure tag is formatted correctly as "key=value"
-			if len(keyValuePair) == 2 {
-				// Make sure default tags are not overwritten, for duplicate keys the first will be applied
-				if _, ok = tags[keyValuePair[0]]; !ok {
-					tags[keyValuePair[0]] = keyValuePair[1]
-				}
-			}
+	// Custom tags come from spec.Tags and the deprecated "iammanager.keikoproj.io/tags": "key1=value1;;key2=value2" annotation
+	customTags, invalidTags := iamRole.CustomTags()
+	if len(invalidTags) > 0 {
+		log.Info("ignoring invalid entries of the tags annotation", "entries", invalidTags)
+		r.Recorder.Event(iamRole, v1.EventTypeWarning, string(iammanagerv1alpha1.PolicyNotAllowed), fmt.Sprintf("Ignoring invalid entries %q of annotation %s", invalidTags, config.IamManagerTagsAnnotation))
+	}
+	for key, value := range customTags {
+		tags[key] = value
+	}
+
+	// Cluster wide managed policies are attached to every role, followed by the ones requested in the spec
+	managedPolicies := []string{}
+	for _, policy := range append(slices.Clone(config.Props.ManagedPolicies()), iamRole.Spec.ManagedPolicyArns...) {
+		if policy != "" && !slices.Contains(managedPolicies, policy) {
+			managedPolicies = append(managedPolicies, policy)
 		}
 	}
 
 	input := &awsapi.IAMRoleRequest{
 		Name:                            roleName,
 		PolicyName:                      config.InlinePolicyName,
-		Description:                     "#DO NOT DELETE#. Managed by iam-manager",
-		SessionDuration:                 43200,
+		Description:                     description,
+		SessionDuration:                 sessionDuration,
+		Path:                            iamRole.RolePath(),
 		TrustPolicy:                     trustPolicy,
 		PermissionPolicy:                string(role),
 		ManagedPermissionBoundaryPolicy: config.Props.ManagedPermissionBoundaryPolicy(),
-		ManagedPolicies:                 config.Props.ManagedPolicies(),
+		ManagedPolicies:                 managedPolicies,
 		Tags:                            tags,
+		PreviousManagedPolicies:         iamRole.Status.ManagedPolicyArns,
+		InlinePolicies:                  inlinePolicies,
+		PreviousInlinePolicies:          iamRole.Status.InlinePolicyNames,
+		PreviousTags:                    iamRole.Status.TagKeys,
+		AdoptRole:                       iamRole.Spec.AdoptRoleARN != "",
 	}
 
 	return input, nil, nil
@@ -391,7 +597,7 @@ type StatusUpdatePredicate struct {
 
 // Update implements default UpdateEvent filter for validating generation change
 func (StatusUpdatePredicate) Update(e event.UpdateEvent) bool {
-	log := logging.Logger(context.Background(), "controllers", "iamrole_controller", "HandleReconcile")
+	log := logging.Logger(context.Background(), "controllers", "iamrole_controller", "StatusUpdatePredicate")
 
 	if e.ObjectOld == nil {
 		log.Error(nil, "Update event has no old runtime object to update", "event", e)
@@ -405,20 +611,86 @@ func (StatusUpdatePredicate) Update(e event.UpdateEvent) bool {
 	oldObj := e.ObjectOld.(*iammanagerv1alpha1.Iamrole)
 	newObj := e.ObjectNew.(*iammanagerv1alpha1.Iamrole)
 
-	return oldObj.Status == newObj.Status
+	return equality.Semantic.DeepEqual(oldObj.Status, newObj.Status)
+}
+
+// ServiceAccountUpdatePredicate filters service account updates which do not touch the metadata managed by iam-manager
+type ServiceAccountUpdatePredicate struct {
+	predicate.Funcs
+}
+
+// Update implements UpdateEvent filter for changes of the IRSA annotations and the annotations/labels added by iam-manager
+func (ServiceAccountUpdatePredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+	oldAnnotations, newAnnotations := e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()
+	oldLabels, newLabels := e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()
+
+	annotations := append([]string{"eks.amazonaws.com/role-arn", config.IRSARegionalEndpointAnnotation, config.IamManagerServiceAccountOwnerAnnotation},
+		strings.Split(oldAnnotations[config.IamManagerManagedAnnotationsAnnotation], ",")...)
+	for _, key := range annotations {
+		if oldAnnotations[key] != newAnnotations[key] {
+			return true
+		}
+	}
+	for _, key := range strings.Split(oldAnnotations[config.IamManagerManagedLabelsAnnotation], ",") {
+		if oldLabels[key] != newLabels[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// IndexIamroleServiceAccounts returns the IRSA service account names of an Iamrole for the IamroleServiceAccountIndex
+func IndexIamroleServiceAccounts(obj client.Object) []string {
+	iamRole, ok := obj.(*iammanagerv1alpha1.Iamrole)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, sa := range iamRole.ServiceAccounts() {
+		names = append(names, sa.Name)
+	}
+	return names
+}
+
+// IamrolesForServiceAccount maps a service account to the Iamroles in its namespace which use it for IRSA
+func (r *IamroleReconciler) IamrolesForServiceAccount(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := logging.Logger(ctx, "controllers", "iamrole_controller", "IamrolesForServiceAccount")
+
+	var iamRoles iammanagerv1alpha1.IamroleList
+	if err := r.List(ctx, &iamRoles, client.InNamespace(obj.GetNamespace()), client.MatchingFields{IamroleServiceAccountIndex: obj.GetName()}); err != nil {
+		log.Error(err, "unable to list the iamroles of the service account", "serviceAccount", obj.GetName(), "namespace", obj.GetNamespace())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(iamRoles.Items))
+	for _, iamRole := range iamRoles.Items {
+		log.V(1).Info("service account changed. Enqueuing the iamrole", "serviceAccount", obj.GetName(), "iamrole", iamRole.Name)
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&iamRole)})
+	}
+	return requests
 }
 
 // SetupWithManager sets up manager with controller
 // GenerationChangedPredicate will take care of not allowing to trigger reconcile for every time status update happens
+// Changes to the IRSA service accounts enqueue the Iamroles using them, so tampered annotations are restored right away
 func (r *IamroleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	maxConcurrent := config.Props.MaxConcurrentReconciles()
 	if maxConcurrent < 1 {
 		maxConcurrent = config.DefaultMaxConcurrentReconciles
 	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &iammanagerv1alpha1.Iamrole{}, IamroleServiceAccountIndex, IndexIamroleServiceAccounts); err != nil {
+		return err
+	}
+	if err := registerIamroleStateCollector(mgr.GetClient()); err != nil {
+		return err
+	}
 	//Lets try to predicate based on Status retry count
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&iammanagerv1alpha1.Iamrole{}).
-		WithEventFilter(StatusUpdatePredicate{}).
+		For(&iammanagerv1alpha1.Iamrole{}, builder.WithPredicates(StatusUpdatePredicate{})).
+		// Only the metadata of the service accounts is cached, as the predicate and the mapping read nothing else
+		Watches(&v1.ServiceAccount{}, handler.EnqueueRequestsFromMapFunc(r.IamrolesForServiceAccount), builder.WithPredicates(ServiceAccountUpdatePredicate{}), builder.OnlyMetadata).
 		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrent}).
 		Complete(r)
 }
@@ -426,7 +698,6 @@ func (r *IamroleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 // UpdateStatus function updates the status based on the process step
 func (r *IamroleReconciler) UpdateStatus(ctx context.Context, iamRole *iammanagerv1alpha1.Iamrole, status iammanagerv1alpha1.IamroleStatus, requeueTime float64) (ctrl.Result, error) {
 	log := logging.Logger(ctx, "controllers", "iamrole_controller", "UpdateStatus")
-	log.WithValues("iamrole", fmt.Sprintf("k8s-%s", iamRole.ObjectMeta.Namespace))
 
 	// if wait time is not specified, requeue for defaultTime it after provided time
 	if requeueTime == 0 {
@@ -438,6 +709,38 @@ func (r *IamroleReconciler) UpdateStatus(ctx context.Context, iamRole *iammanage
 	if status.RoleID == "" {
 		status.RoleID = iamRole.Status.RoleID
 	}
+	if status.ManagedPolicyArns == nil {
+		status.ManagedPolicyArns = iamRole.Status.ManagedPolicyArns
+	}
+	if status.InlinePolicyNames == nil {
+		status.InlinePolicyNames = iamRole.Status.InlinePolicyNames
+	}
+	if status.TagKeys == nil {
+		status.TagKeys = iamRole.Status.TagKeys
+	}
+	if status.ServiceAccounts == nil {
+		status.ServiceAccounts = iamRole.Status.ServiceAccounts
+	}
+	if status.LastDrift == nil {
+		status.LastDrift = iamRole.Status.LastDrift
+	}
+
+	// Conditions are merged into the existing ones. Ready always mirrors the State
+	conditions := slices.Clone(iamRole.Status.Conditions)
+	for _, condition := range status.Conditions {
+		meta.SetStatusCondition(&conditions, condition)
+	}
+	if status.State == iammanagerv1alpha1.Ready {
+		meta.SetStatusCondition(&conditions, newCondition(iamRole, iammanagerv1alpha1.ConditionReady, metav1.ConditionTrue, string(iammanagerv1alpha1.Ready), "Iam role is ready"))
+	} else {
+		reason := string(status.State)
+		if reason == "" {
+			reason = "Unknown"
+		}
+		meta.SetStatusCondition(&conditions, newCondition(iamRole, iammanagerv1alpha1.ConditionReady, metav1.ConditionFalse, reason, status.ErrorDescription))
+	}
+	status.Conditions = conditions
+	status.ObservedGeneration = iamRole.Generation
 
 	if iamRole.Status.LastUpdatedTimestamp.IsZero() {
 		status.LastUpdatedTimestamp = metav1.Now()
@@ -458,15 +761,243 @@ func (r *IamroleReconciler) UpdateStatus(ctx context.Context, iamRole *iammanage
 	return ctrl.Result{RequeueAfter: time.Duration(requeueTime) * time.Millisecond}, nil
 }
 
-// UpdateMeta function updates the metadata (mostly finalizers in this case)
-func (r *IamroleReconciler) UpdateMeta(ctx context.Context, iamRole *iammanagerv1alpha1.Iamrole) {
+// UpdateMeta function updates the metadata (mostly finalizers in this case).
+// On a conflict the latest Iamrole is fetched and the finalizer change is applied to it again
+func (r *IamroleReconciler) UpdateMeta(ctx context.Context, iamRole *iammanagerv1alpha1.Iamrole) error {
 	log := logging.Logger(ctx, "controllers", "iamrole_controller", "UpdateMeta")
-	log = log.WithValues("iam_role_cr", iamRole.ObjectMeta.Name)
 
-	if err := r.Update(ctx, iamRole); err != nil {
+	wantFinalizer := validation.ContainsString(iamRole.ObjectMeta.Finalizers, finalizerName)
+	latest := iamRole.DeepCopy()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		updateErr := r.Update(ctx, latest)
+		if !apierrs.IsConflict(updateErr) {
+			return updateErr
+		}
+		log.Info("Conflict while updating object metadata (finalizer), retrying with the latest version")
+		if err := r.Get(ctx, client.ObjectKeyFromObject(iamRole), latest); err != nil {
+			return err
+		}
+		if wantFinalizer && !validation.ContainsString(latest.ObjectMeta.Finalizers, finalizerName) {
+			latest.ObjectMeta.Finalizers = append(latest.ObjectMeta.Finalizers, finalizerName)
+		} else if !wantFinalizer {
+			latest.ObjectMeta.Finalizers = validation.RemoveString(latest.ObjectMeta.Finalizers, finalizerName)
+		}
+		return updateErr
+	})
+	if err != nil {
 		log.Error(err, "Unable to update object metadata (finalizer)")
-		panic(err)
+		return err
+	}
+	latest.DeepCopyInto(iamRole)
+	return nil
+}
+
+// checkServiceAccounts verifies the IRSA service accounts against the spec.
+// Missing service accounts which must not be created are reported but do not make the role inconsistent
+func (r *IamroleReconciler) checkServiceAccounts(ctx context.Context, iamRole *iammanagerv1alpha1.Iamrole, roleARN string) ([]iammanagerv1alpha1.ServiceAccountStatus, bool) {
+	consistent := true
+	statuses := []iammanagerv1alpha1.ServiceAccountStatus{}
+	desiredServiceAccounts := iamRole.ServiceAccounts()
+	if len(unlinkedServiceAccounts(iamRole, desiredServiceAccounts)) > 0 {
+		consistent = false
+	}
+	for _, desired := range desiredServiceAccounts {
+		status := iammanagerv1alpha1.ServiceAccountStatus{Name: desired.Name, Created: serviceAccountCreated(iamRole, desired.Name)}
+		sa := k8s.NewK8sManagerClient(r.Client).GetServiceAccount(ctx, iamRole.Namespace, desired.Name)
+		status.Synced = sa != nil && validation.CompareServiceAccount(ctx, sa, roleARN, desired, *config.Props)
+		if !status.Synced && (sa != nil || desired.ShouldCreate()) {
+			consistent = false
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, consistent
+}
+
+// syncServiceAccounts creates or patches the IRSA service accounts with the role annotations and releases
+// the service accounts which are no longer linked to the role.
+// On error the statuses of the service accounts which were not processed are carried over
+func (r *IamroleReconciler) syncServiceAccounts(ctx context.Context, iamRole *iammanagerv1alpha1.Iamrole, roleARN string) ([]iammanagerv1alpha1.ServiceAccountStatus, error) {
+	log := logging.Logger(ctx, "controllers", "iamrole_controller", "syncServiceAccounts")
+
+	statuses := []iammanagerv1alpha1.ServiceAccountStatus{}
+	desired := iamRole.ServiceAccounts()
+	unlinked := unlinkedServiceAccounts(iamRole, desired)
+	for i, status := range unlinked {
+		if err := k8s.NewK8sManagerClient(r.Client).ReleaseServiceAccount(ctx, iamRole.Namespace, status.Name, iamRole.Name); err != nil {
+			statuses = append(statuses, unlinked[i:]...)
+			for _, sa := range desired {
+				if j := slices.IndexFunc(iamRole.Status.ServiceAccounts, func(status iammanagerv1alpha1.ServiceAccountStatus) bool { return status.Name == sa.Name }); j >= 0 {
+					statuses = append(statuses, iamRole.Status.ServiceAccounts[j])
+				}
+			}
+			return statuses, err
+		}
+		log.Info("released service account which is no longer linked to the role", "serviceAccount", status.Name)
+	}
+
+	for i, sa := range desired {
+		created, err := k8s.NewK8sManagerClient(r.Client).CreateOrUpdateServiceAccount(ctx, k8s.ServiceAccountRequest{
+			Name:                     sa.Name,
+			Namespace:                iamRole.Namespace,
+			Owner:                    iamRole.Name,
+			RoleARN:                  roleARN,
+			RegionalEndpointDisabled: config.Props.IsIRSARegionalEndpointDisabled(),
+			Annotations:              sa.Annotations,
+			Labels:                   sa.Labels,
+			CreateIfMissing:          sa.ShouldCreate(),
+		})
+		if errors.Is(err, k8s.ErrServiceAccountNotFound) {
+			log.Info("service account does not exist and is not created", "serviceAccount", sa.Name)
+			statuses = append(statuses, iammanagerv1alpha1.ServiceAccountStatus{Name: sa.Name, Created: serviceAccountCreated(iamRole, sa.Name)})
+			continue
+		}
+		if err != nil {
+			for _, remaining := range desired[i:] {
+				if j := slices.IndexFunc(iamRole.Status.ServiceAccounts, func(status iammanagerv1alpha1.ServiceAccountStatus) bool { return status.Name == remaining.Name }); j >= 0 {
+					statuses = append(statuses, iamRole.Status.ServiceAccounts[j])
+				}
+			}
+			return statuses, err
+		}
+		statuses = append(statuses, iammanagerv1alpha1.ServiceAccountStatus{Name: sa.Name, Created: created || serviceAccountCreated(iamRole, sa.Name), Synced: true})
+	}
+	return statuses, nil
+}
+
+// unlinkedServiceAccounts returns the statuses of the service accounts which are no longer linked to the role
+func unlinkedServiceAccounts(iamRole *iammanagerv1alpha1.Iamrole, desired []iammanagerv1alpha1.ServiceAccount) []iammanagerv1alpha1.ServiceAccountStatus {
+	var unlinked []iammanagerv1alpha1.ServiceAccountStatus
+	for _, status := range iamRole.Status.ServiceAccounts {
+		if !slices.ContainsFunc(desired, func(sa iammanagerv1alpha1.ServiceAccount) bool { return sa.Name == status.Name }) {
+			unlinked = append(unlinked, status)
+		}
+	}
+	return unlinked
+}
+
+// releaseServiceAccounts releases every service account of the role which is being deleted
+func (r *IamroleReconciler) releaseServiceAccounts(ctx context.Context, iamRole *iammanagerv1alpha1.Iamrole) error {
+	names := []string{}
+	for _, sa := range iamRole.ServiceAccounts() {
+		names = append(names, sa.Name)
+	}
+	for _, status := range iamRole.Status.ServiceAccounts {
+		if !slices.Contains(names, status.Name) {
+			names = append(names, status.Name)
+		}
+	}
+	for _, name := range names {
+		if err := k8s.NewK8sManagerClient(r.Client).ReleaseServiceAccount(ctx, iamRole.Namespace, name, iamRole.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serviceAccountCreated reports whether the service account was created by iam-manager according to the status
+func serviceAccountCreated(iamRole *iammanagerv1alpha1.Iamrole, name string) bool {
+	return slices.ContainsFunc(iamRole.Status.ServiceAccounts, func(status iammanagerv1alpha1.ServiceAccountStatus) bool {
+		return status.Name == name && status.Created
+	})
+}
+
+// serviceAccountsCondition returns the ServiceAccountsSynced condition for the service account statuses
+func serviceAccountsCondition(iamRole *iammanagerv1alpha1.Iamrole, statuses []iammanagerv1alpha1.ServiceAccountStatus) metav1.Condition {
+	var missing []string
+	for _, status := range statuses {
+		if !status.Synced {
+			missing = append(missing, status.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return newCondition(iamRole, iammanagerv1alpha1.ConditionServiceAccountsSynced, metav1.ConditionFalse, "ServiceAccountNotFound", "Service accounts are missing and not created: "+strings.Join(missing, ", "))
+	}
+	return newCondition(iamRole, iammanagerv1alpha1.ConditionServiceAccountsSynced, metav1.ConditionTrue, "ServiceAccountsSynced", "Service accounts are in sync")
+}
+
+// inlinePolicyNames returns the sorted names of the additional inline policies in the request
+func inlinePolicyNames(input *awsapi.IAMRoleRequest) []string {
+	return append([]string{}, slices.Sorted(maps.Keys(input.InlinePolicies))...)
+}
+
+// describeAdoption tells whether EnsureRole takes over the pre-existing role named by spec.AdoptRoleARN and describes
+// what it overwrites. A role which is in use by another Iamrole is never adopted
+func (r *IamroleReconciler) describeAdoption(ctx context.Context, iamRole *iammanagerv1alpha1.Iamrole, input *awsapi.IAMRoleRequest) (bool, []string, error) {
+	if !input.AdoptRole {
+		return false, nil, nil
+	}
+	targetRole, err := r.IAMClient.GetRole(ctx, *input)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == iam.ErrCodeNoSuchEntityException {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	if validation.IsRoleManaged(*input, targetRole.Role.Tags) {
+		return false, nil, nil
+	}
+
+	var iamRoles iammanagerv1alpha1.IamroleList
+	if err := r.List(ctx, &iamRoles); err != nil {
+		return false, nil, err
+	}
+	for _, other := range iamRoles.Items {
+		if other.UID != iamRole.UID && other.Status.RoleName == input.Name {
+			return false, nil, fmt.Errorf("role %s is used by Iamrole %s/%s. %s", input.Name, other.Namespace, other.Name, awsapi.RoleExistsAlreadyForOtherNamespace)
+		}
+	}
+
+	policyNames := append([]string{input.PolicyName}, slices.Sorted(maps.Keys(input.InlinePolicies))...)
+	targetPolicies, err := r.IAMClient.GetRolePolicies(ctx, *input, policyNames)
+	if err != nil {
+		return false, nil, err
+	}
+	return true, validation.AdoptionOverwrites(ctx, *input, targetRole, targetPolicies), nil
+}
+
+// recordDrift reports the drift found before it gets reverted in a DriftDetected event and in status.lastDrift,
+// which is kept by UpdateStatus until the next drift
+func (r *IamroleReconciler) recordDrift(ctx context.Context, iamRole *iammanagerv1alpha1.Iamrole, roleChanges []validation.DriftChange, components []string, serviceAccounts []iammanagerv1alpha1.ServiceAccountStatus) {
+	log := logging.Logger(ctx, "controllers", "iamrole_controller", "recordDrift")
+
+	var changes []string
+	for _, change := range roleChanges {
+		changes = append(changes, change.String())
+	}
+	for _, sa := range serviceAccounts {
+		if !sa.Synced {
+			changes = append(changes, fmt.Sprintf("service account %s: not in sync", sa.Name))
+		}
+	}
+	log.Info("Drift found", "changes", changes)
+	if len(changes) > maxDriftChanges {
+		changes = append(changes[:maxDriftChanges], fmt.Sprintf("and %d more", len(changes)-maxDriftChanges))
+	}
+
+	r.Recorder.Event(iamRole, v1.EventTypeWarning, iammanagerv1alpha1.ConditionDriftDetected, "Iam role differs from the spec: "+strings.Join(changes, "; "))
+	iamRole.Status.LastDrift = &iammanagerv1alpha1.DriftStatus{DetectedTimestamp: metav1.Now(), Components: components, Changes: changes}
+}
+
+// newCondition returns a condition observed for the current generation of the Iamrole
+func newCondition(iamRole *iammanagerv1alpha1.Iamrole, conditionType string, status metav1.ConditionStatus, reason string, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: iamRole.Generation,
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// customTagKeys returns the sorted keys of the tags in the request other than the reserved ones
+func customTagKeys(input *awsapi.IAMRoleRequest) []string {
+	keys := []string{}
+	for _, key := range slices.Sorted(maps.Keys(input.Tags)) {
+		if !slices.Contains(iammanagerv1alpha1.ReservedTagKeys, key) {
+			keys = append(keys, key)
+		}
 	}
+	return keys
 }
 
 /*
@@ -1,12 +1,37 @@
 package controllers_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	iammanagerv1alpha1 "github.com/keikoproj/iam-manager/api/v1alpha1"
+	"github.com/keikoproj/iam-manager/internal/config"
 	. "github.com/keikoproj/iam-manager/internal/controllers"
+	"github.com/keikoproj/iam-manager/pkg/audit"
+	"github.com/keikoproj/iam-manager/pkg/awsapi"
+	fakeiam "github.com/keikoproj/iam-manager/pkg/awsapi/fake"
 )
 
 var _ = Describe("IamroleController", func() {
@@ -57,4 +82,383 @@ var _ = Describe("IamroleController", func() {
 		})
 
 	})
+
+	Describe("When checking a ServiceAccountUpdatePredicate", func() {
+		instance := ServiceAccountUpdatePredicate{}
+		old := &metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "app",
+				Annotations: map[string]string{
+					"eks.amazonaws.com/role-arn":                  "arn:aws:iam::123456789012:role/k8s-default",
+					"iammanager.keikoproj.io/managed-annotations": "eks.amazonaws.com/role-arn,example.com/extra",
+					"iammanager.keikoproj.io/managed-labels":      "team",
+					"example.com/extra":                           "value",
+				},
+				Labels: map[string]string{"team": "orders"},
+			},
+		}
+
+		It("Should return true when the managed metadata changed", func() {
+			tampered := old.DeepCopy()
+			delete(tampered.Annotations, "eks.amazonaws.com/role-arn")
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: tampered})).To(BeTrue())
+
+			tampered = old.DeepCopy()
+			tampered.Annotations["eks.amazonaws.com/sts-regional-endpoints"] = "false"
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: tampered})).To(BeTrue())
+
+			tampered = old.DeepCopy()
+			tampered.Labels["team"] = "payments"
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: tampered})).To(BeTrue())
+		})
+
+		It("Should return false when other metadata changed", func() {
+			updated := old.DeepCopy()
+			updated.Annotations["unrelated"] = "value"
+			updated.Labels["unrelated"] = "value"
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})).To(BeFalse())
+		})
+	})
+
+	Describe("When mapping a service account to its Iamroles", func() {
+		It("Should enqueue the Iamroles using it in the namespace", func() {
+			testScheme := runtime.NewScheme()
+			Expect(iammanagerv1alpha1.AddToScheme(testScheme)).To(Succeed())
+			fakeClient := fake.NewClientBuilder().WithScheme(testScheme).
+				WithIndex(&iammanagerv1alpha1.Iamrole{}, IamroleServiceAccountIndex, IndexIamroleServiceAccounts).
+				WithObjects(
+					&iammanagerv1alpha1.Iamrole{ObjectMeta: metav1.ObjectMeta{Name: "annotation", Namespace: "default", Annotations: map[string]string{"iam.amazonaws.com/irsa-service-account": "app,worker"}}},
+					&iammanagerv1alpha1.Iamrole{ObjectMeta: metav1.ObjectMeta{Name: "spec", Namespace: "default"}, Spec: iammanagerv1alpha1.IamroleSpec{ServiceAccounts: []iammanagerv1alpha1.ServiceAccount{{Name: "app"}}}},
+					&iammanagerv1alpha1.Iamrole{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}},
+					&iammanagerv1alpha1.Iamrole{ObjectMeta: metav1.ObjectMeta{Name: "annotation", Namespace: "other", Annotations: map[string]string{"iam.amazonaws.com/irsa-service-account": "app"}}},
+				).Build()
+			reconciler := &IamroleReconciler{Client: fakeClient}
+
+			requests := reconciler.IamrolesForServiceAccount(context.Background(), &metav1.PartialObjectMetadata{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			})
+			Expect(requests).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "annotation", Namespace: "default"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "spec", Namespace: "default"}},
+			))
+		})
+	})
+
+	Describe("When updating the status", func() {
+		var reconciler *IamroleReconciler
+		var iamRole *iammanagerv1alpha1.Iamrole
+
+		BeforeEach(func() {
+			testScheme := runtime.NewScheme()
+			Expect(iammanagerv1alpha1.AddToScheme(testScheme)).To(Succeed())
+			iamRole = &iammanagerv1alpha1.Iamrole{
+				ObjectMeta: metav1.ObjectMeta{Name: "iamrole", Namespace: "default", Generation: 3},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(iamRole).WithStatusSubresource(iamRole).Build()
+			reconciler = &IamroleReconciler{Client: fakeClient, Recorder: record.NewFakeRecorder(10)}
+		})
+
+		It("Should mirror the state in the Ready condition and set observedGeneration", func() {
+			_, err := reconciler.UpdateStatus(context.Background(), iamRole, iammanagerv1alpha1.IamroleStatus{
+				State:            iammanagerv1alpha1.PolicyNotAllowed,
+				ErrorDescription: "restricted action",
+				Conditions: []metav1.Condition{
+					{Type: iammanagerv1alpha1.ConditionPolicyValid, Status: metav1.ConditionFalse, Reason: "PolicyNotAllowed", Message: "restricted action"},
+				},
+			}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(iamRole.Status.ObservedGeneration).To(Equal(int64(3)))
+			ready := meta.FindStatusCondition(iamRole.Status.Conditions, iammanagerv1alpha1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("PolicyNotAllowed"))
+			Expect(meta.IsStatusConditionFalse(iamRole.Status.Conditions, iammanagerv1alpha1.ConditionPolicyValid)).To(BeTrue())
+		})
+
+		It("Should keep the conditions which were not updated", func() {
+			_, err := reconciler.UpdateStatus(context.Background(), iamRole, iammanagerv1alpha1.IamroleStatus{
+				State: iammanagerv1alpha1.Ready,
+				Conditions: []metav1.Condition{
+					{Type: iammanagerv1alpha1.ConditionServiceAccountsSynced, Status: metav1.ConditionTrue, Reason: "ServiceAccountsSynced"},
+				},
+			}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = reconciler.UpdateStatus(context.Background(), iamRole, iammanagerv1alpha1.IamroleStatus{State: iammanagerv1alpha1.Ready}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.IsStatusConditionTrue(iamRole.Status.Conditions, iammanagerv1alpha1.ConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(iamRole.Status.Conditions, iammanagerv1alpha1.ConditionServiceAccountsSynced)).To(BeTrue())
+		})
+
+		It("Should keep the service account statuses when they were not checked", func() {
+			_, err := reconciler.UpdateStatus(context.Background(), iamRole, iammanagerv1alpha1.IamroleStatus{
+				State:           iammanagerv1alpha1.Ready,
+				ServiceAccounts: []iammanagerv1alpha1.ServiceAccountStatus{{Name: "app", Created: true, Synced: true}},
+			}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = reconciler.UpdateStatus(context.Background(), iamRole, iammanagerv1alpha1.IamroleStatus{State: iammanagerv1alpha1.Error}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(iamRole.Status.ServiceAccounts).To(Equal([]iammanagerv1alpha1.ServiceAccountStatus{{Name: "app", Created: true, Synced: true}}))
+
+			_, err = reconciler.UpdateStatus(context.Background(), iamRole, iammanagerv1alpha1.IamroleStatus{State: iammanagerv1alpha1.Ready, ServiceAccounts: []iammanagerv1alpha1.ServiceAccountStatus{}}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(iamRole.Status.ServiceAccounts).To(BeEmpty())
+		})
+	})
+
+	Describe("When reconciling against the fake IAM backend", func() {
+		var reconciler *IamroleReconciler
+		var fakeIAM *fakeiam.IAM
+		var k8sClient client.Client
+		var request reconcile.Request
+
+		// loadProperties loads the test config map with the given properties added
+		loadProperties := func(properties map[string]string) {
+			data := map[string]string{
+				"aws.accountId":                          "123456789012",
+				"aws.region":                             "us-west-2",
+				"k8s.cluster.name":                       "test",
+				"iam.policy.action.prefix.whitelist":     "s3:",
+				"iam.policy.resource.blacklist":          "kops",
+				"iam.policy.s3.restricted.resource":      "s3-resource",
+				"iam.role.max.limit.per.namespace":       "1",
+				"iam.managed.permission.boundary.policy": "k8s-boundary",
+				"iam.default.trust.policy":               `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow","Principal": {"AWS": ["arn:aws:iam::123456789012:role/trust_role"]},"Action": "sts:AssumeRole"}]}`,
+			}
+			maps.Copy(data, properties)
+			Expect(config.LoadProperties("", &corev1.ConfigMap{Data: data})).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			loadProperties(nil)
+
+			testScheme := runtime.NewScheme()
+			Expect(iammanagerv1alpha1.AddToScheme(testScheme)).To(Succeed())
+			Expect(corev1.AddToScheme(testScheme)).To(Succeed())
+			iamRole := &iammanagerv1alpha1.Iamrole{
+				ObjectMeta: metav1.ObjectMeta{Name: "iamrole", Namespace: "default"},
+				Spec: iammanagerv1alpha1.IamroleSpec{
+					PolicyDocument: iammanagerv1alpha1.PolicyDocument{
+						Statement: []iammanagerv1alpha1.Statement{{Effect: "Allow", Action: []string{"s3:Get*"}, Resource: []string{"arn:aws:s3:::bucket/*"}}},
+					},
+				},
+			}
+			k8sClient = fake.NewClientBuilder().WithScheme(testScheme).WithObjects(iamRole).WithStatusSubresource(iamRole).
+				WithIndex(&iammanagerv1alpha1.Iamrole{}, IamroleServiceAccountIndex, IndexIamroleServiceAccounts).Build()
+			fakeIAM = fakeiam.NewIAM("123456789012")
+			reconciler = &IamroleReconciler{Client: k8sClient, IAMClient: &awsapi.IAM{Client: fakeIAM}, Recorder: record.NewFakeRecorder(100)}
+			request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "iamrole", Namespace: "default"}}
+		})
+
+		AfterEach(func() {
+			Expect(config.LoadProperties("LOCAL")).To(Succeed())
+		})
+
+		It("Should create the role and delete it with the Iamrole", func() {
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			iamRole := &iammanagerv1alpha1.Iamrole{}
+			Expect(k8sClient.Get(context.Background(), request.NamespacedName, iamRole)).To(Succeed())
+			Expect(iamRole.Status.State).To(Equal(iammanagerv1alpha1.Ready))
+			role, ok := fakeIAM.Role("k8s-iamrole")
+			Expect(ok).To(BeTrue())
+			Expect(iamRole.Status.RoleARN).To(Equal(role.ARN))
+			Expect(role.Tags).To(HaveKeyWithValue("Namespace", "default"))
+			Expect(role.InlinePolicies).To(HaveKey(config.InlinePolicyName))
+
+			Expect(k8sClient.Delete(context.Background(), iamRole)).To(Succeed())
+			_, err = reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeIAM.Roles()).To(BeEmpty())
+		})
+
+		It("Should create the role at the path prefix when the Iamrole has no path", func() {
+			loadProperties(map[string]string{"iam.role.path.prefix": "/k8s/test/"})
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			iamRole := &iammanagerv1alpha1.Iamrole{}
+			Expect(k8sClient.Get(context.Background(), request.NamespacedName, iamRole)).To(Succeed())
+			Expect(iamRole.Status.State).To(Equal(iammanagerv1alpha1.Ready))
+			role, ok := fakeIAM.Role("k8s-iamrole")
+			Expect(ok).To(BeTrue())
+			Expect(role.Path).To(Equal("/k8s/test/"))
+			Expect(iamRole.Status.RoleARN).To(Equal("arn:aws:iam::123456789012:role/k8s/test/k8s-iamrole"))
+		})
+
+		It("Should keep the role and its service account with the Retain deletion policy", func() {
+			loadProperties(map[string]string{
+				"iam.irsa.enabled":            "true",
+				"k8s.cluster.oidc.issuer.url": "https://oidc.eks.us-west-2.amazonaws.com/id/EXAMPLE",
+			})
+			iamRole := &iammanagerv1alpha1.Iamrole{}
+			Expect(k8sClient.Get(context.Background(), request.NamespacedName, iamRole)).To(Succeed())
+			iamRole.Annotations = map[string]string{config.IRSAAnnotation: "app"}
+			iamRole.Spec.DeletionPolicy = iammanagerv1alpha1.DeletionPolicyRetain
+			Expect(k8sClient.Update(context.Background(), iamRole)).To(Succeed())
+
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(context.Background(), request.NamespacedName, iamRole)).To(Succeed())
+			Expect(iamRole.Status.State).To(Equal(iammanagerv1alpha1.Ready))
+			created := &corev1.ServiceAccount{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: "app", Namespace: "default"}, created)).To(Succeed())
+			Expect(created.Annotations).To(HaveKeyWithValue("eks.amazonaws.com/role-arn", iamRole.Status.RoleARN))
+
+			Expect(k8sClient.Delete(context.Background(), iamRole)).To(Succeed())
+			_, err = reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			_, ok := fakeIAM.Role("k8s-iamrole")
+			Expect(ok).To(BeTrue())
+			retained := &corev1.ServiceAccount{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: "app", Namespace: "default"}, retained)).To(Succeed())
+			Expect(retained.Annotations).To(Equal(created.Annotations))
+			Expect(retained.Labels).To(Equal(created.Labels))
+		})
+
+		It("Should audit the last modifier of the Iamrole only when the webhook is enabled", func() {
+			var records bytes.Buffer
+			audit.SetSink(audit.NewJSONLines(&records))
+			defer audit.SetSink(nil)
+			iamRole := &iammanagerv1alpha1.Iamrole{}
+			Expect(k8sClient.Get(context.Background(), request.NamespacedName, iamRole)).To(Succeed())
+			iamRole.Annotations = map[string]string{config.IamManagerLastModifiedByAnnotation: "alice"}
+			Expect(k8sClient.Update(context.Background(), iamRole)).To(Succeed())
+			users := func() []string {
+				var users []string
+				for _, line := range strings.Split(strings.TrimSpace(records.String()), "\n") {
+					var record struct {
+						Iamrole *audit.Iamrole `json:"iamrole"`
+					}
+					Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+					Expect(record.Iamrole).NotTo(BeNil())
+					users = append(users, record.Iamrole.User)
+				}
+				records.Reset()
+				return users
+			}
+
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users()).To(HaveEach(BeEmpty()))
+
+			loadProperties(map[string]string{"webhook.enabled": "true"})
+			Expect(k8sClient.Delete(context.Background(), iamRole)).To(Succeed())
+			_, err = reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users()).To(HaveEach(Equal("alice")))
+		})
+
+		It("Should report the IAM error in the status", func() {
+			fakeIAM.SetError("CreateRole", awserr.New(iam.ErrCodeLimitExceededException, "too many roles", nil))
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			iamRole := &iammanagerv1alpha1.Iamrole{}
+			Expect(k8sClient.Get(context.Background(), request.NamespacedName, iamRole)).To(Succeed())
+			Expect(iamRole.Status.State).To(Equal(iammanagerv1alpha1.Error))
+			Expect(iamRole.Status.ErrorDescription).To(ContainSubstring(iam.ErrCodeLimitExceededException))
+			Expect(fakeIAM.Roles()).To(BeEmpty())
+		})
+
+		It("Should record the reconcile and drift metrics", func() {
+			created := metricValue("iam_manager_reconcile_duration_seconds", map[string]string{"branch": "New", "result": "success"})
+			verified := metricValue("iam_manager_reconcile_duration_seconds", map[string]string{"branch": "Ready", "result": "success"})
+			drifted := metricValue("iam_manager_drift_detections_total", map[string]string{"component": "settings"})
+
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metricValue("iam_manager_reconcile_duration_seconds", map[string]string{"branch": "New", "result": "success"})).To(Equal(created + 1))
+
+			// The description is changed outside of iam-manager
+			role, ok := fakeIAM.Role("k8s-iamrole")
+			Expect(ok).To(BeTrue())
+			role.Description = "changed"
+			fakeIAM.PutRole(role)
+			_, err = reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metricValue("iam_manager_drift_detections_total", map[string]string{"component": "settings"})).To(Equal(drifted + 1))
+			Expect(metricValue("iam_manager_reconcile_duration_seconds", map[string]string{"branch": "Ready", "result": "success"})).To(Equal(verified + 1))
+		})
+
+		It("Should describe the drift in an event and in the status before reverting it", func() {
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			recorder := record.NewFakeRecorder(100)
+			reconciler.Recorder = recorder
+
+			// An action is added to the inline policy and the max session duration is changed outside of iam-manager
+			role, ok := fakeIAM.Role("k8s-iamrole")
+			Expect(ok).To(BeTrue())
+			role.InlinePolicies[config.InlinePolicyName] = strings.Replace(role.InlinePolicies[config.InlinePolicyName], `"s3:Get*"`, `"s3:Get*","s3:DeleteObject"`, 1)
+			sessionDuration := role.MaxSessionDuration
+			role.MaxSessionDuration = 7200
+			fakeIAM.PutRole(role)
+			_, err = reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			iamRole := &iammanagerv1alpha1.Iamrole{}
+			Expect(k8sClient.Get(context.Background(), request.NamespacedName, iamRole)).To(Succeed())
+			Expect(iamRole.Status.State).To(Equal(iammanagerv1alpha1.Ready))
+			Expect(iamRole.Status.LastDrift).NotTo(BeNil())
+			Expect(iamRole.Status.LastDrift.DetectedTimestamp.IsZero()).To(BeFalse())
+			Expect(iamRole.Status.LastDrift.Components).To(Equal([]string{"policy", "settings"}))
+			changes := []string{
+				fmt.Sprintf("inline policy %s statement 1 Action: added s3:DeleteObject", config.InlinePolicyName),
+				fmt.Sprintf("max session duration: changed from %d to 7200", sessionDuration),
+			}
+			Expect(iamRole.Status.LastDrift.Changes).To(Equal(changes))
+			Expect(recorder.Events).To(Receive(Equal("Warning DriftDetected Iam role differs from the spec: " + strings.Join(changes, "; "))))
+
+			// The drift was reverted and the last drift is kept
+			role, _ = fakeIAM.Role("k8s-iamrole")
+			Expect(role.InlinePolicies[config.InlinePolicyName]).NotTo(ContainSubstring("s3:DeleteObject"))
+			_, err = reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(context.Background(), request.NamespacedName, iamRole)).To(Succeed())
+			Expect(iamRole.Status.LastDrift).NotTo(BeNil())
+			Expect(iamRole.Status.LastDrift.Components).To(Equal([]string{"policy", "settings"}))
+		})
+
+		It("Should retry the finalizer update on conflict", func() {
+			conflicts := 0
+			reconciler.Client = interceptor.NewClient(k8sClient.(client.WithWatch), interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					if conflicts == 0 {
+						conflicts++
+						return apierrors.NewConflict(iammanagerv1alpha1.GroupVersion.WithResource("iamroles").GroupResource(), obj.GetName(), errors.New("the object has been modified"))
+					}
+					return c.Update(ctx, obj, opts...)
+				},
+			})
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conflicts).To(Equal(1))
+
+			iamRole := &iammanagerv1alpha1.Iamrole{}
+			Expect(k8sClient.Get(context.Background(), request.NamespacedName, iamRole)).To(Succeed())
+			Expect(iamRole.Finalizers).To(ContainElement("iamrole.finalizers.iammanager.keikoproj.io"))
+			Expect(iamRole.Status.State).To(Equal(iammanagerv1alpha1.Ready))
+		})
+
+		It("Should requeue when the finalizer can't be updated", func() {
+			reconciler.Client = interceptor.NewClient(k8sClient.(client.WithWatch), interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					return apierrors.NewServiceUnavailable("etcd is down")
+				},
+			})
+			_, err := reconciler.Reconcile(context.Background(), request)
+			Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+			Expect(fakeIAM.Roles()).To(BeEmpty())
+		})
+
+		It("Should recover from a panic and requeue", func() {
+			reconciler.IAMClient = nil
+			var err error
+			Expect(func() { _, err = reconciler.Reconcile(context.Background(), request) }).NotTo(Panic())
+			Expect(err).To(MatchError(ContainSubstring("recovered from panic")))
+		})
+	})
 })
@@ -61,19 +61,19 @@ func (r *IamroleReconciler) ReconcileAllReadyStateIamRoles(ctx context.Context)
 	}
 
 	for _, prefetchedIamRole := range iamRoles {
-		log.Info("Reconcile start", "iamRole", prefetchedIamRole.Name)
+		log.Info("Reconcile start", "iamrole", prefetchedIamRole.Name)
 		if iamrole, err = api.GetIamRole(context.Background(), r.Client, prefetchedIamRole.Name, prefetchedIamRole.Namespace); err != nil {
-			log.Error(err, "unable to get iamrole resource", "iamRole", prefetchedIamRole.Name, "namespace", prefetchedIamRole.Namespace)
+			log.Error(err, "unable to get iamrole resource", "iamrole", prefetchedIamRole.Name, "namespace", prefetchedIamRole.Namespace)
 			continue
 		}
 
 		if iamrole.Status.State != "Ready" {
-			log.Info("Reconcile skipped because its state is not ready", "iamRole", iamrole.Name, "state", iamrole.Status.State)
+			log.Info("Reconcile skipped because its state is not ready", "iamrole", iamrole.Name, "state", iamrole.Status.State)
 			continue
 		}
 
 		req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: iamrole.Namespace, Name: iamrole.Name}}
-		res, err = r.HandleReconcile(ctx, req, iamrole)
+		res, err = r.HandleReconcile(auditContext(reconcileContext(ctx, req), iamrole), req, iamrole)
 		log.Info("Reconcile result", "result", res, "error", err)
 
 		// sleep for 2 seconds for politeness
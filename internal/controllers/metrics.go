@@ -0,0 +1,146 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	iammanagerv1alpha1 "github.com/keikoproj/iam-manager/api/v1alpha1"
+	"github.com/keikoproj/iam-manager/pkg/logging"
+	"github.com/keikoproj/iam-manager/pkg/validation"
+)
+
+const (
+	// Drift component reported by the controller on top of the ones from pkg/validation
+	driftServiceAccounts = "service_accounts"
+
+	reconcileSuccess = "success"
+	reconcileFailure = "failure"
+)
+
+var (
+	reconcilePanicsCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "iam_manager_reconcile_panics_total",
+		Help: "Number of panics recovered while reconciling an Iamrole",
+	})
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "iam_manager_reconcile_duration_seconds",
+		Help:    "Duration of the Iamrole reconciles by branch (the state the Iamrole was in, New or Delete) and result (success or failure)",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"branch", "result"})
+	driftDetectionsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iam_manager_drift_detections_total",
+		Help: "Number of times an iam role was found to differ from its Iamrole, by component",
+	}, []string{"component"})
+	iamrolesDesc = prometheus.NewDesc(
+		"iam_manager_iamroles",
+		"Number of Iamroles by namespace and state",
+		[]string{"namespace", "state"}, nil,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(reconcilePanicsCounter, reconcileDuration, driftDetectionsCounter)
+}
+
+// reconcileBranch names the HandleReconcile branch taken for the Iamrole after the state it is in
+func reconcileBranch(iamRole *iammanagerv1alpha1.Iamrole) string {
+	if !iamRole.ObjectMeta.DeletionTimestamp.IsZero() {
+		return "Delete"
+	}
+	if iamRole.Status.State == "" {
+		return "New"
+	}
+	return string(iamRole.Status.State)
+}
+
+// observeReconcile records the duration of the reconcile. It succeeded if the Iamrole ended up Ready,
+// or could be released when it is being deleted
+func observeReconcile(branch string, iamRole *iammanagerv1alpha1.Iamrole, err error, start time.Time) {
+	result := reconcileFailure
+	switch {
+	case err != nil:
+	case !iamRole.ObjectMeta.DeletionTimestamp.IsZero():
+		if !validation.ContainsString(iamRole.ObjectMeta.Finalizers, finalizerName) {
+			result = reconcileSuccess
+		}
+	case iamRole.Status.State == iammanagerv1alpha1.Ready:
+		result = reconcileSuccess
+	}
+	reconcileDuration.WithLabelValues(branch, result).Observe(time.Since(start).Seconds())
+}
+
+// observeDrift counts the drifted components of an iam role
+func observeDrift(components []string) {
+	for _, component := range components {
+		driftDetectionsCounter.WithLabelValues(component).Inc()
+	}
+}
+
+// iamroleStateCollector reports the Iamroles by namespace and state from the manager cache at scrape time,
+// so Iamroles which are gone don't leave stale series behind
+type iamroleStateCollector struct {
+	client client.Reader
+}
+
+// NewIamroleStateCollector returns the collector of the iam_manager_iamroles metric
+func NewIamroleStateCollector(c client.Reader) prometheus.Collector {
+	return &iamroleStateCollector{client: c}
+}
+
+// registerIamroleStateCollector registers the collector once, even if the controller is set up more than once
+func registerIamroleStateCollector(c client.Reader) error {
+	err := metrics.Registry.Register(NewIamroleStateCollector(c))
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		return nil
+	}
+	return err
+}
+
+func (c *iamroleStateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- iamrolesDesc
+}
+
+func (c *iamroleStateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	log := logging.Logger(ctx, "controllers", "metrics", "Collect")
+
+	var iamRoles iammanagerv1alpha1.IamroleList
+	if err := c.client.List(ctx, &iamRoles); err != nil {
+		log.Error(err, "unable to list the iamroles")
+		ch <- prometheus.NewInvalidMetric(iamrolesDesc, err)
+		return
+	}
+	counts := map[[2]string]int{}
+	for _, iamRole := range iamRoles.Items {
+		state := string(iamRole.Status.State)
+		if state == "" {
+			state = "Unknown"
+		}
+		counts[[2]string{iamRole.Namespace, state}]++
+	}
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(iamrolesDesc, prometheus.GaugeValue, float64(count), key[0], key[1])
+	}
+}
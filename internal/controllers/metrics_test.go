@@ -18,18 +18,53 @@ package controllers_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	iammanagerv1alpha1 "github.com/keikoproj/iam-manager/api/v1alpha1"
+	. "github.com/keikoproj/iam-manager/internal/controllers"
 )
 
+// metricValue returns the value of the counter, or the sample count of the histogram, with the labels
+func metricValue(name string, labels map[string]string) float64 {
+	families, err := metrics.Registry.Gather()
+	Expect(err).NotTo(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, label := range metric.GetLabel() {
+				if value, ok := labels[label.GetName()]; ok && value == label.GetValue() {
+					matched++
+				}
+			}
+			if matched != len(labels) {
+				continue
+			}
+			if metric.GetHistogram() != nil {
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
 var _ = Describe("Metrics Server Configuration", func() {
 	var stopFunc context.CancelFunc
 	var ctx context.Context
@@ -89,3 +124,31 @@ var _ = Describe("Metrics Server Configuration", func() {
 		Expect(testManager).NotTo(BeNil(), "Manager should be created with secure metrics configuration")
 	})
 })
+
+var _ = Describe("Iamrole state metric", func() {
+	It("Should count the Iamroles by namespace and state", func() {
+		testScheme := runtime.NewScheme()
+		Expect(iammanagerv1alpha1.AddToScheme(testScheme)).To(Succeed())
+		newIamrole := func(name, ns string, state iammanagerv1alpha1.State) client.Object {
+			return &iammanagerv1alpha1.Iamrole{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+				Status:     iammanagerv1alpha1.IamroleStatus{State: state},
+			}
+		}
+		k8sClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+			newIamrole("a", "default", iammanagerv1alpha1.Ready),
+			newIamrole("b", "default", iammanagerv1alpha1.Ready),
+			newIamrole("c", "default", iammanagerv1alpha1.PolicyNotAllowed),
+			newIamrole("d", "other", ""),
+		).Build()
+
+		expected := `
+# HELP iam_manager_iamroles Number of Iamroles by namespace and state
+# TYPE iam_manager_iamroles gauge
+iam_manager_iamroles{namespace="default",state="PolicyNotAllowed"} 1
+iam_manager_iamroles{namespace="default",state="Ready"} 2
+iam_manager_iamroles{namespace="other",state="Unknown"} 1
+`
+		Expect(testutil.CollectAndCompare(NewIamroleStateCollector(k8sClient), strings.NewReader(expected), "iam_manager_iamroles")).To(Succeed())
+	})
+})
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	api "github.com/keikoproj/iam-manager/api/v1alpha1"
+	"github.com/keikoproj/iam-manager/internal/config"
+	"github.com/keikoproj/iam-manager/pkg/logging"
+)
+
+const (
+	// OrphanedRoleReason is the event reason used when an iam role of this cluster has no Iamrole
+	OrphanedRoleReason = "OrphanedRole"
+	// OrphanedRoleDeletedReason is the event reason used when the garbage collector deleted an orphaned iam role
+	OrphanedRoleDeletedReason = "OrphanedRoleDeleted"
+)
+
+var (
+	orphanedRolesGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "iam_manager_orphaned_roles",
+		Help: "Number of iam roles tagged for this cluster which have no Iamrole",
+	})
+	orphanedRolesDeletedCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "iam_manager_orphaned_roles_deleted_total",
+		Help: "Number of orphaned iam roles deleted by the garbage collector",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(orphanedRolesGauge, orphanedRolesDeletedCounter)
+}
+
+/**
+ * This will start a go routine that will collect the orphaned roles every "iam.role.gc.interval" seconds.
+ */
+func (r *IamroleReconciler) StartOrphanRoleGC(ctx context.Context) error {
+	log := logging.Logger(ctx, "controllers", "orphan_role_gc", "StartOrphanRoleGC")
+
+	// Without the Cluster tag the roles of other clusters in the same account can't be told apart
+	if config.Props.ClusterName() == "" {
+		log.Info("Orphaned role garbage collector is not started because the cluster name is not configured")
+		return nil
+	}
+
+	interval := config.Props.OrphanRoleGCInterval()
+	log.Info("Starting the orphaned role garbage collector", "interval", interval,
+		"gracePeriod", config.Props.OrphanRoleGCGracePeriod(), "dryRun", config.Props.IsOrphanRoleGCDryRun())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	firstSeen := map[string]time.Time{}
+	for {
+		select {
+		case <-ticker.C:
+			start := time.Now()
+			orphans := r.CollectOrphanRoles(ctx, firstSeen, start)
+			log.Info("Collected orphaned roles", "orphans", len(orphans), "duration", time.Since(start))
+		case <-ctx.Done():
+			log.Info("Application graceful shutdown", "time", time.Now())
+			return nil
+		}
+	}
+}
+
+// CollectOrphanRoles lists the iam roles tagged for this cluster and reports the ones no Iamrole refers to.
+// firstSeen records when each orphan was found first and is updated in place. Orphans older than the grace period
+// are deleted unless the garbage collector runs in dry run mode. It returns the names of the remaining orphans
+func (r *IamroleReconciler) CollectOrphanRoles(ctx context.Context, firstSeen map[string]time.Time, now time.Time) []string {
+	log := logging.Logger(ctx, "controllers", "orphan_role_gc", "CollectOrphanRoles")
+
+	iamRoles, err := api.ListIamRoles(ctx, r.Client)
+	if err != nil {
+		log.Error(err, "unable to list iamroles CR")
+		return nil
+	}
+	// Role names are unique within the account, so a role used by any Iamrole is never collected
+	inUse := map[string]string{}
+	for _, iamRole := range iamRoles {
+		if iamRole.Status.RoleName != "" {
+			inUse[iamRole.Status.RoleName] = iamRole.Namespace
+		}
+	}
+
+	// Roles created before iam.role.path.prefix was set stay at their path, so every path is listed and
+	// the Cluster tag tells the roles of this cluster apart
+	roles, err := r.IAMClient.ListRoles(ctx, "/")
+	if err != nil {
+		log.Error(err, "unable to list iam roles")
+		return nil
+	}
+
+	var orphans []string
+	seen := map[string]bool{}
+	for _, role := range roles {
+		roleName := aws.StringValue(role.RoleName)
+		if _, ok := inUse[roleName]; ok {
+			continue
+		}
+		tags, err := r.IAMClient.ListRoleTags(ctx, roleName)
+		if err != nil {
+			log.Error(err, "unable to list the tags of the role", "roleName", roleName)
+			// Keep the grace period running, the role is checked again on the next run
+			if _, ok := firstSeen[roleName]; ok {
+				seen[roleName] = true
+			}
+			continue
+		}
+		if tags["managedBy"] != "iam-manager" || tags["Cluster"] != config.Props.ClusterName() {
+			continue
+		}
+
+		seen[roleName] = true
+		namespace := tags["Namespace"]
+		since, ok := firstSeen[roleName]
+		if !ok {
+			firstSeen[roleName] = now
+			since = now
+			log.Info("Found orphaned role", "roleName", roleName, "namespace", namespace)
+			r.recordOrphanRoleEvent(v1.EventTypeWarning, OrphanedRoleReason,
+				fmt.Sprintf("IAM role %s tagged for namespace %s has no Iamrole", roleName, namespace))
+		}
+
+		if now.Sub(since) < config.Props.OrphanRoleGCGracePeriod() || config.Props.IsOrphanRoleGCDryRun() {
+			orphans = append(orphans, roleName)
+			continue
+		}
+		if err := r.IAMClient.DeleteRole(ctx, roleName); err != nil {
+			log.Error(err, "unable to delete orphaned role", "roleName", roleName, "namespace", namespace)
+			orphans = append(orphans, roleName)
+			continue
+		}
+		delete(firstSeen, roleName)
+		delete(seen, roleName)
+		orphanedRolesDeletedCounter.Inc()
+		log.Info("Deleted orphaned role", "roleName", roleName, "namespace", namespace, "orphanedSince", since)
+		r.recordOrphanRoleEvent(v1.EventTypeNormal, OrphanedRoleDeletedReason,
+			fmt.Sprintf("Deleted IAM role %s tagged for namespace %s which had no Iamrole since %s", roleName, namespace, since.Format(time.RFC3339)))
+	}
+
+	// Roles which got deleted or adopted in the meantime start over
+	for roleName := range firstSeen {
+		if !seen[roleName] {
+			delete(firstSeen, roleName)
+		}
+	}
+	orphanedRolesGauge.Set(float64(len(orphans)))
+	return orphans
+}
+
+// recordOrphanRoleEvent records the event on the iam-manager namespace as orphaned roles have no Iamrole left
+func (r *IamroleReconciler) recordOrphanRoleEvent(eventType string, reason string, message string) {
+	namespace := &v1.Namespace{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+		ObjectMeta: metav1.ObjectMeta{Name: config.IamManagerNamespaceName},
+	}
+	r.Recorder.Event(namespace, eventType, reason, message)
+}
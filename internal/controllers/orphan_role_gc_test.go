@@ -0,0 +1,157 @@
+package controllers_test
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	iammanagerv1alpha1 "github.com/keikoproj/iam-manager/api/v1alpha1"
+	"github.com/keikoproj/iam-manager/internal/config"
+	. "github.com/keikoproj/iam-manager/internal/controllers"
+	"github.com/keikoproj/iam-manager/pkg/awsapi"
+	fakeiam "github.com/keikoproj/iam-manager/pkg/awsapi/fake"
+)
+
+var _ = Describe("Orphaned role garbage collector", func() {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	clusterRole := func(name string, namespace string) fakeiam.Role {
+		return fakeiam.Role{Name: name, Tags: map[string]string{"managedBy": "iam-manager", "Namespace": namespace, "Cluster": "test"}}
+	}
+
+	type gcCase struct {
+		// properties are added to the test config map
+		properties    map[string]string
+		roles         []fakeiam.Role
+		iamRoles      []client.Object
+		firstSeen     map[string]time.Time
+		tagsErr       error
+		wantOrphans   []string
+		wantRoles     []string
+		wantFirstSeen map[string]time.Time
+		wantEvents    []string
+	}
+
+	AfterEach(func() {
+		Expect(config.LoadProperties("LOCAL")).To(Succeed())
+	})
+
+	DescribeTable("When collecting the orphaned roles",
+		func(tc gcCase) {
+			data := map[string]string{
+				"aws.accountId":            "123456789012",
+				"aws.region":               "us-west-2",
+				"k8s.cluster.name":         "test",
+				"iam.role.gc.enabled":      "true",
+				"iam.role.gc.grace.period": "3600",
+				"iam.role.gc.dry.run":      "false",
+				"iam.role.path.prefix":     "/k8s/test/",
+			}
+			maps.Copy(data, tc.properties)
+			Expect(config.LoadProperties("", &corev1.ConfigMap{Data: data})).To(Succeed())
+
+			testScheme := runtime.NewScheme()
+			Expect(iammanagerv1alpha1.AddToScheme(testScheme)).To(Succeed())
+			k8sClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(tc.iamRoles...).Build()
+			fakeIAM := fakeiam.NewIAM("123456789012")
+			for _, role := range tc.roles {
+				fakeIAM.PutRole(role)
+			}
+			fakeIAM.SetError("ListRoleTags", tc.tagsErr)
+			recorder := record.NewFakeRecorder(100)
+			reconciler := &IamroleReconciler{Client: k8sClient, IAMClient: &awsapi.IAM{Client: fakeIAM}, Recorder: recorder}
+
+			firstSeen := maps.Clone(tc.firstSeen)
+			if firstSeen == nil {
+				firstSeen = map[string]time.Time{}
+			}
+			Expect(reconciler.CollectOrphanRoles(context.Background(), firstSeen, now)).To(ConsistOf(tc.wantOrphans))
+
+			var roles []string
+			for _, role := range fakeIAM.Roles() {
+				roles = append(roles, role.Name)
+			}
+			Expect(roles).To(ConsistOf(tc.wantRoles))
+			if tc.wantFirstSeen == nil {
+				tc.wantFirstSeen = map[string]time.Time{}
+			}
+			Expect(firstSeen).To(Equal(tc.wantFirstSeen))
+			var reasons []string
+			for len(recorder.Events) > 0 {
+				reasons = append(reasons, strings.Fields(<-recorder.Events)[1])
+			}
+			Expect(reasons).To(ConsistOf(tc.wantEvents))
+		},
+		Entry("a new orphan is reported and kept for the grace period", gcCase{
+			roles:         []fakeiam.Role{clusterRole("k8s-orphan", "default")},
+			wantOrphans:   []string{"k8s-orphan"},
+			wantRoles:     []string{"k8s-orphan"},
+			wantFirstSeen: map[string]time.Time{"k8s-orphan": now},
+			wantEvents:    []string{OrphanedRoleReason},
+		}),
+		Entry("an orphan within the grace period is kept", gcCase{
+			roles:         []fakeiam.Role{clusterRole("k8s-orphan", "default")},
+			firstSeen:     map[string]time.Time{"k8s-orphan": now.Add(-59 * time.Minute)},
+			wantOrphans:   []string{"k8s-orphan"},
+			wantRoles:     []string{"k8s-orphan"},
+			wantFirstSeen: map[string]time.Time{"k8s-orphan": now.Add(-59 * time.Minute)},
+		}),
+		Entry("an orphan past the grace period is deleted", gcCase{
+			roles:      []fakeiam.Role{clusterRole("k8s-orphan", "default")},
+			firstSeen:  map[string]time.Time{"k8s-orphan": now.Add(-time.Hour)},
+			wantEvents: []string{OrphanedRoleDeletedReason},
+		}),
+		Entry("an orphan past the grace period is only reported in dry run mode", gcCase{
+			properties:    map[string]string{"iam.role.gc.dry.run": "true"},
+			roles:         []fakeiam.Role{clusterRole("k8s-orphan", "default")},
+			firstSeen:     map[string]time.Time{"k8s-orphan": now.Add(-2 * time.Hour)},
+			wantOrphans:   []string{"k8s-orphan"},
+			wantRoles:     []string{"k8s-orphan"},
+			wantFirstSeen: map[string]time.Time{"k8s-orphan": now.Add(-2 * time.Hour)},
+		}),
+		Entry("a role created before the path prefix was set is collected", gcCase{
+			roles:      []fakeiam.Role{{Name: "k8s-legacy", Path: "/", Tags: clusterRole("", "default").Tags}},
+			firstSeen:  map[string]time.Time{"k8s-legacy": now.Add(-time.Hour)},
+			wantEvents: []string{OrphanedRoleDeletedReason},
+		}),
+		Entry("roles which are not managed by iam-manager or belong to another cluster are ignored", gcCase{
+			roles: []fakeiam.Role{
+				{Name: "unmanaged", Tags: map[string]string{"Cluster": "test"}},
+				{Name: "k8s-other-cluster", Tags: map[string]string{"managedBy": "iam-manager", "Namespace": "default", "Cluster": "other"}},
+				{Name: "k8s-no-cluster", Tags: map[string]string{"managedBy": "iam-manager", "Namespace": "default"}},
+			},
+			firstSeen: map[string]time.Time{"k8s-other-cluster": now.Add(-2 * time.Hour)},
+			wantRoles: []string{"unmanaged", "k8s-other-cluster", "k8s-no-cluster"},
+		}),
+		Entry("a role used by an Iamrole is never collected, whatever its namespace tag", gcCase{
+			roles: []fakeiam.Role{clusterRole("k8s-used", "other")},
+			iamRoles: []client.Object{&iammanagerv1alpha1.Iamrole{
+				ObjectMeta: metav1.ObjectMeta{Name: "iamrole", Namespace: "default"},
+				Status:     iammanagerv1alpha1.IamroleStatus{RoleName: "k8s-used"},
+			}},
+			firstSeen: map[string]time.Time{"k8s-used": now.Add(-2 * time.Hour)},
+			wantRoles: []string{"k8s-used"},
+		}),
+		Entry("the grace period keeps running when the tags can't be listed", gcCase{
+			roles:         []fakeiam.Role{clusterRole("k8s-orphan", "default"), clusterRole("k8s-new", "default")},
+			firstSeen:     map[string]time.Time{"k8s-orphan": now.Add(-2 * time.Hour)},
+			tagsErr:       awserr.New(iam.ErrCodeServiceFailureException, "unavailable", nil),
+			wantRoles:     []string{"k8s-orphan", "k8s-new"},
+			wantFirstSeen: map[string]time.Time{"k8s-orphan": now.Add(-2 * time.Hour)},
+		}),
+		Entry("roles which are gone are forgotten", gcCase{
+			firstSeen: map[string]time.Time{"k8s-deleted": now.Add(-time.Minute)},
+		}),
+	)
+})
@@ -37,9 +37,10 @@ func GetTrustPolicy(ctx context.Context, role *iammanagerv1alpha1.Iamrole) (stri
 
 	// Is it IRSA use case
 	// Construct AssumeRoleWithWebIdentity
-	if flag, saNames := ParseIRSAAnnotation(ctx, role); flag {
-		for i := 0; i < len(saNames); i++ {
-			saName := saNames[i]
+	// Service accounts come from spec.ServiceAccounts and the iam.amazonaws.com/irsa-service-account annotation
+	if serviceAccounts := role.ServiceAccounts(); len(serviceAccounts) > 0 {
+		for i := 0; i < len(serviceAccounts); i++ {
+			saName := serviceAccounts[i].Name
 			hostPath := fmt.Sprintf("%s", strings.TrimPrefix(config.Props.OIDCIssuerUrl(), "https://"))
 			statement := iammanagerv1alpha1.TrustPolicyStatement{
 				Effect: "Allow",
@@ -47,9 +48,9 @@ func GetTrustPolicy(ctx context.Context, role *iammanagerv1alpha1.Iamrole) (stri
 				Principal: iammanagerv1alpha1.Principal{
 					Federated: fmt.Sprintf("arn:aws:iam::%s:oidc-provider/%s", config.Props.AWSAccountID(), hostPath),
 				},
-				Condition: &iammanagerv1alpha1.Condition{
-					StringEquals: map[string]string{
-						fmt.Sprintf("%s:sub", hostPath): fmt.Sprintf("system:serviceaccount:%s:%s", role.ObjectMeta.Namespace, saName),
+				Condition: iammanagerv1alpha1.PolicyCondition{
+					"StringEquals": {
+						fmt.Sprintf("%s:sub", hostPath): {fmt.Sprintf("system:serviceaccount:%s:%s", role.ObjectMeta.Namespace, saName)},
 					},
 				},
 			}
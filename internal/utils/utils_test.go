@@ -121,9 +121,9 @@ func (s *UtilsTestSuite) TestDefaultTrustPolicyMultipleNoGoTemplate(c *check.C)
 				Principal: v1alpha1.Principal{
 					Federated: "arn:aws:iam::AWS_ACCOUNT_ID:oidc-provider/OIDC_PROVIDER",
 				},
-				Condition: &v1alpha1.Condition{
-					StringEquals: map[string]string{
-						"OIDC_PROVIDER:sub": "system:serviceaccount:SERVICE_ACCOUNT_NAMESPACE:SERVICE_ACCOUNT_NAME",
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"OIDC_PROVIDER:sub": {"system:serviceaccount:SERVICE_ACCOUNT_NAMESPACE:SERVICE_ACCOUNT_NAME"},
 					},
 				},
 			},
@@ -134,7 +134,7 @@ func (s *UtilsTestSuite) TestDefaultTrustPolicyMultipleNoGoTemplate(c *check.C)
 	c.Assert(resp, check.NotNil)
 	c.Assert(resp.Statement[0], check.DeepEquals, expect.Statement[0])
 	c.Assert(len(resp.Statement), check.Equals, len(expect.Statement))
-	c.Assert(*resp.Statement[1].Condition, check.DeepEquals, *expect.Statement[1].Condition)
+	c.Assert(resp.Statement[1].Condition, check.DeepEquals, expect.Statement[1].Condition)
 }
 
 func (s *UtilsTestSuite) TestDefaultTrustPolicyMultipleWithGoTemplate(c *check.C) {
@@ -155,9 +155,9 @@ func (s *UtilsTestSuite) TestDefaultTrustPolicyMultipleWithGoTemplate(c *check.C
 				Principal: v1alpha1.Principal{
 					Federated: "arn:aws:iam::AWS_ACCOUNT_ID:oidc-provider/OIDC_PROVIDER",
 				},
-				Condition: &v1alpha1.Condition{
-					StringEquals: map[string]string{
-						"OIDC_PROVIDER:sub": "system:serviceaccount:valid_namespace:SERVICE_ACCOUNT_NAME",
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"OIDC_PROVIDER:sub": {"system:serviceaccount:valid_namespace:SERVICE_ACCOUNT_NAME"},
 					},
 				},
 			},
@@ -180,9 +180,9 @@ func (s *UtilsTestSuite) TestGetTrustPolicyDefaultRoleWithMultiple(c *check.C) {
 				Principal: v1alpha1.Principal{
 					Federated: "arn:aws:iam::AWS_ACCOUNT_ID:oidc-provider/OIDC_PROVIDER",
 				},
-				Condition: &v1alpha1.Condition{
-					StringEquals: map[string]string{
-						"OIDC_PROVIDER:sub": "system:serviceaccount:valid_namespace:SERVICE_ACCOUNT_NAME",
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"OIDC_PROVIDER:sub": {"system:serviceaccount:valid_namespace:SERVICE_ACCOUNT_NAME"},
 					},
 				},
 			},
@@ -228,9 +228,9 @@ func (s *UtilsTestSuite) TestGetTrustPolicyDefaultRoleWithMultipleAndStringLikeW
 				Principal: v1alpha1.Principal{
 					Federated: "arn:aws:iam::AWS_ACCOUNT_ID:oidc-provider/OIDC_PROVIDER",
 				},
-				Condition: &v1alpha1.Condition{
-					StringLike: map[string]string{
-						"OIDC_PROVIDER:sub": "system:serviceaccount:SERVICE_ACCOUNT_NAMESPACE:*",
+				Condition: v1alpha1.PolicyCondition{
+					"StringLike": {
+						"OIDC_PROVIDER:sub": {"system:serviceaccount:SERVICE_ACCOUNT_NAMESPACE:*"},
 					},
 				},
 			},
@@ -262,9 +262,9 @@ func (s *UtilsTestSuite) TestGetTrustPolicyDefaultRoleWithMultipleAndStringLikeW
 				Principal: v1alpha1.Principal{
 					Federated: "arn:aws:iam::AWS_ACCOUNT_ID:oidc-provider/OIDC_PROVIDER",
 				},
-				Condition: &v1alpha1.Condition{
-					StringLike: map[string]string{
-						"OIDC_PROVIDER:sub": "system:serviceaccount:valid_namespace:*",
+				Condition: v1alpha1.PolicyCondition{
+					"StringLike": {
+						"OIDC_PROVIDER:sub": {"system:serviceaccount:valid_namespace:*"},
 					},
 				},
 			},
@@ -442,9 +442,9 @@ func (s *UtilsTestSuite) TestGetTrustPolicyWithIRSAAnnotation(c *check.C) {
 				Principal: v1alpha1.Principal{
 					Federated: "arn:aws:iam::AWS_ACCOUNT_ID:oidc-provider/OIDC_PROVIDER",
 				},
-				Condition: &v1alpha1.Condition{
-					StringEquals: map[string]string{
-						"OIDC_PROVIDER:sub": "system:serviceaccount:k8s-namespace-dev:SERVICE_ACCOUNT_NAME",
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"OIDC_PROVIDER:sub": {"system:serviceaccount:k8s-namespace-dev:SERVICE_ACCOUNT_NAME"},
 					},
 				},
 			},
@@ -461,9 +461,9 @@ func (s *UtilsTestSuite) TestGetTrustPolicyWithIRSAAnnotation(c *check.C) {
 				Principal: v1alpha1.Principal{
 					Federated: "arn:aws:iam::123456789012:oidc-provider/google.com/OIDC",
 				},
-				Condition: &v1alpha1.Condition{
-					StringEquals: map[string]string{
-						"google.com/OIDC:sub": "system:serviceaccount:k8s-namespace-dev:default",
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"google.com/OIDC:sub": {"system:serviceaccount:k8s-namespace-dev:default"},
 					},
 				},
 			},
@@ -497,9 +497,9 @@ func (s *UtilsTestSuite) TestGetTrustPolicyWithMultipleIRSAAnnotations(c *check.
 				Principal: v1alpha1.Principal{
 					Federated: "arn:aws:iam::AWS_ACCOUNT_ID:oidc-provider/OIDC_PROVIDER",
 				},
-				Condition: &v1alpha1.Condition{
-					StringEquals: map[string]string{
-						"OIDC_PROVIDER:sub": "system:serviceaccount:k8s-namespace-dev:SERVICE_ACCOUNT_NAME",
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"OIDC_PROVIDER:sub": {"system:serviceaccount:k8s-namespace-dev:SERVICE_ACCOUNT_NAME"},
 					},
 				},
 			},
@@ -516,9 +516,9 @@ func (s *UtilsTestSuite) TestGetTrustPolicyWithMultipleIRSAAnnotations(c *check.
 				Principal: v1alpha1.Principal{
 					Federated: "arn:aws:iam::123456789012:oidc-provider/google.com/OIDC",
 				},
-				Condition: &v1alpha1.Condition{
-					StringEquals: map[string]string{
-						"google.com/OIDC:sub": "system:serviceaccount:k8s-namespace-dev:default",
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"google.com/OIDC:sub": {"system:serviceaccount:k8s-namespace-dev:default"},
 					},
 				},
 			},
@@ -528,9 +528,9 @@ func (s *UtilsTestSuite) TestGetTrustPolicyWithMultipleIRSAAnnotations(c *check.
 				Principal: v1alpha1.Principal{
 					Federated: "arn:aws:iam::123456789012:oidc-provider/google.com/OIDC",
 				},
-				Condition: &v1alpha1.Condition{
-					StringEquals: map[string]string{
-						"google.com/OIDC:sub": "system:serviceaccount:k8s-namespace-dev:my-sa-1",
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"google.com/OIDC:sub": {"system:serviceaccount:k8s-namespace-dev:my-sa-1"},
 					},
 				},
 			},
@@ -564,9 +564,9 @@ func (s *UtilsTestSuite) TestGetTrustPolicyWithIRSAAnnotationAndServiceRoleInReq
 				Principal: v1alpha1.Principal{
 					Federated: "arn:aws:iam::123456789012:oidc-provider/google.com/OIDC",
 				},
-				Condition: &v1alpha1.Condition{
-					StringEquals: map[string]string{
-						"google.com/OIDC:sub": "system:serviceaccount:k8s-namespace-dev:default",
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"google.com/OIDC:sub": {"system:serviceaccount:k8s-namespace-dev:default"},
 					},
 				},
 			},
@@ -873,9 +873,9 @@ func (s *UtilsTestSuite) TestAppendOrReplaceTrustPolicyStatement(c *check.C) {
 			Principal: v1alpha1.Principal{
 				Federated: "arn:aws:iam::123456789012:oidc-provider/google.com/OIDC",
 			},
-			Condition: &v1alpha1.Condition{
-				StringEquals: map[string]string{
-					"google.com/OIDC:sub": "system:serviceaccount:k8s-namespace-dev:default",
+			Condition: v1alpha1.PolicyCondition{
+				"StringEquals": {
+					"google.com/OIDC:sub": {"system:serviceaccount:k8s-namespace-dev:default"},
 				},
 			},
 		},
@@ -912,9 +912,9 @@ func (s *UtilsTestSuite) TestAppendOrReplaceTrustPolicyStatement(c *check.C) {
 		Principal: v1alpha1.Principal{
 			Federated: "arn:aws:iam::123456789012:oidc-provider/google.com/OIDC",
 		},
-		Condition: &v1alpha1.Condition{
-			StringEquals: map[string]string{
-				"google.com/OIDC:sub": "system:serviceaccount:k8s-namespace-dev:my-sa",
+		Condition: v1alpha1.PolicyCondition{
+			"StringEquals": {
+				"google.com/OIDC:sub": {"system:serviceaccount:k8s-namespace-dev:my-sa"},
 			},
 		},
 	}
@@ -928,9 +928,9 @@ func (s *UtilsTestSuite) TestAppendOrReplaceTrustPolicyStatement(c *check.C) {
 			Principal: v1alpha1.Principal{
 				Federated: "arn:aws:iam::123456789012:oidc-provider/google.com/OIDC",
 			},
-			Condition: &v1alpha1.Condition{
-				StringEquals: map[string]string{
-					"google.com/OIDC:sub": "system:serviceaccount:k8s-namespace-dev:default",
+			Condition: v1alpha1.PolicyCondition{
+				"StringEquals": {
+					"google.com/OIDC:sub": {"system:serviceaccount:k8s-namespace-dev:default"},
 				},
 			},
 		},
@@ -961,9 +961,9 @@ func (s *UtilsTestSuite) TestAppendOrReplaceTrustPolicyStatement(c *check.C) {
 			Principal: v1alpha1.Principal{
 				Federated: "arn:aws:iam::123456789012:oidc-provider/google.com/OIDC",
 			},
-			Condition: &v1alpha1.Condition{
-				StringEquals: map[string]string{
-					"google.com/OIDC:sub": "system:serviceaccount:k8s-namespace-dev:my-sa",
+			Condition: v1alpha1.PolicyCondition{
+				"StringEquals": {
+					"google.com/OIDC:sub": {"system:serviceaccount:k8s-namespace-dev:my-sa"},
 				},
 			},
 		},
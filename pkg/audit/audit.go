@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/keikoproj/iam-manager/pkg/logging"
+)
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+
+	// Stdout is the --audit-log value which writes the records to the standard output
+	Stdout = "-"
+)
+
+var (
+	mu   sync.RWMutex
+	sink Sink
+
+	writeFailuresCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "iam_manager_audit_write_failures_total",
+		Help: "Number of audit records which could not be written",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(writeFailuresCounter)
+}
+
+// Record is the audit record of a mutating IAM call
+type Record struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestId,omitempty"`
+	// Operation is the IAM API called, e.g. PutRolePolicy
+	Operation string `json:"operation"`
+	RoleName  string `json:"roleName,omitempty"`
+	// Policy is the name of the inline policy or the arn of the managed policy
+	Policy string `json:"policy,omitempty"`
+	// Keys are the tag keys removed by UntagRole
+	Keys    []string `json:"keys,omitempty"`
+	Iamrole *Iamrole `json:"iamrole,omitempty"`
+	// Before is left out when there was nothing before the call or it could not be read
+	Before Document `json:"before,omitempty"`
+	After  Document `json:"after,omitempty"`
+	Result string   `json:"result"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Iamrole identifies the Iamrole a call is made for
+type Iamrole struct {
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	Generation int64  `json:"generation"`
+	// User is the Kubernetes user who made the last change to the Iamrole, as captured by the admission webhook.
+	// It is empty when the webhook is disabled
+	User string `json:"user,omitempty"`
+}
+
+// Document is a policy document or any other JSON value. It is written as JSON when it is valid, as a string otherwise
+type Document string
+
+func (d Document) MarshalJSON() ([]byte, error) {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, []byte(d)); err == nil {
+		return compacted.Bytes(), nil
+	}
+	return json.Marshal(string(d))
+}
+
+// JSON returns the document of the value, e.g. the tags of a role
+func JSON(v interface{}) Document {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return Document(b)
+}
+
+// Sink receives the audit records. It must be safe for concurrent use
+type Sink interface {
+	Write(ctx context.Context, record Record) error
+}
+
+// JSONLines writes the records as JSON lines
+type JSONLines struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLines returns a sink which writes the records as JSON lines to the writer
+func NewJSONLines(w io.Writer) *JSONLines {
+	return &JSONLines{w: w}
+}
+
+func (s *JSONLines) Write(ctx context.Context, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// Open returns the JSON lines sink of the --audit-log flag: the standard output for "-", or the file which is
+// opened in append only mode. The returned function closes the file
+func Open(path string) (Sink, func() error, error) {
+	if path == Stdout {
+		return NewJSONLines(os.Stdout), func() error { return nil }, nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewJSONLines(file), file.Close, nil
+}
+
+// SetSink sets the sink of the audit records. A nil sink disables the audit log
+func SetSink(s Sink) {
+	mu.Lock()
+	defer mu.Unlock()
+	sink = s
+}
+
+// Enabled tells if the audit records are written, so the documents before a call are only read when needed
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return sink != nil
+}
+
+type iamroleKey struct{}
+
+// WithIamrole returns a context whose records carry the Iamrole
+func WithIamrole(ctx context.Context, iamrole Iamrole) context.Context {
+	return context.WithValue(ctx, iamroleKey{}, iamrole)
+}
+
+// Emit writes the record of the call with the time, the request id and the Iamrole of the context and the
+// result of the call
+func Emit(ctx context.Context, record Record, err error) {
+	mu.RLock()
+	s := sink
+	mu.RUnlock()
+	if s == nil {
+		return
+	}
+
+	record.Time = time.Now().UTC()
+	record.RequestID = logging.RequestID(ctx)
+	if iamrole, ok := ctx.Value(iamroleKey{}).(Iamrole); ok {
+		record.Iamrole = &iamrole
+	}
+	record.Result = ResultSuccess
+	if err != nil {
+		record.Result = ResultFailure
+		record.Error = err.Error()
+	}
+	if writeErr := s.Write(ctx, record); writeErr != nil {
+		writeFailuresCounter.Inc()
+		logging.Logger(ctx, "pkg.audit", "Emit").Error(writeErr, "unable to write the audit record", "operation", record.Operation, "roleName", record.RoleName)
+	}
+}
@@ -0,0 +1,112 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/check.v1"
+
+	"github.com/keikoproj/iam-manager/pkg/audit"
+	"github.com/keikoproj/iam-manager/pkg/logging"
+)
+
+type AuditSuite struct {
+	t   *testing.T
+	ctx context.Context
+}
+
+func TestAuditSuite(t *testing.T) {
+	check.Suite(&AuditSuite{t: t})
+	check.TestingT(t)
+}
+
+func (s *AuditSuite) SetUpTest(c *check.C) {
+	s.ctx = context.Background()
+}
+
+func (s *AuditSuite) TearDownTest(c *check.C) {
+	audit.SetSink(nil)
+}
+
+type failingSink struct{}
+
+func (failingSink) Write(ctx context.Context, record audit.Record) error {
+	return errors.New("disk full")
+}
+
+func (s *AuditSuite) TestEmitWithoutSink(c *check.C) {
+	c.Assert(audit.Enabled(), check.Equals, false)
+	audit.Emit(s.ctx, audit.Record{Operation: "DeleteRole", RoleName: "k8s-default"}, nil)
+}
+
+func (s *AuditSuite) TestEmit(c *check.C) {
+	var out bytes.Buffer
+	audit.SetSink(audit.NewJSONLines(&out))
+	c.Assert(audit.Enabled(), check.Equals, true)
+
+	ctx := logging.WithRequestID(s.ctx, "8a1c1b04")
+	ctx = audit.WithIamrole(ctx, audit.Iamrole{Namespace: "default", Name: "iamrole", UID: "3f0c", Generation: 2, User: "alice"})
+	audit.Emit(ctx, audit.Record{
+		Operation: "PutRolePolicy",
+		RoleName:  "k8s-default",
+		Policy:    "default",
+		Before:    `{"Version": "2012-10-17", "Statement": []}`,
+		After:     `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:Get*"],"Resource":["*"]}]}`,
+	}, nil)
+	audit.Emit(s.ctx, audit.Record{Operation: "DeleteRolePolicy", RoleName: "k8s-default", Policy: "default", Before: "not json"}, errors.New("AccessDenied"))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	c.Assert(lines, check.HasLen, 2)
+
+	var record map[string]interface{}
+	c.Assert(json.Unmarshal([]byte(lines[0]), &record), check.IsNil)
+	c.Assert(record["requestId"], check.Equals, "8a1c1b04")
+	c.Assert(record["operation"], check.Equals, "PutRolePolicy")
+	c.Assert(record["result"], check.Equals, audit.ResultSuccess)
+	c.Assert(record["iamrole"], check.DeepEquals, map[string]interface{}{"namespace": "default", "name": "iamrole", "uid": "3f0c", "generation": float64(2), "user": "alice"})
+	c.Assert(record["before"], check.DeepEquals, map[string]interface{}{"Version": "2012-10-17", "Statement": []interface{}{}})
+	c.Assert(strings.Contains(lines[0], `"before":{"Version":"2012-10-17","Statement":[]}`), check.Equals, true)
+
+	record = nil
+	c.Assert(json.Unmarshal([]byte(lines[1]), &record), check.IsNil)
+	c.Assert(record["result"], check.Equals, audit.ResultFailure)
+	c.Assert(record["error"], check.Equals, "AccessDenied")
+	c.Assert(record["before"], check.Equals, "not json")
+	_, ok := record["after"]
+	c.Assert(ok, check.Equals, false)
+	_, ok = record["iamrole"]
+	c.Assert(ok, check.Equals, false)
+}
+
+func (s *AuditSuite) TestEmitWriteFailure(c *check.C) {
+	audit.SetSink(failingSink{})
+	audit.Emit(s.ctx, audit.Record{Operation: "DeleteRole", RoleName: "k8s-default"}, nil)
+}
+
+func (s *AuditSuite) TestOpenAppends(c *check.C) {
+	path := filepath.Join(c.MkDir(), "audit.log")
+	for _, operation := range []string{"CreateRole", "DeleteRole"} {
+		sink, closeFile, err := audit.Open(path)
+		c.Assert(err, check.IsNil)
+		audit.SetSink(sink)
+		audit.Emit(s.ctx, audit.Record{Operation: operation, RoleName: "k8s-default"}, nil)
+		c.Assert(closeFile(), check.IsNil)
+	}
+
+	content, err := os.ReadFile(path)
+	c.Assert(err, check.IsNil)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	c.Assert(lines, check.HasLen, 2)
+	c.Assert(strings.Contains(lines[0], `"operation":"CreateRole"`), check.Equals, true)
+	c.Assert(strings.Contains(lines[1], `"operation":"DeleteRole"`), check.Equals, true)
+
+	info, err := os.Stat(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(info.Mode().Perm(), check.Equals, os.FileMode(0o600))
+}
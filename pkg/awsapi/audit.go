@@ -0,0 +1,133 @@
+package awsapi
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+
+	"github.com/keikoproj/iam-manager/pkg/audit"
+	"github.com/keikoproj/iam-manager/pkg/logging"
+)
+
+// The documents before a mutation are only read from IAM when the audit log is enabled. They are left out of
+// the record when they can't be read, the mutation goes on regardless
+
+// auditRole returns the role before a mutation, or nil
+func (i *IAM) auditRole(ctx context.Context, roleName string) *iam.Role {
+	if !audit.Enabled() {
+		return nil
+	}
+	resp, err := i.Client.GetRole(&iam.GetRoleInput{RoleName: aws.String(roleName)})
+	if err != nil {
+		logging.Logger(ctx, "awsapi", "audit", "auditRole").V(1).Info("unable to read the role for the audit log", "roleName", roleName, "error", err.Error())
+		return nil
+	}
+	return resp.Role
+}
+
+// auditRolePolicy returns the inline policy document before a mutation
+func (i *IAM) auditRolePolicy(ctx context.Context, roleName string, policyName string) audit.Document {
+	if !audit.Enabled() {
+		return ""
+	}
+	resp, err := i.Client.GetRolePolicy(&iam.GetRolePolicyInput{RoleName: aws.String(roleName), PolicyName: aws.String(policyName)})
+	if err != nil {
+		logging.Logger(ctx, "awsapi", "audit", "auditRolePolicy").V(1).Info("unable to read the inline policy for the audit log", "roleName", roleName, "policyName", policyName, "error", err.Error())
+		return ""
+	}
+	return decodedDocument(resp.PolicyDocument)
+}
+
+// auditRoleTags returns the tags of the role before a mutation, or nil
+func (i *IAM) auditRoleTags(ctx context.Context, roleName string) map[string]string {
+	if !audit.Enabled() {
+		return nil
+	}
+	tags := map[string]string{}
+	err := i.Client.ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String(roleName)}, func(page *iam.ListRoleTagsOutput, lastPage bool) bool {
+		for _, tag := range page.Tags {
+			tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+		return true
+	})
+	if err != nil {
+		logging.Logger(ctx, "awsapi", "audit", "auditRoleTags").V(1).Info("unable to read the tags for the audit log", "roleName", roleName, "error", err.Error())
+		return nil
+	}
+	return tags
+}
+
+// decodedDocument returns the policy document returned by IAM, which URL encodes them
+func decodedDocument(document *string) audit.Document {
+	decoded, err := url.QueryUnescape(aws.StringValue(document))
+	if err != nil {
+		return audit.Document(aws.StringValue(document))
+	}
+	return audit.Document(decoded)
+}
+
+// trustPolicyDocument returns the trust policy of the role
+func trustPolicyDocument(role *iam.Role) audit.Document {
+	if role == nil {
+		return ""
+	}
+	return decodedDocument(role.AssumeRolePolicyDocument)
+}
+
+// roleSettings returns the settings changed by UpdateRole
+func roleSettings(description string, maxSessionDuration int64) audit.Document {
+	return audit.JSON(map[string]interface{}{"Description": description, "MaxSessionDuration": maxSessionDuration})
+}
+
+// roleSettingsOf returns the settings of the role changed by UpdateRole
+func roleSettingsOf(role *iam.Role) audit.Document {
+	if role == nil {
+		return ""
+	}
+	return roleSettings(aws.StringValue(role.Description), aws.Int64Value(role.MaxSessionDuration))
+}
+
+// permissionsBoundaryOf returns the arn of the permissions boundary of the role
+func permissionsBoundaryOf(role *iam.Role) audit.Document {
+	if role == nil || role.PermissionsBoundary == nil {
+		return ""
+	}
+	return audit.JSON(aws.StringValue(role.PermissionsBoundary.PermissionsBoundaryArn))
+}
+
+// tagsDocument returns the tags, or nothing when they are unknown
+func tagsDocument(tags map[string]string) audit.Document {
+	if tags == nil {
+		return ""
+	}
+	return audit.JSON(tags)
+}
+
+// taggedDocument returns the tags after adding the tags, or only the added tags when the tags before are unknown
+func taggedDocument(tags map[string]string, added map[string]string) audit.Document {
+	merged := map[string]string{}
+	for key, value := range tags {
+		merged[key] = value
+	}
+	for key, value := range added {
+		merged[key] = value
+	}
+	return audit.JSON(merged)
+}
+
+// untaggedDocument returns the tags left after removing the keys, or nothing when the tags are unknown
+func untaggedDocument(tags map[string]string, keys []*string) audit.Document {
+	if tags == nil {
+		return ""
+	}
+	remaining := map[string]string{}
+	for key, value := range tags {
+		remaining[key] = value
+	}
+	for _, key := range keys {
+		delete(remaining, aws.StringValue(key))
+	}
+	return audit.JSON(remaining)
+}
@@ -0,0 +1,175 @@
+package awsapi_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"gopkg.in/check.v1"
+
+	"github.com/keikoproj/iam-manager/internal/config"
+	"github.com/keikoproj/iam-manager/pkg/audit"
+	"github.com/keikoproj/iam-manager/pkg/awsapi"
+	"github.com/keikoproj/iam-manager/pkg/awsapi/fake"
+)
+
+type AuditSuite struct {
+	t       *testing.T
+	ctx     context.Context
+	fakeIAM *fake.IAM
+	iam     *awsapi.IAM
+	sink    *recordingSink
+}
+
+func TestAuditSuite(t *testing.T) {
+	check.Suite(&AuditSuite{t: t})
+	check.TestingT(t)
+}
+
+// recordingSink keeps the records in memory
+type recordingSink struct {
+	mu      sync.Mutex
+	records []audit.Record
+}
+
+func (s *recordingSink) Write(ctx context.Context, record audit.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+// byOperation returns the records of the operation
+func (s *recordingSink) byOperation(operation string) []audit.Record {
+	var records []audit.Record
+	for _, record := range s.records {
+		if record.Operation == operation {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func (s *AuditSuite) SetUpTest(c *check.C) {
+	s.ctx = audit.WithIamrole(context.Background(), audit.Iamrole{Namespace: "default", Name: "iamrole", UID: "3f0c", Generation: 1, User: "alice"})
+	s.fakeIAM = fake.NewIAM("123456789012")
+	s.iam = &awsapi.IAM{Client: s.fakeIAM}
+	s.sink = &recordingSink{}
+	audit.SetSink(s.sink)
+
+	_ = config.LoadProperties("LOCAL")
+}
+
+func (s *AuditSuite) TearDownTest(c *check.C) {
+	audit.SetSink(nil)
+}
+
+func (s *AuditSuite) request() awsapi.IAMRoleRequest {
+	return awsapi.IAMRoleRequest{
+		Name:                            "k8s-default",
+		PolicyName:                      config.InlinePolicyName,
+		Description:                     "test role",
+		SessionDuration:                 3600,
+		TrustPolicy:                     `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"Service":"ec2.amazonaws.com"}}]}`,
+		PermissionPolicy:                `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:Get*"],"Resource":["*"]}]}`,
+		ManagedPermissionBoundaryPolicy: "arn:aws:iam::123456789012:policy/k8s-boundary",
+		ManagedPolicies:                 []string{"arn:aws:iam::123456789012:policy/shared"},
+		Tags:                            map[string]string{"managedBy": "iam-manager", "Namespace": "default"},
+	}
+}
+
+func (s *AuditSuite) TestEnsureRoleRecordsEveryMutation(c *check.C) {
+	_, err := s.iam.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.IsNil)
+
+	var operations []string
+	for _, record := range s.sink.records {
+		operations = append(operations, record.Operation)
+		c.Assert(record.RoleName, check.Equals, "k8s-default")
+		c.Assert(record.Result, check.Equals, audit.ResultSuccess)
+		c.Assert(*record.Iamrole, check.Equals, audit.Iamrole{Namespace: "default", Name: "iamrole", UID: "3f0c", Generation: 1, User: "alice"})
+	}
+	c.Assert(operations, check.DeepEquals, []string{"CreateRole", "TagRole", "PutRolePermissionsBoundary", "AttachRolePolicy", "UpdateRole", "UpdateAssumeRolePolicy", "PutRolePolicy"})
+
+	create := s.sink.byOperation("CreateRole")[0]
+	c.Assert(create.Before, check.Equals, audit.Document(""))
+	c.Assert(create.After, check.Equals, audit.Document(s.request().TrustPolicy))
+	attach := s.sink.byOperation("AttachRolePolicy")[0]
+	c.Assert(attach.Policy, check.Equals, "arn:aws:iam::123456789012:policy/shared")
+	put := s.sink.byOperation("PutRolePolicy")[0]
+	c.Assert(put.Policy, check.Equals, config.InlinePolicyName)
+	c.Assert(put.Before, check.Equals, audit.Document(""))
+	c.Assert(put.After, check.Equals, audit.Document(s.request().PermissionPolicy))
+}
+
+func (s *AuditSuite) TestUpdateRoleRecordsBeforeAndAfter(c *check.C) {
+	_, err := s.iam.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.IsNil)
+	s.sink.records = nil
+
+	req := s.request()
+	req.Description = "updated role"
+	req.TrustPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"Service":"eks.amazonaws.com"}}]}`
+	req.PermissionPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:*"],"Resource":["*"]}]}`
+	req.Tags = map[string]string{"managedBy": "iam-manager", "Namespace": "default", "team": "platform"}
+	_, err = s.iam.EnsureRole(s.ctx, req)
+	c.Assert(err, check.IsNil)
+
+	update := s.sink.byOperation("UpdateRole")[0]
+	c.Assert(update.Before, check.Equals, audit.Document(`{"Description":"test role","MaxSessionDuration":3600}`))
+	c.Assert(update.After, check.Equals, audit.Document(`{"Description":"updated role","MaxSessionDuration":3600}`))
+	trust := s.sink.byOperation("UpdateAssumeRolePolicy")[0]
+	c.Assert(trust.Before, check.Equals, audit.Document(s.request().TrustPolicy))
+	c.Assert(trust.After, check.Equals, audit.Document(req.TrustPolicy))
+	put := s.sink.byOperation("PutRolePolicy")[0]
+	c.Assert(put.Before, check.Equals, audit.Document(s.request().PermissionPolicy))
+	c.Assert(put.After, check.Equals, audit.Document(req.PermissionPolicy))
+	tag := s.sink.byOperation("TagRole")[0]
+	c.Assert(tag.Before, check.Equals, audit.Document(`{"Namespace":"default","managedBy":"iam-manager"}`))
+	c.Assert(tag.After, check.Equals, audit.Document(`{"Namespace":"default","managedBy":"iam-manager","team":"platform"}`))
+	boundary := s.sink.byOperation("PutRolePermissionsBoundary")[0]
+	c.Assert(boundary.Before, check.Equals, audit.Document(`"arn:aws:iam::123456789012:policy/k8s-boundary"`))
+}
+
+func (s *AuditSuite) TestDeleteRoleRecordsTheRemovedDocuments(c *check.C) {
+	_, err := s.iam.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.IsNil)
+	s.sink.records = nil
+
+	c.Assert(s.iam.DeleteRole(s.ctx, "k8s-default"), check.IsNil)
+
+	var operations []string
+	for _, record := range s.sink.records {
+		operations = append(operations, record.Operation)
+	}
+	c.Assert(operations, check.DeepEquals, []string{"DetachRolePolicy", "DeleteRolePolicy", "DeleteRole"})
+	c.Assert(s.sink.byOperation("DeleteRolePolicy")[0].Before, check.Equals, audit.Document(s.request().PermissionPolicy))
+	c.Assert(s.sink.byOperation("DeleteRole")[0].Before, check.Equals, audit.Document(s.request().TrustPolicy))
+}
+
+func (s *AuditSuite) TestRetainRoleRecordsTheRemovedTags(c *check.C) {
+	_, err := s.iam.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.IsNil)
+	s.sink.records = nil
+
+	c.Assert(s.iam.RetainRole(s.ctx, "k8s-default", false), check.IsNil)
+
+	untag := s.sink.byOperation("UntagRole")
+	c.Assert(untag, check.HasLen, 1)
+	c.Assert(untag[0].Keys, check.DeepEquals, awsapi.OwnershipTagKeys)
+	c.Assert(untag[0].After, check.Equals, audit.Document(`{}`))
+}
+
+func (s *AuditSuite) TestFailedCallIsRecorded(c *check.C) {
+	_, err := s.iam.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.IsNil)
+	s.sink.records = nil
+	s.fakeIAM.SetError("DeleteRole", awserr.New("AccessDenied", "not authorized to perform iam:DeleteRole", nil))
+
+	c.Assert(s.iam.DeleteRole(s.ctx, "k8s-default"), check.NotNil)
+	deleteRole := s.sink.byOperation("DeleteRole")
+	c.Assert(deleteRole, check.HasLen, 1)
+	c.Assert(deleteRole[0].Result, check.Equals, audit.ResultFailure)
+	c.Assert(deleteRole[0].Error, check.Matches, "AccessDenied.*")
+}
@@ -10,8 +10,10 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/keikoproj/iam-manager/pkg/logging"
+	"github.com/keikoproj/iam-manager/pkg/tracing"
 )
 
 type EKSIface interface {
@@ -27,15 +29,18 @@ func NewEKS(region string) *EKS {
 	if err != nil {
 		panic(err)
 	}
+	InstrumentSession(sess)
 	return &EKS{
 		Client: eks.New(sess),
 	}
 }
 
 // DescribeCluster function provides cluster info
-func (e *EKS) DescribeCluster(ctx context.Context, clusterName string) (*eks.DescribeClusterOutput, error) {
+func (e *EKS) DescribeCluster(ctx context.Context, clusterName string) (_ *eks.DescribeClusterOutput, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.DescribeCluster", attribute.String("aws.eks.cluster_name", clusterName))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "eks", "DescribeCluster")
-	log.WithValues("clusterName", clusterName)
+	log = log.WithValues("clusterName", clusterName)
 	log.V(1).Info("Initiating api call")
 
 	input := &eks.DescribeClusterInput{
@@ -0,0 +1,763 @@
+// Package fake provides an in-memory IAM backend which can be used instead of AWS in tests and local runs
+package fake
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+)
+
+// Role is the state of a role held by the fake backend
+type Role struct {
+	Name                     string            `json:"name"`
+	Path                     string            `json:"path"`
+	ARN                      string            `json:"arn"`
+	RoleID                   string            `json:"roleId"`
+	Description              string            `json:"description,omitempty"`
+	MaxSessionDuration       int64             `json:"maxSessionDuration"`
+	AssumeRolePolicyDocument string            `json:"assumeRolePolicyDocument"`
+	PermissionsBoundary      string            `json:"permissionsBoundary,omitempty"`
+	Tags                     map[string]string `json:"tags,omitempty"`
+	InlinePolicies           map[string]string `json:"inlinePolicies,omitempty"`
+	ManagedPolicies          []string          `json:"managedPolicies,omitempty"`
+	CreateDate               time.Time         `json:"createDate"`
+}
+
+// OIDCProvider is the state of an OpenID Connect provider held by the fake backend
+type OIDCProvider struct {
+	ARN            string    `json:"arn"`
+	URL            string    `json:"url"`
+	ClientIDList   []string  `json:"clientIdList,omitempty"`
+	ThumbprintList []string  `json:"thumbprintList,omitempty"`
+	CreateDate     time.Time `json:"createDate"`
+}
+
+// IAM is an in-memory implementation of iamiface.IAMAPI. It implements the role, role policy, tag and
+// OpenID Connect provider operations used by iam-manager. Any other operation panics
+type IAM struct {
+	iamiface.IAMAPI
+
+	// AccountID is used to build the ARNs of the roles and OIDC providers
+	AccountID string
+
+	mu            sync.Mutex
+	roles         map[string]*Role
+	oidcProviders map[string]*OIDCProvider
+	errs          map[string]error
+	stateFile     string
+}
+
+// state is the content of the state file
+type state struct {
+	Roles         []Role         `json:"roles"`
+	OIDCProviders []OIDCProvider `json:"oidcProviders"`
+}
+
+// NewIAM returns an empty fake IAM backend for the account
+func NewIAM(accountID string) *IAM {
+	return &IAM{
+		AccountID:     accountID,
+		roles:         map[string]*Role{},
+		oidcProviders: map[string]*OIDCProvider{},
+		errs:          map[string]error{},
+	}
+}
+
+// NewFileIAM returns a fake IAM backend which keeps its state in the JSON file. The roles and OIDC providers of an
+// existing file are loaded, and the file is rewritten after every change
+func NewFileIAM(accountID string, stateFile string) (*IAM, error) {
+	f := NewIAM(accountID)
+	f.stateFile = stateFile
+
+	data, err := os.ReadFile(stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("unable to decode fake IAM state %s: %v", stateFile, err)
+	}
+	for i := range st.Roles {
+		role := copyRole(&st.Roles[i])
+		f.roles[role.Name] = &role
+	}
+	for i := range st.OIDCProviders {
+		provider := st.OIDCProviders[i]
+		f.oidcProviders[provider.ARN] = &provider
+	}
+	return f, nil
+}
+
+// SetError makes every call of the operation, e.g. "CreateRole" or "ListRoleTags" for ListRoleTagsPages, fail with err
+// until it is cleared with a nil err
+func (f *IAM) SetError(operation string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.errs, operation)
+		return
+	}
+	f.errs[operation] = err
+}
+
+// Role returns a copy of the role
+func (f *IAM) Role(roleName string) (Role, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	role, ok := f.roles[roleName]
+	if !ok {
+		return Role{}, false
+	}
+	return copyRole(role), true
+}
+
+// Roles returns a copy of every role sorted by name
+func (f *IAM) Roles() []Role {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var roles []Role
+	for _, name := range f.roleNames("") {
+		roles = append(roles, copyRole(f.roles[name]))
+	}
+	return roles
+}
+
+// PutRole adds or replaces a role, e.g. to simulate a role which was created outside of iam-manager.
+// It is written to the state file with the next change
+func (f *IAM) PutRole(role Role) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if role.Path == "" {
+		role.Path = "/"
+	}
+	if role.ARN == "" {
+		role.ARN = f.roleARN(role.Path, role.Name)
+	}
+	if role.RoleID == "" {
+		role.RoleID = newID("AROA")
+	}
+	if role.CreateDate.IsZero() {
+		role.CreateDate = time.Now().UTC()
+	}
+	r := copyRole(&role)
+	f.roles[role.Name] = &r
+}
+
+// OIDCProviders returns a copy of every OIDC provider sorted by ARN
+func (f *IAM) OIDCProviders() []OIDCProvider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var providers []OIDCProvider
+	for _, arn := range f.oidcProviderARNs() {
+		p := *f.oidcProviders[arn]
+		p.ClientIDList = append([]string(nil), p.ClientIDList...)
+		p.ThumbprintList = append([]string(nil), p.ThumbprintList...)
+		providers = append(providers, p)
+	}
+	return providers
+}
+
+// GetRole returns the role
+func (f *IAM) GetRole(input *iam.GetRoleInput) (*iam.GetRoleOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("GetRole", input); err != nil {
+		return nil, err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		return nil, err
+	}
+	return &iam.GetRoleOutput{Role: role.toIAM()}, nil
+}
+
+// CreateRole creates the role
+func (f *IAM) CreateRole(input *iam.CreateRoleInput) (*iam.CreateRoleOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("CreateRole", input); err != nil {
+		return nil, err
+	}
+	name := aws.StringValue(input.RoleName)
+	if _, ok := f.roles[name]; ok {
+		return nil, awserr.New(iam.ErrCodeEntityAlreadyExistsException, fmt.Sprintf("Role with name %s already exists.", name), nil)
+	}
+	path := aws.StringValue(input.Path)
+	if path == "" {
+		path = "/"
+	}
+	maxSessionDuration := aws.Int64Value(input.MaxSessionDuration)
+	if maxSessionDuration == 0 {
+		maxSessionDuration = 3600
+	}
+	role := &Role{
+		Name:                     name,
+		Path:                     path,
+		ARN:                      f.roleARN(path, name),
+		RoleID:                   newID("AROA"),
+		Description:              aws.StringValue(input.Description),
+		MaxSessionDuration:       maxSessionDuration,
+		AssumeRolePolicyDocument: aws.StringValue(input.AssumeRolePolicyDocument),
+		PermissionsBoundary:      aws.StringValue(input.PermissionsBoundary),
+		Tags:                     map[string]string{},
+		InlinePolicies:           map[string]string{},
+		CreateDate:               time.Now().UTC(),
+	}
+	for _, tag := range input.Tags {
+		role.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	f.roles[name] = role
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	return &iam.CreateRoleOutput{Role: role.toIAM()}, nil
+}
+
+// UpdateRole updates the description and max session duration of the role
+func (f *IAM) UpdateRole(input *iam.UpdateRoleInput) (*iam.UpdateRoleOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("UpdateRole", input); err != nil {
+		return nil, err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		return nil, err
+	}
+	if input.Description != nil {
+		role.Description = aws.StringValue(input.Description)
+	}
+	if input.MaxSessionDuration != nil {
+		role.MaxSessionDuration = aws.Int64Value(input.MaxSessionDuration)
+	}
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	return &iam.UpdateRoleOutput{}, nil
+}
+
+// UpdateAssumeRolePolicy replaces the trust policy of the role
+func (f *IAM) UpdateAssumeRolePolicy(input *iam.UpdateAssumeRolePolicyInput) (*iam.UpdateAssumeRolePolicyOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("UpdateAssumeRolePolicy", input); err != nil {
+		return nil, err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		return nil, err
+	}
+	role.AssumeRolePolicyDocument = aws.StringValue(input.PolicyDocument)
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	return &iam.UpdateAssumeRolePolicyOutput{}, nil
+}
+
+// PutRolePermissionsBoundary sets the permission boundary of the role
+func (f *IAM) PutRolePermissionsBoundary(input *iam.PutRolePermissionsBoundaryInput) (*iam.PutRolePermissionsBoundaryOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("PutRolePermissionsBoundary", input); err != nil {
+		return nil, err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		return nil, err
+	}
+	role.PermissionsBoundary = aws.StringValue(input.PermissionsBoundary)
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	return &iam.PutRolePermissionsBoundaryOutput{}, nil
+}
+
+// DeleteRole deletes the role. Like IAM, it refuses roles which still have policies
+func (f *IAM) DeleteRole(input *iam.DeleteRoleInput) (*iam.DeleteRoleOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("DeleteRole", input); err != nil {
+		return nil, err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		return nil, err
+	}
+	if len(role.InlinePolicies) > 0 || len(role.ManagedPolicies) > 0 {
+		return nil, awserr.New(iam.ErrCodeDeleteConflictException, "Cannot delete entity, must remove policies first.", nil)
+	}
+	delete(f.roles, role.Name)
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	return &iam.DeleteRoleOutput{}, nil
+}
+
+// ListRolesPages lists the roles under the path prefix in a single page
+func (f *IAM) ListRolesPages(input *iam.ListRolesInput, fn func(*iam.ListRolesOutput, bool) bool) error {
+	f.mu.Lock()
+	if err := f.check("ListRoles", input); err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	out := &iam.ListRolesOutput{IsTruncated: aws.Bool(false)}
+	for _, name := range f.roleNames(aws.StringValue(input.PathPrefix)) {
+		out.Roles = append(out.Roles, f.roles[name].toIAM())
+	}
+	f.mu.Unlock()
+	fn(out, true)
+	return nil
+}
+
+// PutRolePolicy adds or replaces an inline policy of the role
+func (f *IAM) PutRolePolicy(input *iam.PutRolePolicyInput) (*iam.PutRolePolicyOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("PutRolePolicy", input); err != nil {
+		return nil, err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		return nil, err
+	}
+	role.InlinePolicies[aws.StringValue(input.PolicyName)] = aws.StringValue(input.PolicyDocument)
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	return &iam.PutRolePolicyOutput{}, nil
+}
+
+// GetRolePolicy returns an inline policy of the role. The document is URL encoded like IAM does
+func (f *IAM) GetRolePolicy(input *iam.GetRolePolicyInput) (*iam.GetRolePolicyOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("GetRolePolicy", input); err != nil {
+		return nil, err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		return nil, err
+	}
+	document, ok := role.InlinePolicies[aws.StringValue(input.PolicyName)]
+	if !ok {
+		return nil, noSuchEntity("policy", aws.StringValue(input.PolicyName))
+	}
+	return &iam.GetRolePolicyOutput{
+		RoleName:       input.RoleName,
+		PolicyName:     input.PolicyName,
+		PolicyDocument: aws.String(url.QueryEscape(document)),
+	}, nil
+}
+
+// DeleteRolePolicy deletes an inline policy of the role
+func (f *IAM) DeleteRolePolicy(input *iam.DeleteRolePolicyInput) (*iam.DeleteRolePolicyOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("DeleteRolePolicy", input); err != nil {
+		return nil, err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := role.InlinePolicies[aws.StringValue(input.PolicyName)]; !ok {
+		return nil, noSuchEntity("policy", aws.StringValue(input.PolicyName))
+	}
+	delete(role.InlinePolicies, aws.StringValue(input.PolicyName))
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	return &iam.DeleteRolePolicyOutput{}, nil
+}
+
+// ListRolePoliciesPages lists the inline policy names of the role in a single page
+func (f *IAM) ListRolePoliciesPages(input *iam.ListRolePoliciesInput, fn func(*iam.ListRolePoliciesOutput, bool) bool) error {
+	f.mu.Lock()
+	if err := f.check("ListRolePolicies", input); err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	var names []string
+	for name := range role.InlinePolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	f.mu.Unlock()
+	fn(&iam.ListRolePoliciesOutput{PolicyNames: aws.StringSlice(names), IsTruncated: aws.Bool(false)}, true)
+	return nil
+}
+
+// AttachRolePolicy attaches a managed policy to the role
+func (f *IAM) AttachRolePolicy(input *iam.AttachRolePolicyInput) (*iam.AttachRolePolicyOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("AttachRolePolicy", input); err != nil {
+		return nil, err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		return nil, err
+	}
+	policyArn := aws.StringValue(input.PolicyArn)
+	for _, attached := range role.ManagedPolicies {
+		if attached == policyArn {
+			return &iam.AttachRolePolicyOutput{}, nil
+		}
+	}
+	role.ManagedPolicies = append(role.ManagedPolicies, policyArn)
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	return &iam.AttachRolePolicyOutput{}, nil
+}
+
+// DetachRolePolicy detaches a managed policy from the role
+func (f *IAM) DetachRolePolicy(input *iam.DetachRolePolicyInput) (*iam.DetachRolePolicyOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("DetachRolePolicy", input); err != nil {
+		return nil, err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		return nil, err
+	}
+	policyArn := aws.StringValue(input.PolicyArn)
+	for i, attached := range role.ManagedPolicies {
+		if attached == policyArn {
+			role.ManagedPolicies = append(role.ManagedPolicies[:i], role.ManagedPolicies[i+1:]...)
+			if err := f.save(); err != nil {
+				return nil, err
+			}
+			return &iam.DetachRolePolicyOutput{}, nil
+		}
+	}
+	return nil, noSuchEntity("policy", policyArn)
+}
+
+// ListAttachedRolePoliciesPages lists the managed policies of the role in a single page
+func (f *IAM) ListAttachedRolePoliciesPages(input *iam.ListAttachedRolePoliciesInput, fn func(*iam.ListAttachedRolePoliciesOutput, bool) bool) error {
+	f.mu.Lock()
+	if err := f.check("ListAttachedRolePolicies", input); err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	out := &iam.ListAttachedRolePoliciesOutput{IsTruncated: aws.Bool(false)}
+	for _, policyArn := range role.ManagedPolicies {
+		out.AttachedPolicies = append(out.AttachedPolicies, &iam.AttachedPolicy{
+			PolicyArn:  aws.String(policyArn),
+			PolicyName: aws.String(policyArn[strings.LastIndex(policyArn, "/")+1:]),
+		})
+	}
+	f.mu.Unlock()
+	fn(out, true)
+	return nil
+}
+
+// TagRole adds or overwrites tags of the role
+func (f *IAM) TagRole(input *iam.TagRoleInput) (*iam.TagRoleOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("TagRole", input); err != nil {
+		return nil, err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range input.Tags {
+		role.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	return &iam.TagRoleOutput{}, nil
+}
+
+// UntagRole removes tags from the role. Missing tags are ignored like IAM does
+func (f *IAM) UntagRole(input *iam.UntagRoleInput) (*iam.UntagRoleOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("UntagRole", input); err != nil {
+		return nil, err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range input.TagKeys {
+		delete(role.Tags, aws.StringValue(key))
+	}
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	return &iam.UntagRoleOutput{}, nil
+}
+
+// ListRoleTagsPages lists the tags of the role sorted by key in a single page
+func (f *IAM) ListRoleTagsPages(input *iam.ListRoleTagsInput, fn func(*iam.ListRoleTagsOutput, bool) bool) error {
+	f.mu.Lock()
+	if err := f.check("ListRoleTags", input); err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	role, err := f.role(aws.StringValue(input.RoleName))
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	out := &iam.ListRoleTagsOutput{Tags: role.iamTags(), IsTruncated: aws.Bool(false)}
+	f.mu.Unlock()
+	fn(out, true)
+	return nil
+}
+
+// CreateOpenIDConnectProvider creates the OIDC provider for the url
+func (f *IAM) CreateOpenIDConnectProvider(input *iam.CreateOpenIDConnectProviderInput) (*iam.CreateOpenIDConnectProviderOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("CreateOpenIDConnectProvider", input); err != nil {
+		return nil, err
+	}
+	host := strings.TrimPrefix(aws.StringValue(input.Url), "https://")
+	arn := fmt.Sprintf("arn:aws:iam::%s:oidc-provider/%s", f.AccountID, host)
+	if _, ok := f.oidcProviders[arn]; ok {
+		return nil, awserr.New(iam.ErrCodeEntityAlreadyExistsException, fmt.Sprintf("Provider with url %s already exists.", aws.StringValue(input.Url)), nil)
+	}
+	f.oidcProviders[arn] = &OIDCProvider{
+		ARN:            arn,
+		URL:            host,
+		ClientIDList:   aws.StringValueSlice(input.ClientIDList),
+		ThumbprintList: aws.StringValueSlice(input.ThumbprintList),
+		CreateDate:     time.Now().UTC(),
+	}
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	return &iam.CreateOpenIDConnectProviderOutput{OpenIDConnectProviderArn: aws.String(arn)}, nil
+}
+
+// GetOpenIDConnectProvider returns the OIDC provider
+func (f *IAM) GetOpenIDConnectProvider(input *iam.GetOpenIDConnectProviderInput) (*iam.GetOpenIDConnectProviderOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("GetOpenIDConnectProvider", input); err != nil {
+		return nil, err
+	}
+	provider, ok := f.oidcProviders[aws.StringValue(input.OpenIDConnectProviderArn)]
+	if !ok {
+		return nil, noSuchEntity("OpenIDConnect provider", aws.StringValue(input.OpenIDConnectProviderArn))
+	}
+	return &iam.GetOpenIDConnectProviderOutput{
+		Url:            aws.String(provider.URL),
+		ClientIDList:   aws.StringSlice(provider.ClientIDList),
+		ThumbprintList: aws.StringSlice(provider.ThumbprintList),
+		CreateDate:     aws.Time(provider.CreateDate),
+	}, nil
+}
+
+// ListOpenIDConnectProviders lists the ARNs of the OIDC providers
+func (f *IAM) ListOpenIDConnectProviders(input *iam.ListOpenIDConnectProvidersInput) (*iam.ListOpenIDConnectProvidersOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("ListOpenIDConnectProviders", input); err != nil {
+		return nil, err
+	}
+	out := &iam.ListOpenIDConnectProvidersOutput{}
+	for _, arn := range f.oidcProviderARNs() {
+		out.OpenIDConnectProviderList = append(out.OpenIDConnectProviderList, &iam.OpenIDConnectProviderListEntry{Arn: aws.String(arn)})
+	}
+	return out, nil
+}
+
+// DeleteOpenIDConnectProvider deletes the OIDC provider
+func (f *IAM) DeleteOpenIDConnectProvider(input *iam.DeleteOpenIDConnectProviderInput) (*iam.DeleteOpenIDConnectProviderOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("DeleteOpenIDConnectProvider", input); err != nil {
+		return nil, err
+	}
+	arn := aws.StringValue(input.OpenIDConnectProviderArn)
+	if _, ok := f.oidcProviders[arn]; !ok {
+		return nil, noSuchEntity("OpenIDConnect provider", arn)
+	}
+	delete(f.oidcProviders, arn)
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	return &iam.DeleteOpenIDConnectProviderOutput{}, nil
+}
+
+// save writes the roles and OIDC providers to the state file, if any. Must be called with mu held
+func (f *IAM) save() error {
+	if f.stateFile == "" {
+		return nil
+	}
+	st := state{Roles: []Role{}, OIDCProviders: []OIDCProvider{}}
+	for _, name := range f.roleNames("") {
+		st.Roles = append(st.Roles, *f.roles[name])
+	}
+	for _, arn := range f.oidcProviderARNs() {
+		st.OIDCProviders = append(st.OIDCProviders, *f.oidcProviders[arn])
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return awserr.New(iam.ErrCodeServiceFailureException, "unable to encode the fake IAM state", err)
+	}
+	// Write next to the state file and rename, so that a reader never sees a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(f.stateFile), filepath.Base(f.stateFile)+".*")
+	if err != nil {
+		return awserr.New(iam.ErrCodeServiceFailureException, "unable to write the fake IAM state", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return awserr.New(iam.ErrCodeServiceFailureException, "unable to write the fake IAM state", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return awserr.New(iam.ErrCodeServiceFailureException, "unable to write the fake IAM state", err)
+	}
+	if err := os.Rename(tmp.Name(), f.stateFile); err != nil {
+		return awserr.New(iam.ErrCodeServiceFailureException, "unable to write the fake IAM state", err)
+	}
+	return nil
+}
+
+// validator is implemented by the IAM inputs which have required fields
+type validator interface {
+	Validate() error
+}
+
+// check returns the injected error of the operation or the validation error of the input. Must be called with mu held
+func (f *IAM) check(operation string, input interface{}) error {
+	if err, ok := f.errs[operation]; ok {
+		return err
+	}
+	if v, ok := input.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return awserr.New(iam.ErrCodeInvalidInputException, err.Error(), err)
+		}
+	}
+	return nil
+}
+
+// role returns the stored role or a NoSuchEntity error. Must be called with mu held
+func (f *IAM) role(roleName string) (*Role, error) {
+	role, ok := f.roles[roleName]
+	if !ok {
+		return nil, noSuchEntity("role", roleName)
+	}
+	return role, nil
+}
+
+// roleNames returns the sorted names of the roles under the path prefix. Must be called with mu held
+func (f *IAM) roleNames(pathPrefix string) []string {
+	var names []string
+	for name, role := range f.roles {
+		if strings.HasPrefix(role.Path, pathPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// oidcProviderARNs returns the sorted ARNs of the OIDC providers. Must be called with mu held
+func (f *IAM) oidcProviderARNs() []string {
+	var arns []string
+	for arn := range f.oidcProviders {
+		arns = append(arns, arn)
+	}
+	sort.Strings(arns)
+	return arns
+}
+
+func (f *IAM) roleARN(path, roleName string) string {
+	return fmt.Sprintf("arn:aws:iam::%s:role%s%s", f.AccountID, path, roleName)
+}
+
+// toIAM converts the role to the shape returned by IAM, with the trust policy URL encoded
+func (r *Role) toIAM() *iam.Role {
+	role := &iam.Role{
+		RoleName:                 aws.String(r.Name),
+		Path:                     aws.String(r.Path),
+		Arn:                      aws.String(r.ARN),
+		RoleId:                   aws.String(r.RoleID),
+		Description:              aws.String(r.Description),
+		MaxSessionDuration:       aws.Int64(r.MaxSessionDuration),
+		AssumeRolePolicyDocument: aws.String(url.QueryEscape(r.AssumeRolePolicyDocument)),
+		CreateDate:               aws.Time(r.CreateDate),
+		Tags:                     r.iamTags(),
+	}
+	if r.PermissionsBoundary != "" {
+		role.PermissionsBoundary = &iam.AttachedPermissionsBoundary{
+			PermissionsBoundaryArn:  aws.String(r.PermissionsBoundary),
+			PermissionsBoundaryType: aws.String(iam.PermissionsBoundaryAttachmentTypePermissionsBoundaryPolicy),
+		}
+	}
+	return role
+}
+
+func (r *Role) iamTags() []*iam.Tag {
+	var keys []string
+	for key := range r.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var tags []*iam.Tag
+	for _, key := range keys {
+		tags = append(tags, &iam.Tag{Key: aws.String(key), Value: aws.String(r.Tags[key])})
+	}
+	return tags
+}
+
+func copyRole(r *Role) Role {
+	c := *r
+	c.Tags = map[string]string{}
+	for k, v := range r.Tags {
+		c.Tags[k] = v
+	}
+	c.InlinePolicies = map[string]string{}
+	for k, v := range r.InlinePolicies {
+		c.InlinePolicies[k] = v
+	}
+	c.ManagedPolicies = append([]string(nil), r.ManagedPolicies...)
+	return c
+}
+
+func noSuchEntity(kind, name string) error {
+	return awserr.New(iam.ErrCodeNoSuchEntityException, fmt.Sprintf("The %s with name %s cannot be found.", kind, name), nil)
+}
+
+func newID(prefix string) string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return prefix + strings.ToUpper(hex.EncodeToString(b))
+}
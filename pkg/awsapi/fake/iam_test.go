@@ -0,0 +1,172 @@
+package fake_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/keikoproj/iam-manager/internal/config"
+	"github.com/keikoproj/iam-manager/pkg/awsapi"
+	"github.com/keikoproj/iam-manager/pkg/awsapi/fake"
+	"gopkg.in/check.v1"
+)
+
+type FakeIAMSuite struct {
+	t       *testing.T
+	ctx     context.Context
+	fakeIAM *fake.IAM
+	iam     *awsapi.IAM
+}
+
+func TestFakeIAMTestSuite(t *testing.T) {
+	check.Suite(&FakeIAMSuite{t: t})
+	check.TestingT(t)
+}
+
+func (s *FakeIAMSuite) SetUpTest(c *check.C) {
+	s.ctx = context.Background()
+	s.fakeIAM = fake.NewIAM("123456789012")
+	s.iam = &awsapi.IAM{Client: s.fakeIAM}
+
+	_ = config.LoadProperties("LOCAL")
+}
+
+func (s *FakeIAMSuite) request() awsapi.IAMRoleRequest {
+	return awsapi.IAMRoleRequest{
+		Name:                            "k8s-default",
+		PolicyName:                      config.InlinePolicyName,
+		Description:                     "test role",
+		SessionDuration:                 3600,
+		TrustPolicy:                     `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"Service":"ec2.amazonaws.com"}}]}`,
+		PermissionPolicy:                `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:Get*"],"Resource":["*"]}]}`,
+		ManagedPermissionBoundaryPolicy: "arn:aws:iam::123456789012:policy/k8s-boundary",
+		ManagedPolicies:                 []string{"arn:aws:iam::123456789012:policy/shared"},
+		Tags:                            map[string]string{"managedBy": "iam-manager", "Namespace": "default"},
+	}
+}
+
+func (s *FakeIAMSuite) TestEnsureRoleCreatesRole(c *check.C) {
+	resp, err := s.iam.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.RoleARN, check.Equals, "arn:aws:iam::123456789012:role/k8s-default")
+
+	role, ok := s.fakeIAM.Role("k8s-default")
+	c.Assert(ok, check.Equals, true)
+	c.Assert(role.RoleID, check.Equals, resp.RoleID)
+	c.Assert(role.Description, check.Equals, "test role")
+	c.Assert(role.PermissionsBoundary, check.Equals, "arn:aws:iam::123456789012:policy/k8s-boundary")
+	c.Assert(role.ManagedPolicies, check.DeepEquals, []string{"arn:aws:iam::123456789012:policy/shared"})
+	c.Assert(role.InlinePolicies[config.InlinePolicyName], check.Equals, s.request().PermissionPolicy)
+	c.Assert(role.Tags, check.DeepEquals, map[string]string{"managedBy": "iam-manager", "Namespace": "default"})
+
+	// A second reconcile finds the role and leaves it as it is
+	resp2, err := s.iam.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.IsNil)
+	c.Assert(resp2.RoleID, check.Equals, resp.RoleID)
+}
+
+func (s *FakeIAMSuite) TestEnsureRoleRefusesForeignRole(c *check.C) {
+	s.fakeIAM.PutRole(fake.Role{Name: "k8s-default", Tags: map[string]string{"Namespace": "other"}})
+
+	_, err := s.iam.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.NotNil)
+	c.Assert(err.Error(), check.Matches, ".*"+awsapi.RoleExistsAlreadyForOtherNamespace)
+}
+
+func (s *FakeIAMSuite) TestGetRolePolicyIsURLEncoded(c *check.C) {
+	_, err := s.iam.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.IsNil)
+
+	policies, err := s.iam.GetRolePolicies(s.ctx, s.request(), []string{config.InlinePolicyName})
+	c.Assert(err, check.IsNil)
+	c.Assert(policies[config.InlinePolicyName], check.Not(check.Equals), s.request().PermissionPolicy)
+}
+
+func (s *FakeIAMSuite) TestDeleteRoleRemovesPolicies(c *check.C) {
+	_, err := s.iam.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.IsNil)
+
+	_, err = s.fakeIAM.DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("k8s-default")})
+	c.Assert(err, check.NotNil)
+	c.Assert(err.(awserr.Error).Code(), check.Equals, iam.ErrCodeDeleteConflictException)
+
+	c.Assert(s.iam.DeleteRole(s.ctx, "k8s-default"), check.IsNil)
+	_, ok := s.fakeIAM.Role("k8s-default")
+	c.Assert(ok, check.Equals, false)
+
+	// Deleting it again is fine
+	c.Assert(s.iam.DeleteRole(s.ctx, "k8s-default"), check.IsNil)
+}
+
+func (s *FakeIAMSuite) TestRetainRoleRemovesOwnershipTags(c *check.C) {
+	req := s.request()
+	req.Tags["team"] = "platform"
+	_, err := s.iam.EnsureRole(s.ctx, req)
+	c.Assert(err, check.IsNil)
+
+	c.Assert(s.iam.RetainRole(s.ctx, "k8s-default", true), check.IsNil)
+	role, ok := s.fakeIAM.Role("k8s-default")
+	c.Assert(ok, check.Equals, true)
+	c.Assert(role.Tags, check.DeepEquals, map[string]string{"team": "platform"})
+	c.Assert(role.InlinePolicies, check.HasLen, 0)
+	c.Assert(role.ManagedPolicies, check.HasLen, 0)
+}
+
+func (s *FakeIAMSuite) TestListRolesByPathPrefix(c *check.C) {
+	s.fakeIAM.PutRole(fake.Role{Name: "k8s-a", Path: "/k8s/"})
+	s.fakeIAM.PutRole(fake.Role{Name: "other"})
+
+	roles, err := s.iam.ListRoles(s.ctx, "/k8s/")
+	c.Assert(err, check.IsNil)
+	c.Assert(roles, check.HasLen, 1)
+	c.Assert(aws.StringValue(roles[0].Arn), check.Equals, "arn:aws:iam::123456789012:role/k8s/k8s-a")
+}
+
+func (s *FakeIAMSuite) TestCreateOIDCProviderTwice(c *check.C) {
+	c.Assert(s.iam.CreateOIDCProvider(s.ctx, "https://oidc.example.com/id/1234", "sts.amazonaws.com", "abcd"), check.IsNil)
+	c.Assert(s.iam.CreateOIDCProvider(s.ctx, "https://oidc.example.com/id/1234", "sts.amazonaws.com", "abcd"), check.IsNil)
+
+	providers := s.fakeIAM.OIDCProviders()
+	c.Assert(providers, check.HasLen, 1)
+	c.Assert(providers[0].ARN, check.Equals, "arn:aws:iam::123456789012:oidc-provider/oidc.example.com/id/1234")
+	c.Assert(providers[0].ClientIDList, check.DeepEquals, []string{"sts.amazonaws.com"})
+}
+
+func (s *FakeIAMSuite) TestSetError(c *check.C) {
+	s.fakeIAM.SetError("CreateRole", awserr.New(iam.ErrCodeLimitExceededException, "too many roles", nil))
+
+	_, err := s.iam.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.NotNil)
+	c.Assert(err.(awserr.Error).Code(), check.Equals, iam.ErrCodeLimitExceededException)
+	c.Assert(s.fakeIAM.Roles(), check.HasLen, 0)
+
+	s.fakeIAM.SetError("CreateRole", nil)
+	_, err = s.iam.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.IsNil)
+}
+
+func (s *FakeIAMSuite) TestFileIAMKeepsState(c *check.C) {
+	stateFile := filepath.Join(c.MkDir(), "iam.json")
+	fileIAM, err := fake.NewFileIAM("123456789012", stateFile)
+	c.Assert(err, check.IsNil)
+	client := &awsapi.IAM{Client: fileIAM}
+	resp, err := client.EnsureRole(s.ctx, s.request())
+	c.Assert(err, check.IsNil)
+	c.Assert(client.CreateOIDCProvider(s.ctx, "https://oidc.example.com/id/1234", "sts.amazonaws.com", "abcd"), check.IsNil)
+
+	reloaded, err := fake.NewFileIAM("123456789012", stateFile)
+	c.Assert(err, check.IsNil)
+	role, ok := reloaded.Role("k8s-default")
+	c.Assert(ok, check.Equals, true)
+	c.Assert(role.RoleID, check.Equals, resp.RoleID)
+	c.Assert(role.InlinePolicies[config.InlinePolicyName], check.Equals, s.request().PermissionPolicy)
+	c.Assert(reloaded.OIDCProviders(), check.HasLen, 1)
+
+	c.Assert((&awsapi.IAM{Client: reloaded}).DeleteRole(s.ctx, "k8s-default"), check.IsNil)
+	reloaded, err = fake.NewFileIAM("123456789012", stateFile)
+	c.Assert(err, check.IsNil)
+	c.Assert(reloaded.Roles(), check.HasLen, 0)
+}
@@ -6,7 +6,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,36 +19,65 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/keikoproj/iam-manager/pkg/audit"
 	"github.com/keikoproj/iam-manager/pkg/logging"
+	"github.com/keikoproj/iam-manager/pkg/tracing"
 )
 
-// IAMIface defines interface methods
+// IAMIface defines the IAM operations used by the controller. IAM implements it on top of an iamiface.IAMAPI client
 type IAMIface interface {
-	CreateRole(ctx context.Context, req IAMRoleRequest)
-	EnsureRole(ctx context.Context, req IAMRoleRequest)
-	UpdateRole(ctx context.Context, req IAMRoleRequest)
-	DeleteRole(ctx context.Context, roleName string)
-	GetRole(ctx context.Context, roleName string)
-	AttachInlineRolePolicy(ctx context.Context, req IAMRoleRequest)
+	EnsureRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleResponse, error)
+	CreateRole(ctx context.Context, req IAMRoleRequest) (*iam.CreateRoleOutput, error)
+	UpdateRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleResponse, error)
+	DeleteRole(ctx context.Context, roleName string) error
+	RetainRole(ctx context.Context, roleName string, removePolicies bool) error
+	GetRole(ctx context.Context, req IAMRoleRequest) (*iam.GetRoleOutput, error)
+	AttachInlineRolePolicy(ctx context.Context, req IAMRoleRequest) (*IAMRoleResponse, error)
 	AddPermissionBoundary(ctx context.Context, req IAMRoleRequest) error
-	GetRolePolicy(ctx context.Context, req IAMRoleRequest) bool
+	GetRolePolicy(ctx context.Context, req IAMRoleRequest) (*string, error)
+	GetRolePolicies(ctx context.Context, req IAMRoleRequest, policyNames []string) (map[string]string, error)
+	ListAttachedRolePolicies(ctx context.Context, roleName string) ([]string, error)
+	ListRoles(ctx context.Context, pathPrefix string) ([]*iam.Role, error)
+	ListRoleTags(ctx context.Context, roleName string) (map[string]string, error)
+	CreateOIDCProvider(ctx context.Context, url string, aud string, certThumpPrint string) error
 }
 
+var _ IAMIface = &IAM{}
+
 const (
 	RoleExistsAlreadyForOtherNamespace = "Please choose a different name"
+	RoleNotManagedByIamManager         = "Set spec.AdoptRoleARN to adopt it"
 )
 
+// OwnershipTagKeys are the tags which tell that iam-manager manages a role for a namespace and cluster
+var OwnershipTagKeys = []string{"managedBy", "Namespace", "Cluster"}
+
 // IAMRoleRequest struct
 type IAMRoleRequest struct {
 	Name                            string
 	PolicyName                      string
 	Description                     string
 	SessionDuration                 int64
+	Path                            string
 	TrustPolicy                     string
 	PermissionPolicy                string
 	ManagedPermissionBoundaryPolicy string
 	ManagedPolicies                 []string
 	Tags                            map[string]string
+	// PreviousManagedPolicies are the managed policies attached by iam-manager during earlier reconciles.
+	// Only these are detached when they are no longer part of ManagedPolicies
+	PreviousManagedPolicies []string
+	// InlinePolicies holds the additional inline policy documents keyed by policy name
+	InlinePolicies map[string]string
+	// PreviousInlinePolicies are the additional inline policies put by iam-manager during earlier reconciles.
+	// Only these are deleted when they are no longer part of InlinePolicies
+	PreviousInlinePolicies []string
+	// PreviousTags are the custom tag keys attached by iam-manager during earlier reconciles.
+	// Only these are removed when they are no longer part of Tags
+	PreviousTags []string
+	// AdoptRole allows taking over the role when it exists already but is not managed by iam-manager
+	// for the namespace and cluster of the request
+	AdoptRole bool
 }
 
 type IAMRoleResponse struct {
@@ -91,6 +122,7 @@ func NewIAM(region string, disallowSameAccountDynamoDBAccess bool) *IAM {
 	if err != nil {
 		panic(err)
 	}
+	InstrumentSession(sess)
 	return &IAM{
 		Client:                            iam.New(sess),
 		DisallowSameAccountDynamoDBAccess: disallowSameAccountDynamoDBAccess,
@@ -98,7 +130,9 @@ func NewIAM(region string, disallowSameAccountDynamoDBAccess bool) *IAM {
 }
 
 // EnsureRole ensures that a role exists, and that it has the appropriate configuration
-func (i *IAM) EnsureRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleResponse, error) {
+func (i *IAM) EnsureRole(ctx context.Context, req IAMRoleRequest) (_ *IAMRoleResponse, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.EnsureRole", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "EnsureRole")
 	log = log.WithValues("roleName", req.Name)
 
@@ -125,6 +159,14 @@ func (i *IAM) EnsureRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleRespo
 		return &IAMRoleResponse{}, err
 	}
 
+	//Remove stale tags
+	log.V(1).Info("Removing stale Tags")
+	err = i.UntagRole(ctx, req)
+
+	if err != nil {
+		return &IAMRoleResponse{}, err
+	}
+
 	//Add permission boundary
 	log.V(1).Info("Attaching Permission Boundary")
 	err = i.AddPermissionBoundary(ctx, req)
@@ -134,15 +176,11 @@ func (i *IAM) EnsureRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleRespo
 	}
 
 	//Attach managed role policy
-	log.V(1).Info("Attaching Managed policies")
-	for _, policy := range req.ManagedPolicies {
-		if policy != "" {
-			err = i.AttachManagedRolePolicy(ctx, policy, req.Name)
-			if err != nil {
-				log.Error(err, "Error while attaching managed policy", "policy", policy)
-				return &IAMRoleResponse{}, err
-			}
-		}
+	log.V(1).Info("Syncing Managed policies")
+	err = i.SyncManagedRolePolicies(ctx, req)
+
+	if err != nil {
+		return &IAMRoleResponse{}, err
 	}
 
 	log.V(1).Info("Attaching Inline role policies")
@@ -158,7 +196,9 @@ func (i *IAM) EnsureRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleRespo
 
 // GetOrCreateRole will try to create a new IAM Role in AWS. If it exists already, it will
 // use that role. In either case we return back an IAMRoleResponse{} object.
-func (i *IAM) GetOrCreateRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleResponse, error) {
+func (i *IAM) GetOrCreateRole(ctx context.Context, req IAMRoleRequest) (_ *IAMRoleResponse, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.GetOrCreateRole", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "GetOrCreateRole")
 	log = log.WithValues("roleName", req.Name)
 
@@ -175,6 +215,10 @@ func (i *IAM) GetOrCreateRole(ctx context.Context, req IAMRoleRequest) (*IAMRole
 		}
 	}
 	if getResp != nil {
+		// IAM does not allow moving an existing role to a different path
+		if req.Path != "" && aws.StringValue(getResp.Role.Path) != req.Path {
+			return nil, fmt.Errorf("role %s exists at path %s and cannot be moved to path %s", req.Name, aws.StringValue(getResp.Role.Path), req.Path)
+		}
 		return NewIAMRoleResponseFromGetRole(*getResp), nil
 	}
 
@@ -192,45 +236,54 @@ func (i *IAM) GetOrCreateRole(ctx context.Context, req IAMRoleRequest) (*IAMRole
 }
 
 // VerifyTags function verifies the tags attached to the role
-func (i *IAM) VerifyTags(ctx context.Context, req IAMRoleRequest) (*IAMRoleResponse, error) {
+func (i *IAM) VerifyTags(ctx context.Context, req IAMRoleRequest) (_ *IAMRoleResponse, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.VerifyTags", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "VerifyTags")
 	log = log.WithValues("roleName", req.Name)
 	log.V(1).Info("Initiating api call")
 
 	//Lets first list the tags and look for namespace and cluster tags
-	listTags, err := i.Client.ListRoleTags(&iam.ListRoleTagsInput{
-		RoleName: aws.String(req.Name),
-	})
-
+	tags, err := i.ListRoleTags(ctx, req.Name)
 	if err != nil {
 		return nil, err
 	}
 
+	managed := tags["managedBy"] == "iam-manager"
 	flag := false
-	for _, tag := range listTags.Tags {
-		if aws.StringValue(tag.Key) == "Namespace" {
-			if aws.StringValue(tag.Value) != req.Tags["Namespace"] {
-				flag = true
-				break
-			}
-		}
-		if aws.StringValue(tag.Key) == "Cluster" {
-			if aws.StringValue(tag.Value) != req.Tags["Cluster"] {
-				flag = true
-				break
-			}
-		}
+	otherCluster := false
+	if namespace, ok := tags["Namespace"]; ok && namespace != req.Tags["Namespace"] {
+		flag = true
+	}
+	if cluster, ok := tags["Cluster"]; ok && cluster != req.Tags["Cluster"] {
+		flag = true
+		otherCluster = true
+	}
+
+	// Adopted roles get the tags of the request, whoever owned them before in this cluster. A role iam-manager manages
+	// for another cluster is never adopted, or both clusters would keep taking it from each other
+	if req.AdoptRole && managed && otherCluster {
+		return nil, fmt.Errorf("role %s is managed by iam-manager of cluster %s and can't be adopted. %s", req.Name, tags["Cluster"], RoleExistsAlreadyForOtherNamespace)
+	}
+	if req.AdoptRole {
+		return &IAMRoleResponse{}, nil
 	}
 
 	if flag {
 		return nil, fmt.Errorf("role name %s in AWS is not available. %s", req.Name, RoleExistsAlreadyForOtherNamespace)
 	}
 
+	if !managed {
+		return nil, fmt.Errorf("role %s exists in AWS but is not managed by iam-manager. %s", req.Name, RoleNotManagedByIamManager)
+	}
+
 	return &IAMRoleResponse{}, nil
 }
 
 // TagRole tags role with appropriate tags
-func (i *IAM) TagRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleResponse, error) {
+func (i *IAM) TagRole(ctx context.Context, req IAMRoleRequest) (_ *IAMRoleResponse, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.TagRole", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "TagRole")
 	log = log.WithValues("roleName", req.Name)
 	log.V(1).Info("Initiating api call")
@@ -249,7 +302,9 @@ func (i *IAM) TagRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleResponse
 		Tags:     tags,
 	}
 
-	_, err := i.Client.TagRole(input)
+	before := i.auditRoleTags(ctx, req.Name)
+	_, err = i.Client.TagRole(input)
+	audit.Emit(ctx, audit.Record{Operation: "TagRole", RoleName: req.Name, Before: tagsDocument(before), After: taggedDocument(before, req.Tags)}, err)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
 			switch aerr.Code() {
@@ -277,8 +332,55 @@ func (i *IAM) TagRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleResponse
 	return &IAMRoleResponse{}, nil
 }
 
+// UntagRole removes the tags attached by iam-manager earlier which are no longer part of the request
+func (i *IAM) UntagRole(ctx context.Context, req IAMRoleRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.UntagRole", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
+	log := logging.Logger(ctx, "awsapi", "iam", "UntagRole")
+	log = log.WithValues("roleName", req.Name)
+
+	var tagKeys []*string
+	for _, key := range req.PreviousTags {
+		if _, ok := req.Tags[key]; ok {
+			continue
+		}
+		tagKeys = append(tagKeys, aws.String(key))
+	}
+	if len(tagKeys) == 0 {
+		return nil
+	}
+
+	log.V(1).Info("Initiating api call", "tagKeys", aws.StringValueSlice(tagKeys))
+	before := i.auditRoleTags(ctx, req.Name)
+	_, err = i.Client.UntagRole(&iam.UntagRoleInput{
+		RoleName: aws.String(req.Name),
+		TagKeys:  tagKeys,
+	})
+	audit.Emit(ctx, audit.Record{Operation: "UntagRole", RoleName: req.Name, Keys: aws.StringValueSlice(tagKeys), Before: tagsDocument(before), After: untaggedDocument(before, tagKeys)}, err)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeNoSuchEntityException:
+				log.Error(err, iam.ErrCodeNoSuchEntityException)
+			case iam.ErrCodeServiceFailureException:
+				log.Error(err, iam.ErrCodeServiceFailureException)
+			default:
+				log.Error(err, aerr.Error())
+			}
+		} else {
+			log.Error(err, err.Error())
+		}
+		return err
+	}
+
+	log.V(1).Info("Successfully completed UntagRole call")
+	return nil
+}
+
 // AddPermissionBoundary adds permission boundary to the existing roles
-func (i *IAM) AddPermissionBoundary(ctx context.Context, req IAMRoleRequest) error {
+func (i *IAM) AddPermissionBoundary(ctx context.Context, req IAMRoleRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.AddPermissionBoundary", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "AddPermissionBoundary")
 	log = log.WithValues("roleName", req.Name)
 	log.V(1).Info("Initiating api call")
@@ -292,7 +394,9 @@ func (i *IAM) AddPermissionBoundary(ctx context.Context, req IAMRoleRequest) err
 		return err
 	}
 
-	_, err := i.Client.PutRolePermissionsBoundary(input)
+	role := i.auditRole(ctx, req.Name)
+	_, err = i.Client.PutRolePermissionsBoundary(input)
+	audit.Emit(ctx, audit.Record{Operation: "PutRolePermissionsBoundary", RoleName: req.Name, Before: permissionsBoundaryOf(role), After: audit.JSON(req.ManagedPermissionBoundaryPolicy)}, err)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
 			switch aerr.Code() {
@@ -324,7 +428,9 @@ func (i *IAM) AddPermissionBoundary(ctx context.Context, req IAMRoleRequest) err
 }
 
 // UpdateRole updates role
-func (i *IAM) UpdateRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleResponse, error) {
+func (i *IAM) UpdateRole(ctx context.Context, req IAMRoleRequest) (_ *IAMRoleResponse, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.UpdateRole", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "UpdateRole")
 	log = log.WithValues("roleName", req.Name)
 	log.V(1).Info("initiating api call")
@@ -338,7 +444,9 @@ func (i *IAM) UpdateRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleRespo
 		log.Error(err, "input validation failed")
 		return nil, err
 	}
-	_, err := i.Client.UpdateRole(input)
+	role := i.auditRole(ctx, req.Name)
+	_, err = i.Client.UpdateRole(input)
+	audit.Emit(ctx, audit.Record{Operation: "UpdateRole", RoleName: req.Name, Before: roleSettingsOf(role), After: roleSettings(req.Description, req.SessionDuration)}, err)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
 			switch aerr.Code() {
@@ -374,6 +482,7 @@ func (i *IAM) UpdateRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleRespo
 	}
 
 	_, err = i.Client.UpdateAssumeRolePolicy(inputPolicy)
+	audit.Emit(ctx, audit.Record{Operation: "UpdateAssumeRolePolicy", RoleName: req.Name, Before: trustPolicyDocument(role), After: audit.Document(req.TrustPolicy)}, err)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
 			switch aerr.Code() {
@@ -401,7 +510,15 @@ func (i *IAM) UpdateRole(ctx context.Context, req IAMRoleRequest) (*IAMRoleRespo
 		return nil, err
 	}
 
-	return i.AttachInlineRolePolicy(ctx, req)
+	resp, err := i.AttachInlineRolePolicy(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = i.SyncInlineRolePolicies(ctx, req); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 // getActions extracts actions from the statement entry
@@ -428,8 +545,11 @@ func getActions(action interface{}) []string {
 //
 //  2. If new policy has DynamoDB access to the IKS AWS account DynamoDB table, but existing policy doesn't
 //     have DynamoDB access to the IKS AWS account DynamoDB table, return error
-func (i *IAM) ValidateAllowSameAccountDynamoDBAccess(ctx context.Context, req IAMRoleRequest) error {
+func (i *IAM) ValidateAllowSameAccountDynamoDBAccess(ctx context.Context, req IAMRoleRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.ValidateAllowSameAccountDynamoDBAccess", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "ValidateAllowSameAccountDynamoDBAccess")
+	log = log.WithValues("roleName", req.Name)
 
 	if !i.DisallowSameAccountDynamoDBAccess {
 		log.V(1).Info("DisallowSameAccountDynamoDBAccess is disabled, allowing same-account DynamoDB access")
@@ -474,7 +594,9 @@ func (i *IAM) ValidateAllowSameAccountDynamoDBAccess(ctx context.Context, req IA
 		return nil
 	}
 
-	if HasDynamoDBAccessByAccount(req.PermissionPolicy, awsAccount) {
+	if HasDynamoDBAccessByAccount(req.PermissionPolicy, awsAccount) || slices.ContainsFunc(slices.Collect(maps.Values(req.InlinePolicies)), func(policy string) bool {
+		return HasDynamoDBAccessByAccount(policy, awsAccount)
+	}) {
 		// Validate new input of custom inline policy
 		log.V(1).Error(fmt.Errorf("validation error"), "existing policy doesn't have DynamoDB access to the same AWS account, and new permission policy has DynamoDB access to the same AWS account",
 			"existingPolicy", existingPolicyDoc, "newPolicy", req.PermissionPolicy)
@@ -495,6 +617,9 @@ func HasDynamoDBAccessByAccount(policy string, accountID string) bool {
 
 	for _, statement := range policyDoc.Statement {
 		if statement.Effect == "Allow" {
+			if statementGrantsDynamoDBByExclusion(statement, accountID) {
+				return true
+			}
 			actions := getActions(statement.Action)
 			for _, action := range actions {
 				if strings.HasPrefix(action, "dynamodb:") {
@@ -521,12 +646,40 @@ func HasDynamoDBAccessByAccount(policy string, accountID string) bool {
 	return false
 }
 
+// statementGrantsDynamoDBByExclusion treats NotAction/NotResource conservatively: a NotAction which doesn't exclude
+// every DynamoDB action grants DynamoDB access and a NotResource which doesn't exclude "*" covers every table
+func statementGrantsDynamoDBByExclusion(statement StatementEntry, accountID string) bool {
+	if statement.NotAction == nil && statement.NotResource == nil {
+		return false
+	}
+	var grantsDynamoDB bool
+	if statement.NotAction != nil {
+		grantsDynamoDB = !slices.ContainsFunc(getActions(statement.NotAction), func(action string) bool {
+			return action == "*" || action == "dynamodb:*"
+		})
+	} else {
+		grantsDynamoDB = slices.ContainsFunc(getActions(statement.Action), func(action string) bool {
+			return strings.HasPrefix(action, "dynamodb:")
+		})
+	}
+	if !grantsDynamoDB {
+		return false
+	}
+	if statement.NotResource != nil {
+		return !slices.Contains(getActions(statement.NotResource), "*")
+	}
+	return slices.ContainsFunc(getActions(statement.Resource), func(resource string) bool {
+		return resourceHasDynamoDBAccessByAccount(resource, accountID)
+	})
+}
+
 func resourceHasDynamoDBAccessByAccount(resourceStr, accountID string) bool {
 	return strings.Contains(resourceStr, fmt.Sprintf(":%s:table/", accountID)) || strings.Contains(resourceStr, "*:table/") || resourceStr == "*"
 }
 
 func ExtractRoleAwsAccount(ctx context.Context, role *iam.GetRoleOutput) (string, error) {
-	log := logging.Logger(ctx, "awsapi", "iam", "UpdateRole")
+	log := logging.Logger(ctx, "awsapi", "iam", "ExtractRoleAwsAccount")
+	log = log.WithValues("roleName", aws.StringValue(role.Role.RoleName))
 
 	arn := role.Role.Arn
 	// Extract AWS account ID from ARN
@@ -542,7 +695,9 @@ func ExtractRoleAwsAccount(ctx context.Context, role *iam.GetRoleOutput) (string
 }
 
 // AttachInlineRolePolicy function attaches inline policy to the role
-func (i *IAM) AttachInlineRolePolicy(ctx context.Context, req IAMRoleRequest) (*IAMRoleResponse, error) {
+func (i *IAM) AttachInlineRolePolicy(ctx context.Context, req IAMRoleRequest) (_ *IAMRoleResponse, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.AttachInlineRolePolicy", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "AttachInlineRolePolicy")
 	log = log.WithValues("roleName", req.Name)
 	log.V(1).Info("Initiating api call")
@@ -556,7 +711,9 @@ func (i *IAM) AttachInlineRolePolicy(ctx context.Context, req IAMRoleRequest) (*
 		log.Error(err, "input validation failed")
 		return nil, err
 	}
-	_, err := i.Client.PutRolePolicy(input)
+	before := i.auditRolePolicy(ctx, req.Name, req.PolicyName)
+	_, err = i.Client.PutRolePolicy(input)
+	audit.Emit(ctx, audit.Record{Operation: "PutRolePolicy", RoleName: req.Name, Policy: req.PolicyName, Before: before, After: audit.Document(req.PermissionPolicy)}, err)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
 			switch aerr.Code() {
@@ -580,8 +737,41 @@ func (i *IAM) AttachInlineRolePolicy(ctx context.Context, req IAMRoleRequest) (*
 	return &IAMRoleResponse{}, nil
 }
 
+// SyncInlineRolePolicies puts every additional inline policy on the role and deletes the ones iam-manager
+// put earlier which are no longer desired
+func (i *IAM) SyncInlineRolePolicies(ctx context.Context, req IAMRoleRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.SyncInlineRolePolicies", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
+	log := logging.Logger(ctx, "awsapi", "iam", "SyncInlineRolePolicies")
+	log = log.WithValues("roleName", req.Name)
+
+	for _, policyName := range slices.Sorted(maps.Keys(req.InlinePolicies)) {
+		policyReq := req
+		policyReq.PolicyName = policyName
+		policyReq.PermissionPolicy = req.InlinePolicies[policyName]
+		if _, err := i.AttachInlineRolePolicy(ctx, policyReq); err != nil {
+			log.Error(err, "Error while putting inline policy", "policyName", policyName)
+			return err
+		}
+	}
+
+	for _, policyName := range req.PreviousInlinePolicies {
+		if _, ok := req.InlinePolicies[policyName]; ok || policyName == req.PolicyName {
+			continue
+		}
+		log.Info("Deleting inline policy which is no longer desired", "policyName", policyName)
+		if err := i.DeleteInlinePolicy(ctx, policyName, req.Name); err != nil {
+			log.Error(err, "Error while deleting inline policy", "policyName", policyName)
+			return err
+		}
+	}
+	return nil
+}
+
 // CreateRole will try to create an IAM Role, or return back Nil if it can not be created
-func (i *IAM) CreateRole(ctx context.Context, req IAMRoleRequest) (*iam.CreateRoleOutput, error) {
+func (i *IAM) CreateRole(ctx context.Context, req IAMRoleRequest) (_ *iam.CreateRoleOutput, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.CreateRole", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "CreateRole")
 	log = log.WithValues("roleName", req.Name)
 
@@ -592,6 +782,13 @@ func (i *IAM) CreateRole(ctx context.Context, req IAMRoleRequest) (*iam.CreateRo
 		MaxSessionDuration:       aws.Int64(req.SessionDuration),
 		PermissionsBoundary:      aws.String(req.ManagedPermissionBoundaryPolicy),
 	}
+	if req.Path != "" {
+		input.Path = aws.String(req.Path)
+	}
+	// Tagging on creation makes sure a role created by iam-manager is never mistaken for a pre-existing one
+	for _, key := range slices.Sorted(maps.Keys(req.Tags)) {
+		input.Tags = append(input.Tags, &iam.Tag{Key: aws.String(key), Value: aws.String(req.Tags[key])})
+	}
 
 	if err := input.Validate(); err != nil {
 		log.Error(err, "input validation failed")
@@ -601,6 +798,7 @@ func (i *IAM) CreateRole(ctx context.Context, req IAMRoleRequest) (*iam.CreateRo
 	// If the role already exists - we'll figure that out based on the error that is returned by AWS.
 	log.V(1).Info("Initiating api call")
 	resp, err := i.Client.CreateRole(input)
+	audit.Emit(ctx, audit.Record{Operation: "CreateRole", RoleName: req.Name, After: audit.Document(req.TrustPolicy)}, err)
 
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
@@ -627,7 +825,9 @@ func (i *IAM) CreateRole(ctx context.Context, req IAMRoleRequest) (*iam.CreateRo
 }
 
 // GetRole gets the role from aws iam
-func (i *IAM) GetRole(ctx context.Context, req IAMRoleRequest) (*iam.GetRoleOutput, error) {
+func (i *IAM) GetRole(ctx context.Context, req IAMRoleRequest) (_ *iam.GetRoleOutput, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.GetRole", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "GetRole")
 	log = log.WithValues("roleName", req.Name)
 	log.V(1).Info("Initiating api call")
@@ -652,7 +852,9 @@ func (i *IAM) GetRole(ctx context.Context, req IAMRoleRequest) (*iam.GetRoleOutp
 }
 
 // GetRolePolicy gets the role from aws iam
-func (i *IAM) GetRolePolicy(ctx context.Context, req IAMRoleRequest) (*string, error) {
+func (i *IAM) GetRolePolicy(ctx context.Context, req IAMRoleRequest) (_ *string, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.GetRolePolicy", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "GetRolePolicy")
 	log = log.WithValues("roleName", req.Name)
 	log.V(1).Info("Initiating api call")
@@ -689,16 +891,43 @@ func (i *IAM) GetRolePolicy(ctx context.Context, req IAMRoleRequest) (*string, e
 	return resp.PolicyDocument, nil
 }
 
+// GetRolePolicies gets the inline policy documents of the role for the given policy names.
+// Policies which don't exist in AWS are left out of the result
+func (i *IAM) GetRolePolicies(ctx context.Context, req IAMRoleRequest, policyNames []string) (_ map[string]string, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.GetRolePolicies", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
+	policies := map[string]string{}
+	for _, policyName := range policyNames {
+		if _, ok := policies[policyName]; ok {
+			continue
+		}
+		policyReq := req
+		policyReq.PolicyName = policyName
+		policy, err := i.GetRolePolicy(ctx, policyReq)
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == iam.ErrCodeNoSuchEntityException {
+				continue
+			}
+			return nil, err
+		}
+		policies[policyName] = aws.StringValue(policy)
+	}
+	return policies, nil
+}
+
 // AttachManagedRolePolicy function attaches managed policy to the role
-func (i *IAM) AttachManagedRolePolicy(ctx context.Context, policyArn string, roleName string) error {
+func (i *IAM) AttachManagedRolePolicy(ctx context.Context, policyArn string, roleName string) (err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.AttachManagedRolePolicy", tracing.RoleNameKey.String(roleName))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "AttachManagedRolePolicy")
 	log = log.WithValues("roleName", roleName, "policyName", policyArn)
 	log.V(1).Info("Initiating api call")
 
-	_, err := i.Client.AttachRolePolicy(&iam.AttachRolePolicyInput{
+	_, err = i.Client.AttachRolePolicy(&iam.AttachRolePolicyInput{
 		RoleName:  aws.String(roleName),
 		PolicyArn: aws.String(policyArn),
 	})
+	audit.Emit(ctx, audit.Record{Operation: "AttachRolePolicy", RoleName: roleName, Policy: policyArn}, err)
 
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
@@ -725,39 +954,229 @@ func (i *IAM) AttachManagedRolePolicy(ctx context.Context, policyArn string, rol
 	return nil
 }
 
-// DeleteRole function deletes the role in the account
-func (i *IAM) DeleteRole(ctx context.Context, roleName string) error {
-	log := logging.Logger(ctx, "awsapi", "iam", "DeleteRole")
+// ListAttachedRolePolicies lists the ARNs of the managed policies attached to the role
+func (i *IAM) ListAttachedRolePolicies(ctx context.Context, roleName string) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.ListAttachedRolePolicies", tracing.RoleNameKey.String(roleName))
+	defer func() { tracing.End(span, err) }()
+	log := logging.Logger(ctx, "awsapi", "iam", "ListAttachedRolePolicies")
 	log = log.WithValues("roleName", roleName)
 	log.V(1).Info("Initiating api call")
 
-	//Check if role exists
+	var policyArns []string
+	err = i.Client.ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{
+		RoleName: aws.String(roleName),
+	}, func(page *iam.ListAttachedRolePoliciesOutput, lastPage bool) bool {
+		for _, policy := range page.AttachedPolicies {
+			policyArns = append(policyArns, aws.StringValue(policy.PolicyArn))
+		}
+		return true
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeNoSuchEntityException:
+				log.Error(err, iam.ErrCodeNoSuchEntityException)
+			case iam.ErrCodeInvalidInputException:
+				log.Error(err, iam.ErrCodeInvalidInputException)
+			case iam.ErrCodeServiceFailureException:
+				log.Error(err, iam.ErrCodeServiceFailureException)
+			default:
+				log.Error(err, aerr.Error())
+			}
+		}
+		return nil, err
+	}
+	log.V(1).Info("Successfully listed attached policies", "policyList", policyArns)
+	return policyArns, nil
+}
+
+// ListRoles lists every role under the path prefix, following the pagination markers
+func (i *IAM) ListRoles(ctx context.Context, pathPrefix string) (_ []*iam.Role, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.ListRoles")
+	defer func() { tracing.End(span, err) }()
+	log := logging.Logger(ctx, "awsapi", "iam", "ListRoles")
+	log = log.WithValues("pathPrefix", pathPrefix)
+	log.V(1).Info("Initiating api call")
 
-	managedPolicyList, err := i.Client.ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{
+	input := &iam.ListRolesInput{}
+	if pathPrefix != "" {
+		input.PathPrefix = aws.String(pathPrefix)
+	}
+	var roles []*iam.Role
+	err = i.Client.ListRolesPages(input, func(page *iam.ListRolesOutput, lastPage bool) bool {
+		roles = append(roles, page.Roles...)
+		return true
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeServiceFailureException:
+				log.Error(err, iam.ErrCodeServiceFailureException)
+			default:
+				log.Error(err, aerr.Error())
+			}
+		} else {
+			log.Error(err, err.Error())
+		}
+		return nil, err
+	}
+	log.V(1).Info("Successfully listed roles", "count", len(roles))
+	return roles, nil
+}
+
+// ListRoleTags returns the tags of the role, following the pagination markers
+func (i *IAM) ListRoleTags(ctx context.Context, roleName string) (_ map[string]string, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.ListRoleTags", tracing.RoleNameKey.String(roleName))
+	defer func() { tracing.End(span, err) }()
+	log := logging.Logger(ctx, "awsapi", "iam", "ListRoleTags")
+	log = log.WithValues("roleName", roleName)
+	log.V(1).Info("Initiating api call")
+
+	tags := map[string]string{}
+	err = i.Client.ListRoleTagsPages(&iam.ListRoleTagsInput{
 		RoleName: aws.String(roleName),
+	}, func(page *iam.ListRoleTagsOutput, lastPage bool) bool {
+		for _, tag := range page.Tags {
+			tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+		return true
 	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeNoSuchEntityException:
+				log.Error(err, iam.ErrCodeNoSuchEntityException)
+			case iam.ErrCodeServiceFailureException:
+				log.Error(err, iam.ErrCodeServiceFailureException)
+			default:
+				log.Error(err, aerr.Error())
+			}
+		} else {
+			log.Error(err, err.Error())
+		}
+		return nil, err
+	}
+	log.V(1).Info("Successfully listed role tags")
+	return tags, nil
+}
 
+// SyncManagedRolePolicies attaches the desired managed policies which are missing on the role and detaches
+// the ones iam-manager attached earlier which are no longer desired. Policies attached outside of iam-manager
+// and the permission boundary are left alone
+func (i *IAM) SyncManagedRolePolicies(ctx context.Context, req IAMRoleRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.SyncManagedRolePolicies", tracing.RoleNameKey.String(req.Name))
+	defer func() { tracing.End(span, err) }()
+	log := logging.Logger(ctx, "awsapi", "iam", "SyncManagedRolePolicies")
+	log = log.WithValues("roleName", req.Name)
+
+	attached, err := i.ListAttachedRolePolicies(ctx, req.Name)
 	if err != nil {
+		return err
+	}
+
+	for _, policy := range req.ManagedPolicies {
+		if policy == "" || slices.Contains(attached, policy) {
+			continue
+		}
+		if err := i.AttachManagedRolePolicy(ctx, policy, req.Name); err != nil {
+			log.Error(err, "Error while attaching managed policy", "policy", policy)
+			return err
+		}
+	}
+
+	for _, policy := range attached {
+		if slices.Contains(req.ManagedPolicies, policy) || !slices.Contains(req.PreviousManagedPolicies, policy) || policy == req.ManagedPermissionBoundaryPolicy {
+			continue
+		}
+		log.Info("Detaching managed policy which is no longer desired", "policy", policy)
+		if err := i.DetachRolePolicy(ctx, policy, req.Name); err != nil {
+			log.Error(err, "Error while detaching managed policy", "policy", policy)
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteRole function deletes the role in the account
+func (i *IAM) DeleteRole(ctx context.Context, roleName string) (err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.DeleteRole", tracing.RoleNameKey.String(roleName))
+	defer func() { tracing.End(span, err) }()
+	log := logging.Logger(ctx, "awsapi", "iam", "DeleteRole")
+	log = log.WithValues("roleName", roleName)
+	log.V(1).Info("Initiating api call")
+
+	//Check if role exists
+	if err := i.RemoveRolePolicies(ctx, roleName); err != nil {
 		if strings.Contains(err.Error(), "NoSuchEntity") {
 			log.Info("Role doesn't exist in the target account", "role_name", roleName)
 			return nil
-		} else {
-			log.Error(err, "Unable to list attached managed policies for role")
-			return err
 		}
+		return err
 	}
-	log.V(1).Info("listing attached policies", "policyList", managedPolicyList.AttachedPolicies)
+
+	input := &iam.DeleteRoleInput{
+		RoleName: aws.String(roleName),
+	}
+
+	role := i.auditRole(ctx, roleName)
+	_, err = i.Client.DeleteRole(input)
+	audit.Emit(ctx, audit.Record{Operation: "DeleteRole", RoleName: roleName, Before: trustPolicyDocument(role)}, err)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case iam.ErrCodeLimitExceededException:
+				log.Error(err, iam.ErrCodeLimitExceededException)
+			case iam.ErrCodeNoSuchEntityException:
+				//This is ok
+				err = nil
+				log.V(1).Info(iam.ErrCodeNoSuchEntityException)
+			case iam.ErrCodeServiceFailureException:
+				log.Error(err, iam.ErrCodeServiceFailureException)
+			default:
+				log.Error(err, aerr.Error())
+			}
+		}
+		return err
+	}
+	log.V(1).Info("Successfully deleted the role")
+	return nil
+}
+
+// RemoveRolePolicies detaches every managed policy and deletes every inline policy of the role
+func (i *IAM) RemoveRolePolicies(ctx context.Context, roleName string) (err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.RemoveRolePolicies", tracing.RoleNameKey.String(roleName))
+	defer func() { tracing.End(span, err) }()
+	log := logging.Logger(ctx, "awsapi", "iam", "RemoveRolePolicies")
+	log = log.WithValues("roleName", roleName)
+
+	// Collect every page first, detaching while paginating would shift the markers
+	var managedPolicies []*iam.AttachedPolicy
+	err = i.Client.ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{
+		RoleName: aws.String(roleName),
+	}, func(page *iam.ListAttachedRolePoliciesOutput, lastPage bool) bool {
+		managedPolicies = append(managedPolicies, page.AttachedPolicies...)
+		return true
+	})
+	if err != nil {
+		log.Error(err, "Unable to list attached managed policies for role")
+		return err
+	}
+	log.V(1).Info("listing attached policies", "policyList", managedPolicies)
 
 	// Detach managed policies
-	for _, policy := range managedPolicyList.AttachedPolicies {
+	for _, policy := range managedPolicies {
 		if err := i.DetachRolePolicy(ctx, aws.StringValue(policy.PolicyArn), roleName); err != nil {
 			log.Error(err, "Unable to delete the policy", "policyName", aws.StringValue(policy.PolicyName))
 			return err
 		}
 	}
 
-	inlinePolicies, err := i.Client.ListRolePolicies(&iam.ListRolePoliciesInput{
+	var inlinePolicies []*string
+	err = i.Client.ListRolePoliciesPages(&iam.ListRolePoliciesInput{
 		RoleName: aws.String(roleName),
+	}, func(page *iam.ListRolePoliciesOutput, lastPage bool) bool {
+		inlinePolicies = append(inlinePolicies, page.PolicyNames...)
+		return true
 	})
 	if err != nil {
 		log.Error(err, "Unable to list inline policies for role")
@@ -765,41 +1184,65 @@ func (i *IAM) DeleteRole(ctx context.Context, roleName string) error {
 	}
 
 	// Delete inline policies
-	for _, inlinePolicy := range inlinePolicies.PolicyNames {
+	for _, inlinePolicy := range inlinePolicies {
 		if err := i.DeleteInlinePolicy(ctx, aws.StringValue(inlinePolicy), roleName); err != nil {
 			log.Error(err, "Unable to delete the policy", "policyName", aws.StringValue(inlinePolicy))
 			return err
 		}
 	}
+	return nil
+}
 
-	input := &iam.DeleteRoleInput{
-		RoleName: aws.String(roleName),
+// RetainRole keeps the role in AWS but removes the ownership tags, so that iam-manager no longer manages it
+// and it can be adopted again later. With removePolicies, the inline and managed policies are removed as well
+func (i *IAM) RetainRole(ctx context.Context, roleName string, removePolicies bool) (err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.RetainRole", tracing.RoleNameKey.String(roleName))
+	defer func() { tracing.End(span, err) }()
+	log := logging.Logger(ctx, "awsapi", "iam", "RetainRole")
+	log = log.WithValues("roleName", roleName)
+	log.V(1).Info("Initiating api call", "removePolicies", removePolicies)
+
+	if removePolicies {
+		if err := i.RemoveRolePolicies(ctx, roleName); err != nil {
+			if strings.Contains(err.Error(), "NoSuchEntity") {
+				log.Info("Role doesn't exist in the target account", "role_name", roleName)
+				return nil
+			}
+			return err
+		}
 	}
 
-	_, err = i.Client.DeleteRole(input)
+	before := i.auditRoleTags(ctx, roleName)
+	_, err = i.Client.UntagRole(&iam.UntagRoleInput{
+		RoleName: aws.String(roleName),
+		TagKeys:  aws.StringSlice(OwnershipTagKeys),
+	})
+	audit.Emit(ctx, audit.Record{Operation: "UntagRole", RoleName: roleName, Keys: OwnershipTagKeys, Before: tagsDocument(before), After: untaggedDocument(before, aws.StringSlice(OwnershipTagKeys))}, err)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
 			switch aerr.Code() {
-			case iam.ErrCodeLimitExceededException:
-				log.Error(err, iam.ErrCodeLimitExceededException)
 			case iam.ErrCodeNoSuchEntityException:
 				//This is ok
-				err = nil
 				log.V(1).Info(iam.ErrCodeNoSuchEntityException)
+				return nil
 			case iam.ErrCodeServiceFailureException:
 				log.Error(err, iam.ErrCodeServiceFailureException)
 			default:
 				log.Error(err, aerr.Error())
 			}
+		} else {
+			log.Error(err, err.Error())
 		}
 		return err
 	}
-	log.V(1).Info("Successfully deleted the role")
+	log.V(1).Info("Successfully retained the role")
 	return nil
 }
 
 // DeleteInlinePolicy function deletes inline policy
-func (i *IAM) DeleteInlinePolicy(ctx context.Context, policyName string, roleName string) error {
+func (i *IAM) DeleteInlinePolicy(ctx context.Context, policyName string, roleName string) (err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.DeleteInlinePolicy", tracing.RoleNameKey.String(roleName))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "DeleteInlinePolicy")
 	log = log.WithValues("roleName", roleName, "policyName", policyName)
 	log.V(1).Info("Initiating api call")
@@ -809,7 +1252,9 @@ func (i *IAM) DeleteInlinePolicy(ctx context.Context, policyName string, roleNam
 		RoleName:   aws.String(roleName),
 	}
 
-	_, err := i.Client.DeleteRolePolicy(input)
+	before := i.auditRolePolicy(ctx, roleName, policyName)
+	_, err = i.Client.DeleteRolePolicy(input)
+	audit.Emit(ctx, audit.Record{Operation: "DeleteRolePolicy", RoleName: roleName, Policy: policyName, Before: before}, err)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
 			switch aerr.Code() {
@@ -839,15 +1284,18 @@ func (i *IAM) DeleteInlinePolicy(ctx context.Context, policyName string, roleNam
 }
 
 // DetachRolePolicy detaches a policy from role
-func (i *IAM) DetachRolePolicy(ctx context.Context, policyArn string, roleName string) error {
+func (i *IAM) DetachRolePolicy(ctx context.Context, policyArn string, roleName string) (err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.DetachRolePolicy", tracing.RoleNameKey.String(roleName))
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi", "iam", "DetachRolePolicy")
 	log = log.WithValues("roleName", roleName, "policyArn", policyArn)
 	log.V(1).Info("Initiating api call")
 
-	_, err := i.Client.DetachRolePolicy(&iam.DetachRolePolicyInput{
+	_, err = i.Client.DetachRolePolicy(&iam.DetachRolePolicyInput{
 		PolicyArn: aws.String(policyArn),
 		RoleName:  aws.String(roleName),
 	})
+	audit.Emit(ctx, audit.Record{Operation: "DetachRolePolicy", RoleName: roleName, Policy: policyArn}, err)
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
 			switch aerr.Code() {
@@ -877,7 +1325,9 @@ func (i *IAM) DetachRolePolicy(ctx context.Context, policyArn string, roleName s
 }
 
 // CreateOIDCProvider creates OIDC IDP provider with AWS IAM
-func (i *IAM) CreateOIDCProvider(ctx context.Context, url string, aud string, certThumpPrint string) error {
+func (i *IAM) CreateOIDCProvider(ctx context.Context, url string, aud string, certThumpPrint string) (err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.CreateOIDCProvider")
+	defer func() { tracing.End(span, err) }()
 	log := logging.Logger(ctx, "awsapi.iam", "CreateOIDCProvider")
 	log = log.WithValues("url", url, "aud", aud)
 	log.V(1).Info("Creating OIDC Provider with AWS IAM")
@@ -889,6 +1339,7 @@ func (i *IAM) CreateOIDCProvider(ctx context.Context, url string, aud string, ce
 	}
 
 	result, err := i.Client.CreateOpenIDConnectProvider(input)
+	audit.Emit(ctx, audit.Record{Operation: "CreateOpenIDConnectProvider", After: audit.JSON(input)}, err)
 	idpAlreadyExists := false
 	if err != nil {
 		if aerr, ok := err.(awserr.Error); ok {
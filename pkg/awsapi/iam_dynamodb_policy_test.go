@@ -237,3 +237,39 @@ func (s *IAMAPISuite) TestValidateAllowedDynamoDBAccessFeatureFlagDisabled(c *ch
 	err := s.mockIAM.ValidateAllowSameAccountDynamoDBAccess(s.ctx, req)
 	c.Assert(err, check.IsNil)
 }
+
+func (s *IAMAPISuite) TestHasDynamoDBAccessByAccountNotAction(c *check.C) {
+	// NotAction which doesn't exclude DynamoDB grants every DynamoDB action
+	policy := `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "NotAction": ["iam:*"], "Resource": "*"}]}`
+	c.Assert(awsapi.HasDynamoDBAccessByAccount(policy, "123456789012"), check.Equals, true)
+
+	policy = `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "NotAction": ["iam:*", "dynamodb:*"], "Resource": "*"}]}`
+	c.Assert(awsapi.HasDynamoDBAccessByAccount(policy, "123456789012"), check.Equals, false)
+}
+
+func (s *IAMAPISuite) TestHasDynamoDBAccessByAccountNotResource(c *check.C) {
+	// NotResource matches every table outside of the list
+	policy := `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": ["dynamodb:GetItem"], "NotResource": ["arn:aws:dynamodb:us-west-2:123456789012:table/Secret"]}]}`
+	c.Assert(awsapi.HasDynamoDBAccessByAccount(policy, "123456789012"), check.Equals, true)
+
+	policy = `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": ["s3:GetObject"], "NotResource": ["arn:aws:s3:::secret/*"]}]}`
+	c.Assert(awsapi.HasDynamoDBAccessByAccount(policy, "123456789012"), check.Equals, false)
+}
+
+func (s *IAMAPISuite) TestValidateAllowedDynamoDBAccessNewInlinePolicyHasAccess(c *check.C) {
+	s.mockIAM.DisallowSameAccountDynamoDBAccess = true
+	s.mockI.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.GetRoleOutput{
+		Role: &iam.Role{
+			Arn: aws.String("arn:aws:iam::123456789012:role/VALID_ROLE"),
+		},
+	}, nil)
+	s.mockI.EXPECT().GetRolePolicy(&iam.GetRolePolicyInput{RoleName: aws.String("VALID_ROLE"), PolicyName: aws.String("VALID_POLICY")}).Times(1).Return(&iam.GetRolePolicyOutput{
+		PolicyDocument: aws.String(url.QueryEscape(`{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "*"}]}`)),
+	}, nil)
+
+	// the same account DynamoDB access can't be smuggled through an additional inline policy
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", PolicyName: "VALID_POLICY", PermissionPolicy: `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "s3:*", "Resource": "*"}]}`,
+		InlinePolicies: map[string]string{"data": `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "dynamodb:*", "Resource": "*"}]}`}}
+	err := s.mockIAM.ValidateAllowSameAccountDynamoDBAccess(s.ctx, req)
+	c.Assert(err, check.NotNil)
+}
@@ -44,19 +44,31 @@ func (s *IAMAPISuite) TearDownTest(c *check.C) {
 	s.mockCtrl.Finish()
 }
 
+// listPages returns a gomock action for a List*Pages call which hands the pages to the callback until it stops
+func listPages[I, O any](pages ...O) func(I, func(O, bool) bool) error {
+	return func(_ I, fn func(O, bool) bool) error {
+		for i, page := range pages {
+			if !fn(page, i == len(pages)-1) {
+				break
+			}
+		}
+		return nil
+	}
+}
+
 //############
 
 func (s *IAMAPISuite) TestEnsureRoleSuccess(c *check.C) {
 	s.mockI.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "", errors.New(iam.ErrCodeNoSuchEntityException)))
-	s.mockI.EXPECT().CreateRole(&iam.CreateRoleInput{RoleName: aws.String("VALID_ROLE"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy()), MaxSessionDuration: aws.Int64(3600), AssumeRolePolicyDocument: aws.String("SOMETHING"), Description: aws.String("")}).Times(1).Return(&iam.CreateRoleOutput{Role: &iam.Role{RoleId: aws.String("ABCDE1234"), Arn: aws.String("arn:aws:iam::123456789012:role/VALID_ROLE")}}, nil)
-	s.mockI.EXPECT().ListRoleTags(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.ListRoleTagsOutput{
+	s.mockI.EXPECT().CreateRole(&iam.CreateRoleInput{RoleName: aws.String("VALID_ROLE"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy()), MaxSessionDuration: aws.Int64(3600), AssumeRolePolicyDocument: aws.String("SOMETHING"), Description: aws.String(""), Tags: []*iam.Tag{{Key: aws.String("managedBy"), Value: aws.String("iam-manager")}}}).Times(1).Return(&iam.CreateRoleOutput{Role: &iam.Role{RoleId: aws.String("ABCDE1234"), Arn: aws.String("arn:aws:iam::123456789012:role/VALID_ROLE")}}, nil)
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](&iam.ListRoleTagsOutput{
 		Tags: []*iam.Tag{
 			{
 				Key:   aws.String("managedBy"),
 				Value: aws.String("iam-manager"),
 			},
 		},
-	}, nil)
+	}))
 	s.mockI.EXPECT().TagRole(&iam.TagRoleInput{RoleName: aws.String("VALID_ROLE"), Tags: []*iam.Tag{
 		{
 			Key:   aws.String("managedBy"),
@@ -65,6 +77,7 @@ func (s *IAMAPISuite) TestEnsureRoleSuccess(c *check.C) {
 	}}).Times(1).Return(&iam.TagRoleOutput{}, nil)
 	s.mockI.EXPECT().PutRolePermissionsBoundary(&iam.PutRolePermissionsBoundaryInput{RoleName: aws.String("VALID_ROLE"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy())}).Times(1).Return(nil, nil)
 	s.mockI.EXPECT().PutRolePolicy(&iam.PutRolePolicyInput{PolicyDocument: aws.String("SOMETHING"), RoleName: aws.String("VALID_ROLE"), PolicyName: aws.String("VALID_POLICY")}).Times(1).Return(&iam.PutRolePolicyOutput{}, nil)
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{}))
 	s.mockI.EXPECT().AttachRolePolicy(&iam.AttachRolePolicyInput{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/SOMETHING"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.AttachRolePolicyOutput{}, nil)
 	s.mockI.EXPECT().UpdateRole(&iam.UpdateRoleInput{RoleName: aws.String("VALID_ROLE"), MaxSessionDuration: aws.Int64(3600), Description: aws.String("")}).Times(1).Return(&iam.UpdateRoleOutput{}, nil)
 	s.mockI.EXPECT().UpdateAssumeRolePolicy(&iam.UpdateAssumeRolePolicyInput{RoleName: aws.String("VALID_ROLE"), PolicyDocument: aws.String("SOMETHING")}).Times(1).Return(&iam.UpdateAssumeRolePolicyOutput{}, nil)
@@ -90,15 +103,15 @@ func (s *IAMAPISuite) TestEnsureRoleSuccess(c *check.C) {
 
 func (s *IAMAPISuite) TestEnsureRoleSuccessWithNoManagedPolicies(c *check.C) {
 	s.mockI.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "", errors.New(iam.ErrCodeNoSuchEntityException)))
-	s.mockI.EXPECT().CreateRole(&iam.CreateRoleInput{RoleName: aws.String("VALID_ROLE"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy()), MaxSessionDuration: aws.Int64(3600), AssumeRolePolicyDocument: aws.String("SOMETHING"), Description: aws.String("")}).Times(1).Return(&iam.CreateRoleOutput{Role: &iam.Role{RoleId: aws.String("ABCDE1234"), Arn: aws.String("arn:aws:iam::123456789012:role/VALID_ROLE")}}, nil)
-	s.mockI.EXPECT().ListRoleTags(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.ListRoleTagsOutput{
+	s.mockI.EXPECT().CreateRole(&iam.CreateRoleInput{RoleName: aws.String("VALID_ROLE"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy()), MaxSessionDuration: aws.Int64(3600), AssumeRolePolicyDocument: aws.String("SOMETHING"), Description: aws.String(""), Tags: []*iam.Tag{{Key: aws.String("managedBy"), Value: aws.String("iam-manager")}}}).Times(1).Return(&iam.CreateRoleOutput{Role: &iam.Role{RoleId: aws.String("ABCDE1234"), Arn: aws.String("arn:aws:iam::123456789012:role/VALID_ROLE")}}, nil)
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](&iam.ListRoleTagsOutput{
 		Tags: []*iam.Tag{
 			{
 				Key:   aws.String("managedBy"),
 				Value: aws.String("iam-manager"),
 			},
 		},
-	}, nil)
+	}))
 	s.mockI.EXPECT().TagRole(&iam.TagRoleInput{RoleName: aws.String("VALID_ROLE"), Tags: []*iam.Tag{
 		{
 			Key:   aws.String("managedBy"),
@@ -107,6 +120,7 @@ func (s *IAMAPISuite) TestEnsureRoleSuccessWithNoManagedPolicies(c *check.C) {
 	}}).Times(1).Return(&iam.TagRoleOutput{}, nil)
 	s.mockI.EXPECT().PutRolePermissionsBoundary(&iam.PutRolePermissionsBoundaryInput{RoleName: aws.String("VALID_ROLE"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy())}).Times(1).Return(nil, nil)
 	s.mockI.EXPECT().PutRolePolicy(&iam.PutRolePolicyInput{PolicyDocument: aws.String("SOMETHING"), RoleName: aws.String("VALID_ROLE"), PolicyName: aws.String("VALID_POLICY")}).Times(1).Return(&iam.PutRolePolicyOutput{}, nil)
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{}))
 	//s.mockI.EXPECT().AttachRolePolicy(&iam.AttachRolePolicyInput{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/SOMETHING"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.AttachRolePolicyOutput{}, nil)
 	s.mockI.EXPECT().UpdateRole(&iam.UpdateRoleInput{RoleName: aws.String("VALID_ROLE"), MaxSessionDuration: aws.Int64(3600), Description: aws.String("")}).Times(1).Return(&iam.UpdateRoleOutput{}, nil)
 	s.mockI.EXPECT().UpdateAssumeRolePolicy(&iam.UpdateAssumeRolePolicyInput{RoleName: aws.String("VALID_ROLE"), PolicyDocument: aws.String("SOMETHING")}).Times(1).Return(&iam.UpdateAssumeRolePolicyOutput{}, nil)
@@ -132,7 +146,7 @@ func (s *IAMAPISuite) TestEnsureRoleSuccessWithNoManagedPolicies(c *check.C) {
 
 func (s *IAMAPISuite) TestEnsureRoleFailsIfGetRoleAndCreateRoleConflict(c *check.C) {
 	s.mockI.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "", errors.New(iam.ErrCodeNoSuchEntityException)))
-	s.mockI.EXPECT().CreateRole(&iam.CreateRoleInput{RoleName: aws.String("VALID_ROLE"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy()), MaxSessionDuration: aws.Int64(3600), AssumeRolePolicyDocument: aws.String("SOMETHING"), Description: aws.String("")}).Times(1).Return(nil, awserr.New(iam.ErrCodeEntityAlreadyExistsException, "", errors.New(iam.ErrCodeEntityAlreadyExistsException)))
+	s.mockI.EXPECT().CreateRole(&iam.CreateRoleInput{RoleName: aws.String("VALID_ROLE"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy()), MaxSessionDuration: aws.Int64(3600), AssumeRolePolicyDocument: aws.String("SOMETHING"), Description: aws.String(""), Tags: []*iam.Tag{{Key: aws.String("managedBy"), Value: aws.String("iam-manager")}}}).Times(1).Return(nil, awserr.New(iam.ErrCodeEntityAlreadyExistsException, "", errors.New(iam.ErrCodeEntityAlreadyExistsException)))
 	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", PolicyName: "VALID_POLICY", PermissionPolicy: "SOMETHING", SessionDuration: 3600, TrustPolicy: "SOMETHING", ManagedPermissionBoundaryPolicy: config.Props.ManagedPermissionBoundaryPolicy(), ManagedPolicies: config.Props.ManagedPolicies(), Tags: map[string]string{
 		"managedBy": "iam-manager",
 	}}
@@ -142,8 +156,8 @@ func (s *IAMAPISuite) TestEnsureRoleFailsIfGetRoleAndCreateRoleConflict(c *check
 
 func (s *IAMAPISuite) TestEnsureRoleWithRoleOwnedByOtherNamespace(c *check.C) {
 	s.mockI.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "", errors.New(iam.ErrCodeNoSuchEntityException)))
-	s.mockI.EXPECT().CreateRole(&iam.CreateRoleInput{RoleName: aws.String("VALID_ROLE"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy()), MaxSessionDuration: aws.Int64(3600), AssumeRolePolicyDocument: aws.String("SOMETHING"), Description: aws.String("")}).Times(1).Return(&iam.CreateRoleOutput{Role: &iam.Role{RoleId: aws.String("ABCDE1234"), Arn: aws.String("arn:aws:iam::123456789012:role/VALID_ROLE")}}, nil)
-	s.mockI.EXPECT().ListRoleTags(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.ListRoleTagsOutput{
+	s.mockI.EXPECT().CreateRole(&iam.CreateRoleInput{RoleName: aws.String("VALID_ROLE"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy()), MaxSessionDuration: aws.Int64(3600), AssumeRolePolicyDocument: aws.String("SOMETHING"), Description: aws.String(""), Tags: []*iam.Tag{{Key: aws.String("managedBy"), Value: aws.String("iam-manager")}}}).Times(1).Return(&iam.CreateRoleOutput{Role: &iam.Role{RoleId: aws.String("ABCDE1234"), Arn: aws.String("arn:aws:iam::123456789012:role/VALID_ROLE")}}, nil)
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](&iam.ListRoleTagsOutput{
 		Tags: []*iam.Tag{
 			{
 				Key:   aws.String("managedBy"),
@@ -154,7 +168,7 @@ func (s *IAMAPISuite) TestEnsureRoleWithRoleOwnedByOtherNamespace(c *check.C) {
 				Value: aws.String("namespace_name"),
 			},
 		},
-	}, nil)
+	}))
 
 	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", PolicyName: "VALID_POLICY", PermissionPolicy: "SOMETHING", SessionDuration: 3600, TrustPolicy: "SOMETHING", ManagedPermissionBoundaryPolicy: config.Props.ManagedPermissionBoundaryPolicy(), ManagedPolicies: config.Props.ManagedPolicies(), Tags: map[string]string{
 		"managedBy": "iam-manager",
@@ -165,15 +179,132 @@ func (s *IAMAPISuite) TestEnsureRoleWithRoleOwnedByOtherNamespace(c *check.C) {
 }
 
 // ###########
+func (s *IAMAPISuite) TestSyncManagedRolePoliciesDetachesNoLongerDesired(c *check.C) {
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{
+		AttachedPolicies: []*iam.AttachedPolicy{
+			{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/KEEP")},
+			{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/REMOVED")},
+			{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/ATTACHED_OUTSIDE")},
+		},
+	}))
+	s.mockI.EXPECT().AttachRolePolicy(&iam.AttachRolePolicyInput{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/NEW"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.AttachRolePolicyOutput{}, nil)
+	s.mockI.EXPECT().DetachRolePolicy(&iam.DetachRolePolicyInput{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/REMOVED"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.DetachRolePolicyOutput{}, nil)
+	req := awsapi.IAMRoleRequest{
+		Name:                            "VALID_ROLE",
+		ManagedPermissionBoundaryPolicy: config.Props.ManagedPermissionBoundaryPolicy(),
+		ManagedPolicies:                 []string{"arn:aws:iam::123456789012:policy/KEEP", "arn:aws:iam::123456789012:policy/NEW"},
+		PreviousManagedPolicies:         []string{"arn:aws:iam::123456789012:policy/KEEP", "arn:aws:iam::123456789012:policy/REMOVED"},
+	}
+	err := s.mockIAM.SyncManagedRolePolicies(s.ctx, req)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IAMAPISuite) TestSyncManagedRolePoliciesLeavesPermissionBoundary(c *check.C) {
+	boundary := "arn:aws:iam::123456789012:policy/BOUNDARY"
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{
+		AttachedPolicies: []*iam.AttachedPolicy{
+			{PolicyArn: aws.String(boundary)},
+		},
+	}))
+	req := awsapi.IAMRoleRequest{
+		Name:                            "VALID_ROLE",
+		ManagedPermissionBoundaryPolicy: boundary,
+		PreviousManagedPolicies:         []string{boundary},
+	}
+	err := s.mockIAM.SyncManagedRolePolicies(s.ctx, req)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IAMAPISuite) TestListAttachedRolePoliciesAllPages(c *check.C) {
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](
+		&iam.ListAttachedRolePoliciesOutput{AttachedPolicies: []*iam.AttachedPolicy{{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/FIRST")}}, IsTruncated: aws.Bool(true), Marker: aws.String("next")},
+		&iam.ListAttachedRolePoliciesOutput{AttachedPolicies: []*iam.AttachedPolicy{{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/SECOND")}}},
+	))
+	policies, err := s.mockIAM.ListAttachedRolePolicies(s.ctx, "VALID_ROLE")
+	c.Assert(err, check.IsNil)
+	c.Assert(policies, check.DeepEquals, []string{"arn:aws:iam::123456789012:policy/FIRST", "arn:aws:iam::123456789012:policy/SECOND"})
+}
+
+func (s *IAMAPISuite) TestSyncManagedRolePoliciesListFailure(c *check.C) {
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).Return(awserr.New(iam.ErrCodeServiceFailureException, "", errors.New(iam.ErrCodeServiceFailureException)))
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", ManagedPolicies: []string{"arn:aws:iam::123456789012:policy/NEW"}}
+	err := s.mockIAM.SyncManagedRolePolicies(s.ctx, req)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *IAMAPISuite) TestSyncInlineRolePoliciesDeletesNoLongerDesired(c *check.C) {
+	s.mockI.EXPECT().PutRolePolicy(&iam.PutRolePolicyInput{PolicyDocument: aws.String("DATA"), RoleName: aws.String("VALID_ROLE"), PolicyName: aws.String("data")}).Times(1).Return(&iam.PutRolePolicyOutput{}, nil)
+	s.mockI.EXPECT().PutRolePolicy(&iam.PutRolePolicyInput{PolicyDocument: aws.String("LOGS"), RoleName: aws.String("VALID_ROLE"), PolicyName: aws.String("logs")}).Times(1).Return(&iam.PutRolePolicyOutput{}, nil)
+	s.mockI.EXPECT().DeleteRolePolicy(&iam.DeleteRolePolicyInput{RoleName: aws.String("VALID_ROLE"), PolicyName: aws.String("removed")}).Times(1).Return(&iam.DeleteRolePolicyOutput{}, nil)
+	req := awsapi.IAMRoleRequest{
+		Name:                   "VALID_ROLE",
+		PolicyName:             "VALID_POLICY",
+		PermissionPolicy:       "SOMETHING",
+		InlinePolicies:         map[string]string{"data": "DATA", "logs": "LOGS"},
+		PreviousInlinePolicies: []string{"data", "removed", "VALID_POLICY"},
+	}
+	err := s.mockIAM.SyncInlineRolePolicies(s.ctx, req)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IAMAPISuite) TestGetRolePoliciesSkipsMissing(c *check.C) {
+	s.mockI.EXPECT().GetRolePolicy(&iam.GetRolePolicyInput{PolicyName: aws.String("VALID_POLICY"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.GetRolePolicyOutput{PolicyDocument: aws.String("SOMETHING")}, nil)
+	s.mockI.EXPECT().GetRolePolicy(&iam.GetRolePolicyInput{PolicyName: aws.String("removed"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "", errors.New(iam.ErrCodeNoSuchEntityException)))
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", PolicyName: "VALID_POLICY"}
+	policies, err := s.mockIAM.GetRolePolicies(s.ctx, req, []string{"VALID_POLICY", "removed", "VALID_POLICY"})
+	c.Assert(err, check.IsNil)
+	c.Assert(policies, check.DeepEquals, map[string]string{"VALID_POLICY": "SOMETHING"})
+}
+
+func (s *IAMAPISuite) TestGetRolePoliciesFailure(c *check.C) {
+	s.mockI.EXPECT().GetRolePolicy(&iam.GetRolePolicyInput{PolicyName: aws.String("VALID_POLICY"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(nil, awserr.New(iam.ErrCodeServiceFailureException, "", errors.New(iam.ErrCodeServiceFailureException)))
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", PolicyName: "VALID_POLICY"}
+	_, err := s.mockIAM.GetRolePolicies(s.ctx, req, []string{"VALID_POLICY"})
+	c.Assert(err, check.NotNil)
+}
+
+func (s *IAMAPISuite) TestListRolesAllPages(c *check.C) {
+	s.mockI.EXPECT().ListRolesPages(&iam.ListRolesInput{PathPrefix: aws.String("/k8s/")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRolesInput](
+		&iam.ListRolesOutput{Roles: []*iam.Role{{RoleName: aws.String("k8s-a")}}, IsTruncated: aws.Bool(true)},
+		&iam.ListRolesOutput{Roles: []*iam.Role{{RoleName: aws.String("k8s-b")}}},
+	))
+	roles, err := s.mockIAM.ListRoles(s.ctx, "/k8s/")
+	c.Assert(err, check.IsNil)
+	c.Assert(roles, check.HasLen, 2)
+	c.Assert(aws.StringValue(roles[1].RoleName), check.Equals, "k8s-b")
+}
+
+func (s *IAMAPISuite) TestListRolesFailure(c *check.C) {
+	s.mockI.EXPECT().ListRolesPages(&iam.ListRolesInput{}, gomock.Any()).Times(1).Return(awserr.New(iam.ErrCodeServiceFailureException, "", errors.New(iam.ErrCodeServiceFailureException)))
+	_, err := s.mockIAM.ListRoles(s.ctx, "")
+	c.Assert(err, check.NotNil)
+}
+
+func (s *IAMAPISuite) TestListRoleTagsAllPages(c *check.C) {
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](
+		&iam.ListRoleTagsOutput{Tags: []*iam.Tag{{Key: aws.String("managedBy"), Value: aws.String("iam-manager")}}, IsTruncated: aws.Bool(true), Marker: aws.String("next")},
+		&iam.ListRoleTagsOutput{Tags: []*iam.Tag{{Key: aws.String("Namespace"), Value: aws.String("default")}}},
+	))
+	tags, err := s.mockIAM.ListRoleTags(s.ctx, "VALID_ROLE")
+	c.Assert(err, check.IsNil)
+	c.Assert(tags, check.DeepEquals, map[string]string{"managedBy": "iam-manager", "Namespace": "default"})
+}
+
+func (s *IAMAPISuite) TestListRoleTagsFailure(c *check.C) {
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).Return(awserr.New(iam.ErrCodeNoSuchEntityException, "", errors.New(iam.ErrCodeNoSuchEntityException)))
+	_, err := s.mockIAM.ListRoleTags(s.ctx, "VALID_ROLE")
+	c.Assert(err, check.NotNil)
+}
+
 func (s *IAMAPISuite) TestVerifyTagsSuccess(c *check.C) {
-	s.mockI.EXPECT().ListRoleTags(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.ListRoleTagsOutput{
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](&iam.ListRoleTagsOutput{
 		Tags: []*iam.Tag{
 			{
 				Key:   aws.String("managedBy"),
 				Value: aws.String("iam-manager"),
 			},
 		},
-	}, nil)
+	}))
 
 	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", PolicyName: "VALID_POLICY", PermissionPolicy: "SOMETHING", ManagedPermissionBoundaryPolicy: config.Props.ManagedPermissionBoundaryPolicy(), Tags: map[string]string{
 		"managedBy": "iam-manager",
@@ -184,7 +315,7 @@ func (s *IAMAPISuite) TestVerifyTagsSuccess(c *check.C) {
 }
 
 func (s *IAMAPISuite) TestVerifyTagsDifferentClusters(c *check.C) {
-	s.mockI.EXPECT().ListRoleTags(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.ListRoleTagsOutput{
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](&iam.ListRoleTagsOutput{
 		Tags: []*iam.Tag{
 			{
 				Key:   aws.String("managedBy"),
@@ -195,7 +326,7 @@ func (s *IAMAPISuite) TestVerifyTagsDifferentClusters(c *check.C) {
 				Value: aws.String("different-cluster"),
 			},
 		},
-	}, nil)
+	}))
 
 	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", PolicyName: "VALID_POLICY", PermissionPolicy: "SOMETHING", ManagedPermissionBoundaryPolicy: config.Props.ManagedPermissionBoundaryPolicy(), Tags: map[string]string{
 		"managedBy": "iam-manager",
@@ -208,7 +339,7 @@ func (s *IAMAPISuite) TestVerifyTagsDifferentClusters(c *check.C) {
 }
 
 func (s *IAMAPISuite) TestVerifyTagsDifferentNamespace(c *check.C) {
-	s.mockI.EXPECT().ListRoleTags(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.ListRoleTagsOutput{
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](&iam.ListRoleTagsOutput{
 		Tags: []*iam.Tag{
 			{
 				Key:   aws.String("managedBy"),
@@ -219,7 +350,7 @@ func (s *IAMAPISuite) TestVerifyTagsDifferentNamespace(c *check.C) {
 				Value: aws.String("different-namespace"),
 			},
 		},
-	}, nil)
+	}))
 
 	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", PolicyName: "VALID_POLICY", PermissionPolicy: "SOMETHING", ManagedPermissionBoundaryPolicy: config.Props.ManagedPermissionBoundaryPolicy(), Tags: map[string]string{
 		"managedBy": "iam-manager",
@@ -231,6 +362,94 @@ func (s *IAMAPISuite) TestVerifyTagsDifferentNamespace(c *check.C) {
 
 }
 
+func (s *IAMAPISuite) TestVerifyTagsDifferentNamespaceOnLaterPage(c *check.C) {
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](
+		&iam.ListRoleTagsOutput{Tags: []*iam.Tag{{Key: aws.String("managedBy"), Value: aws.String("iam-manager")}}, IsTruncated: aws.Bool(true), Marker: aws.String("next")},
+		&iam.ListRoleTagsOutput{Tags: []*iam.Tag{{Key: aws.String("Namespace"), Value: aws.String("different-namespace")}}},
+	))
+
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", Tags: map[string]string{
+		"managedBy": "iam-manager",
+		"Namespace": "namespace_name",
+	}}
+	_, err := s.mockIAM.VerifyTags(s.ctx, req)
+	c.Assert(err, check.NotNil)
+	c.Assert(err.Error(), check.Matches, "*"+awsapi.RoleExistsAlreadyForOtherNamespace)
+}
+
+func (s *IAMAPISuite) TestVerifyTagsManagedOnLaterPage(c *check.C) {
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](
+		&iam.ListRoleTagsOutput{Tags: []*iam.Tag{{Key: aws.String("Namespace"), Value: aws.String("namespace_name")}}, IsTruncated: aws.Bool(true), Marker: aws.String("next")},
+		&iam.ListRoleTagsOutput{Tags: []*iam.Tag{{Key: aws.String("managedBy"), Value: aws.String("iam-manager")}}},
+	))
+
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", Tags: map[string]string{
+		"managedBy": "iam-manager",
+		"Namespace": "namespace_name",
+	}}
+	_, err := s.mockIAM.VerifyTags(s.ctx, req)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IAMAPISuite) TestVerifyTagsNotManaged(c *check.C) {
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](&iam.ListRoleTagsOutput{
+		Tags: []*iam.Tag{{Key: aws.String("team"), Value: aws.String("payments")}},
+	}))
+
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", Tags: map[string]string{
+		"managedBy": "iam-manager",
+		"Namespace": "namespace_name",
+	}}
+	_, err := s.mockIAM.VerifyTags(s.ctx, req)
+	c.Assert(err, check.NotNil)
+	c.Assert(err.Error(), check.Matches, ".*"+awsapi.RoleNotManagedByIamManager)
+}
+
+func (s *IAMAPISuite) TestVerifyTagsAdoptRole(c *check.C) {
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](&iam.ListRoleTagsOutput{
+		Tags: []*iam.Tag{{Key: aws.String("Namespace"), Value: aws.String("different-namespace")}},
+	}))
+
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", AdoptRole: true, Tags: map[string]string{
+		"managedBy": "iam-manager",
+		"Namespace": "namespace_name",
+	}}
+	_, err := s.mockIAM.VerifyTags(s.ctx, req)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IAMAPISuite) TestVerifyTagsAdoptUnmanagedRoleOfOtherCluster(c *check.C) {
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](&iam.ListRoleTagsOutput{
+		Tags: []*iam.Tag{{Key: aws.String("Cluster"), Value: aws.String("different-cluster")}},
+	}))
+
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", AdoptRole: true, Tags: map[string]string{
+		"managedBy": "iam-manager",
+		"Cluster":   "cluster_name",
+	}}
+	_, err := s.mockIAM.VerifyTags(s.ctx, req)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IAMAPISuite) TestVerifyTagsAdoptRoleManagedForOtherCluster(c *check.C) {
+	s.mockI.EXPECT().ListRoleTagsPages(&iam.ListRoleTagsInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRoleTagsInput](&iam.ListRoleTagsOutput{
+		Tags: []*iam.Tag{
+			{Key: aws.String("managedBy"), Value: aws.String("iam-manager")},
+			{Key: aws.String("Namespace"), Value: aws.String("namespace_name")},
+			{Key: aws.String("Cluster"), Value: aws.String("different-cluster")},
+		},
+	}))
+
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", AdoptRole: true, Tags: map[string]string{
+		"managedBy": "iam-manager",
+		"Namespace": "namespace_name",
+		"Cluster":   "cluster_name",
+	}}
+	_, err := s.mockIAM.VerifyTags(s.ctx, req)
+	c.Assert(err, check.NotNil)
+	c.Assert(err.Error(), check.Matches, ".*"+awsapi.RoleExistsAlreadyForOtherNamespace)
+}
+
 func (s *IAMAPISuite) TestTagRoleSuccess(c *check.C) {
 	s.mockI.EXPECT().TagRole(&iam.TagRoleInput{RoleName: aws.String("VALID_ROLE"), Tags: []*iam.Tag{
 		{
@@ -598,7 +817,7 @@ func (s *IAMAPISuite) TestGetRolePolicyFailureUnmodififiablePolicyDocument(c *ch
 
 func (s *IAMAPISuite) TestGetOrCreateRoleSuccessNewRole(c *check.C) {
 	s.mockI.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "", errors.New(iam.ErrCodeNoSuchEntityException)))
-	s.mockI.EXPECT().CreateRole(&iam.CreateRoleInput{RoleName: aws.String("VALID_ROLE"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy()), MaxSessionDuration: aws.Int64(3600), AssumeRolePolicyDocument: aws.String("SOMETHING"), Description: aws.String("")}).Times(1).Return(&iam.CreateRoleOutput{Role: &iam.Role{RoleId: aws.String("ABCDE1234"), Arn: aws.String("arn:aws:iam::123456789012:role/VALID_ROLE")}}, nil)
+	s.mockI.EXPECT().CreateRole(&iam.CreateRoleInput{RoleName: aws.String("VALID_ROLE"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy()), MaxSessionDuration: aws.Int64(3600), AssumeRolePolicyDocument: aws.String("SOMETHING"), Description: aws.String(""), Tags: []*iam.Tag{{Key: aws.String("managedBy"), Value: aws.String("iam-manager")}}}).Times(1).Return(&iam.CreateRoleOutput{Role: &iam.Role{RoleId: aws.String("ABCDE1234"), Arn: aws.String("arn:aws:iam::123456789012:role/VALID_ROLE")}}, nil)
 	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", PolicyName: "VALID_POLICY", PermissionPolicy: "SOMETHING", SessionDuration: 3600, TrustPolicy: "SOMETHING", ManagedPermissionBoundaryPolicy: config.Props.ManagedPermissionBoundaryPolicy(), ManagedPolicies: config.Props.ManagedPolicies(), Tags: map[string]string{
 		"managedBy": "iam-manager",
 	}}
@@ -617,6 +836,43 @@ func (s *IAMAPISuite) TestGetOrCreateRoleSuccessExistsRole(c *check.C) {
 	c.Assert(err, check.IsNil)
 }
 
+func (s *IAMAPISuite) TestGetOrCreateRoleSuccessNewRoleWithPath(c *check.C) {
+	s.mockI.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "", errors.New(iam.ErrCodeNoSuchEntityException)))
+	s.mockI.EXPECT().CreateRole(&iam.CreateRoleInput{RoleName: aws.String("VALID_ROLE"), Path: aws.String("/k8s/cluster/"), PermissionsBoundary: aws.String(config.Props.ManagedPermissionBoundaryPolicy()), MaxSessionDuration: aws.Int64(7200), AssumeRolePolicyDocument: aws.String("SOMETHING"), Description: aws.String("my role")}).Times(1).Return(&iam.CreateRoleOutput{Role: &iam.Role{RoleId: aws.String("ABCDE1234"), Arn: aws.String("arn:aws:iam::123456789012:role/k8s/cluster/VALID_ROLE")}}, nil)
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", PolicyName: "VALID_POLICY", PermissionPolicy: "SOMETHING", SessionDuration: 7200, Description: "my role", Path: "/k8s/cluster/", TrustPolicy: "SOMETHING", ManagedPermissionBoundaryPolicy: config.Props.ManagedPermissionBoundaryPolicy()}
+	resp, err := s.mockIAM.GetOrCreateRole(s.ctx, req)
+	c.Assert(err, check.IsNil)
+	c.Assert(resp.RoleARN, check.Equals, "arn:aws:iam::123456789012:role/k8s/cluster/VALID_ROLE")
+}
+
+func (s *IAMAPISuite) TestGetOrCreateRoleFailureExistsRoleWithDifferentPath(c *check.C) {
+	s.mockI.EXPECT().GetRole(&iam.GetRoleInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(
+		&iam.GetRoleOutput{Role: &iam.Role{RoleId: aws.String("ABCDE1234"), Path: aws.String("/"), Arn: aws.String("arn:aws:iam::123456789012:role/VALID_ROLE")}}, nil)
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", PolicyName: "VALID_POLICY", PermissionPolicy: "SOMETHING", SessionDuration: 3600, Path: "/k8s/cluster/", TrustPolicy: "SOMETHING", ManagedPermissionBoundaryPolicy: config.Props.ManagedPermissionBoundaryPolicy()}
+	_, err := s.mockIAM.GetOrCreateRole(s.ctx, req)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *IAMAPISuite) TestUntagRoleRemovesStaleTags(c *check.C) {
+	s.mockI.EXPECT().UntagRole(&iam.UntagRoleInput{RoleName: aws.String("VALID_ROLE"), TagKeys: []*string{aws.String("removed")}}).Times(1).Return(&iam.UntagRoleOutput{}, nil)
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", Tags: map[string]string{"managedBy": "iam-manager", "team": "payments"}, PreviousTags: []string{"team", "removed"}}
+	err := s.mockIAM.UntagRole(s.ctx, req)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IAMAPISuite) TestUntagRoleNothingToRemove(c *check.C) {
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", Tags: map[string]string{"managedBy": "iam-manager", "team": "payments"}, PreviousTags: []string{"team"}}
+	err := s.mockIAM.UntagRole(s.ctx, req)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IAMAPISuite) TestUntagRoleFailure(c *check.C) {
+	s.mockI.EXPECT().UntagRole(&iam.UntagRoleInput{RoleName: aws.String("VALID_ROLE"), TagKeys: []*string{aws.String("removed")}}).Times(1).Return(nil, awserr.New(iam.ErrCodeServiceFailureException, "", errors.New(iam.ErrCodeServiceFailureException)))
+	req := awsapi.IAMRoleRequest{Name: "VALID_ROLE", PreviousTags: []string{"removed"}}
+	err := s.mockIAM.UntagRole(s.ctx, req)
+	c.Assert(err, check.NotNil)
+}
+
 //###########
 
 func (s *IAMAPISuite) TestCreateRoleInvalidRequest(c *check.C) {
@@ -775,48 +1031,109 @@ func (s *IAMAPISuite) TestAttachManagedRolePolicyFailureUnattachablePolicyDocume
 
 func (s *IAMAPISuite) TestDeleteRoleSuccess(c *check.C) {
 	s.mockI.EXPECT().DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.DeleteRoleOutput{}, nil)
-	s.mockI.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.ListAttachedRolePoliciesOutput{}, nil)
-	s.mockI.EXPECT().ListRolePolicies(&iam.ListRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.ListRolePoliciesOutput{}, nil)
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{}))
+	s.mockI.EXPECT().ListRolePoliciesPages(&iam.ListRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRolePoliciesInput](&iam.ListRolePoliciesOutput{}))
 
 	err := s.mockIAM.DeleteRole(s.ctx, "VALID_ROLE")
 	c.Assert(err, check.IsNil)
 }
 
+func (s *IAMAPISuite) TestDeleteRoleAllPages(c *check.C) {
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](
+		&iam.ListAttachedRolePoliciesOutput{AttachedPolicies: []*iam.AttachedPolicy{{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/FIRST"), PolicyName: aws.String("FIRST")}}, IsTruncated: aws.Bool(true), Marker: aws.String("next")},
+		&iam.ListAttachedRolePoliciesOutput{AttachedPolicies: []*iam.AttachedPolicy{{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/SECOND"), PolicyName: aws.String("SECOND")}}},
+	))
+	s.mockI.EXPECT().DetachRolePolicy(&iam.DetachRolePolicyInput{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/FIRST"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.DetachRolePolicyOutput{}, nil)
+	s.mockI.EXPECT().DetachRolePolicy(&iam.DetachRolePolicyInput{PolicyArn: aws.String("arn:aws:iam::123456789012:policy/SECOND"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.DetachRolePolicyOutput{}, nil)
+	s.mockI.EXPECT().ListRolePoliciesPages(&iam.ListRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRolePoliciesInput](
+		&iam.ListRolePoliciesOutput{PolicyNames: aws.StringSlice([]string{"custom"}), IsTruncated: aws.Bool(true), Marker: aws.String("next")},
+		&iam.ListRolePoliciesOutput{PolicyNames: aws.StringSlice([]string{"logs"})},
+	))
+	s.mockI.EXPECT().DeleteRolePolicy(&iam.DeleteRolePolicyInput{PolicyName: aws.String("custom"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.DeleteRolePolicyOutput{}, nil)
+	s.mockI.EXPECT().DeleteRolePolicy(&iam.DeleteRolePolicyInput{PolicyName: aws.String("logs"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.DeleteRolePolicyOutput{}, nil)
+	s.mockI.EXPECT().DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.DeleteRoleOutput{}, nil)
+
+	err := s.mockIAM.DeleteRole(s.ctx, "VALID_ROLE")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IAMAPISuite) TestDeleteRoleInlinePoliciesListFailure(c *check.C) {
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("SERVICE_FAILURE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{}))
+	s.mockI.EXPECT().ListRolePoliciesPages(&iam.ListRolePoliciesInput{RoleName: aws.String("SERVICE_FAILURE")}, gomock.Any()).Times(1).Return(awserr.New(iam.ErrCodeServiceFailureException, "", errors.New(iam.ErrCodeServiceFailureException)))
+
+	err := s.mockIAM.DeleteRole(s.ctx, "SERVICE_FAILURE")
+	c.Assert(err, check.NotNil)
+}
+
+func (s *IAMAPISuite) TestRetainRoleSuccess(c *check.C) {
+	s.mockI.EXPECT().UntagRole(&iam.UntagRoleInput{RoleName: aws.String("VALID_ROLE"), TagKeys: aws.StringSlice([]string{"managedBy", "Namespace", "Cluster"})}).Times(1).Return(&iam.UntagRoleOutput{}, nil)
+
+	err := s.mockIAM.RetainRole(s.ctx, "VALID_ROLE", false)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IAMAPISuite) TestRetainRoleWithoutPoliciesSuccess(c *check.C) {
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{
+		AttachedPolicies: []*iam.AttachedPolicy{{PolicyArn: aws.String("arn:aws:iam::aws:policy/ReadOnlyAccess"), PolicyName: aws.String("ReadOnlyAccess")}},
+	}))
+	s.mockI.EXPECT().DetachRolePolicy(&iam.DetachRolePolicyInput{PolicyArn: aws.String("arn:aws:iam::aws:policy/ReadOnlyAccess"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.DetachRolePolicyOutput{}, nil)
+	s.mockI.EXPECT().ListRolePoliciesPages(&iam.ListRolePoliciesInput{RoleName: aws.String("VALID_ROLE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRolePoliciesInput](&iam.ListRolePoliciesOutput{PolicyNames: aws.StringSlice([]string{"custom"})}))
+	s.mockI.EXPECT().DeleteRolePolicy(&iam.DeleteRolePolicyInput{PolicyName: aws.String("custom"), RoleName: aws.String("VALID_ROLE")}).Times(1).Return(&iam.DeleteRolePolicyOutput{}, nil)
+	s.mockI.EXPECT().UntagRole(&iam.UntagRoleInput{RoleName: aws.String("VALID_ROLE"), TagKeys: aws.StringSlice([]string{"managedBy", "Namespace", "Cluster"})}).Times(1).Return(&iam.UntagRoleOutput{}, nil)
+
+	err := s.mockIAM.RetainRole(s.ctx, "VALID_ROLE", true)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IAMAPISuite) TestRetainRoleNoSuchEntity(c *check.C) {
+	s.mockI.EXPECT().UntagRole(&iam.UntagRoleInput{RoleName: aws.String("NO_SUCH_ENTITY"), TagKeys: aws.StringSlice([]string{"managedBy", "Namespace", "Cluster"})}).Times(1).Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "", errors.New(iam.ErrCodeNoSuchEntityException)))
+
+	err := s.mockIAM.RetainRole(s.ctx, "NO_SUCH_ENTITY", false)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *IAMAPISuite) TestRetainRoleFailure(c *check.C) {
+	s.mockI.EXPECT().UntagRole(&iam.UntagRoleInput{RoleName: aws.String("SERVICE_FAILURE"), TagKeys: aws.StringSlice([]string{"managedBy", "Namespace", "Cluster"})}).Times(1).Return(nil, awserr.New(iam.ErrCodeServiceFailureException, "", errors.New(iam.ErrCodeServiceFailureException)))
+
+	err := s.mockIAM.RetainRole(s.ctx, "SERVICE_FAILURE", false)
+	c.Assert(err, check.NotNil)
+}
+
 func (s *IAMAPISuite) TestDeleteRoleFailureMalformedPolicyDocument(c *check.C) {
 	s.mockI.EXPECT().DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("MALFORMED_POLICY")}).Times(1).Return(nil, awserr.New(iam.ErrCodeMalformedPolicyDocumentException, "", errors.New(iam.ErrCodeMalformedPolicyDocumentException)))
-	s.mockI.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("MALFORMED_POLICY")}).Times(1).Return(&iam.ListAttachedRolePoliciesOutput{}, nil)
-	s.mockI.EXPECT().ListRolePolicies(&iam.ListRolePoliciesInput{RoleName: aws.String("MALFORMED_POLICY")}).Times(1).Return(&iam.ListRolePoliciesOutput{}, nil)
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("MALFORMED_POLICY")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{}))
+	s.mockI.EXPECT().ListRolePoliciesPages(&iam.ListRolePoliciesInput{RoleName: aws.String("MALFORMED_POLICY")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRolePoliciesInput](&iam.ListRolePoliciesOutput{}))
 	err := s.mockIAM.DeleteRole(s.ctx, "MALFORMED_POLICY")
 	c.Assert(err, check.NotNil)
 }
 
 func (s *IAMAPISuite) TestDeleteRoleFailureLimitExceeded(c *check.C) {
 	s.mockI.EXPECT().DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("TOO_MANY_REQUEST")}).Times(1).Return(nil, awserr.New(iam.ErrCodeLimitExceededException, "", errors.New(iam.ErrCodeLimitExceededException)))
-	s.mockI.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("TOO_MANY_REQUEST")}).Times(1).Return(&iam.ListAttachedRolePoliciesOutput{}, nil)
-	s.mockI.EXPECT().ListRolePolicies(&iam.ListRolePoliciesInput{RoleName: aws.String("TOO_MANY_REQUEST")}).Times(1).Return(&iam.ListRolePoliciesOutput{}, nil)
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("TOO_MANY_REQUEST")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{}))
+	s.mockI.EXPECT().ListRolePoliciesPages(&iam.ListRolePoliciesInput{RoleName: aws.String("TOO_MANY_REQUEST")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRolePoliciesInput](&iam.ListRolePoliciesOutput{}))
 
 	err := s.mockIAM.DeleteRole(s.ctx, "TOO_MANY_REQUEST")
 	c.Assert(err, check.NotNil)
 }
 func (s *IAMAPISuite) TestDeleteRoleFailureNoSuchEntity(c *check.C) {
 	s.mockI.EXPECT().DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("NO_SUCH_ENTITY")}).Times(1).Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "", errors.New(iam.ErrCodeNoSuchEntityException)))
-	s.mockI.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("NO_SUCH_ENTITY")}).Times(1).Return(&iam.ListAttachedRolePoliciesOutput{}, nil)
-	s.mockI.EXPECT().ListRolePolicies(&iam.ListRolePoliciesInput{RoleName: aws.String("NO_SUCH_ENTITY")}).Times(1).Return(&iam.ListRolePoliciesOutput{}, nil)
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("NO_SUCH_ENTITY")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{}))
+	s.mockI.EXPECT().ListRolePoliciesPages(&iam.ListRolePoliciesInput{RoleName: aws.String("NO_SUCH_ENTITY")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRolePoliciesInput](&iam.ListRolePoliciesOutput{}))
 
 	err := s.mockIAM.DeleteRole(s.ctx, "NO_SUCH_ENTITY")
 	c.Assert(err, check.IsNil)
 }
 
 func (s *IAMAPISuite) TestDeleteRoleFailureNoSuchEntityAssumeRole(c *check.C) {
-	s.mockI.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("NO_SUCH_ENTITY")}).Times(1).Return(nil, awserr.New(iam.ErrCodeNoSuchEntityException, "", errors.New(iam.ErrCodeNoSuchEntityException)))
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("NO_SUCH_ENTITY")}, gomock.Any()).Times(1).Return(awserr.New(iam.ErrCodeNoSuchEntityException, "", errors.New(iam.ErrCodeNoSuchEntityException)))
 
 	err := s.mockIAM.DeleteRole(s.ctx, "NO_SUCH_ENTITY")
 	c.Assert(err, check.IsNil)
 }
 func (s *IAMAPISuite) TestDeleteRoleFailureServiceFailure(c *check.C) {
 	s.mockI.EXPECT().DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("SERVICE_FAILURE")}).Times(1).Return(nil, awserr.New(iam.ErrCodeServiceFailureException, "", errors.New(iam.ErrCodeServiceFailureException)))
-	s.mockI.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("SERVICE_FAILURE")}).Times(1).Return(&iam.ListAttachedRolePoliciesOutput{}, nil)
-	s.mockI.EXPECT().ListRolePolicies(&iam.ListRolePoliciesInput{RoleName: aws.String("SERVICE_FAILURE")}).Times(1).Return(&iam.ListRolePoliciesOutput{}, nil)
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("SERVICE_FAILURE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{}))
+	s.mockI.EXPECT().ListRolePoliciesPages(&iam.ListRolePoliciesInput{RoleName: aws.String("SERVICE_FAILURE")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRolePoliciesInput](&iam.ListRolePoliciesOutput{}))
 
 	err := s.mockIAM.DeleteRole(s.ctx, "SERVICE_FAILURE")
 	c.Assert(err, check.NotNil)
@@ -824,8 +1141,8 @@ func (s *IAMAPISuite) TestDeleteRoleFailureServiceFailure(c *check.C) {
 
 func (s *IAMAPISuite) TestDeleteRoleFailureUnmodififiablePolicyDocument(c *check.C) {
 	s.mockI.EXPECT().DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("UNMODIFIABLE_POLICY")}).Times(1).Return(nil, awserr.New(iam.ErrCodeUnmodifiableEntityException, "", errors.New(iam.ErrCodeUnmodifiableEntityException)))
-	s.mockI.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("UNMODIFIABLE_POLICY")}).Times(1).Return(&iam.ListAttachedRolePoliciesOutput{}, nil)
-	s.mockI.EXPECT().ListRolePolicies(&iam.ListRolePoliciesInput{RoleName: aws.String("UNMODIFIABLE_POLICY")}).Times(1).Return(&iam.ListRolePoliciesOutput{}, nil)
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("UNMODIFIABLE_POLICY")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{}))
+	s.mockI.EXPECT().ListRolePoliciesPages(&iam.ListRolePoliciesInput{RoleName: aws.String("UNMODIFIABLE_POLICY")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRolePoliciesInput](&iam.ListRolePoliciesOutput{}))
 
 	err := s.mockIAM.DeleteRole(s.ctx, "UNMODIFIABLE_POLICY")
 	c.Assert(err, check.NotNil)
@@ -833,8 +1150,8 @@ func (s *IAMAPISuite) TestDeleteRoleFailureUnmodififiablePolicyDocument(c *check
 
 func (s *IAMAPISuite) TestDeleteRoleFailureInvalidPolicyDocument(c *check.C) {
 	s.mockI.EXPECT().DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("INVALID_POLICY")}).Times(1).Return(nil, awserr.New(iam.ErrCodeInvalidInputException, "", errors.New(iam.ErrCodeInvalidInputException)))
-	s.mockI.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("INVALID_POLICY")}).Times(1).Return(&iam.ListAttachedRolePoliciesOutput{}, nil)
-	s.mockI.EXPECT().ListRolePolicies(&iam.ListRolePoliciesInput{RoleName: aws.String("INVALID_POLICY")}).Times(1).Return(&iam.ListRolePoliciesOutput{}, nil)
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("INVALID_POLICY")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{}))
+	s.mockI.EXPECT().ListRolePoliciesPages(&iam.ListRolePoliciesInput{RoleName: aws.String("INVALID_POLICY")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRolePoliciesInput](&iam.ListRolePoliciesOutput{}))
 
 	err := s.mockIAM.DeleteRole(s.ctx, "INVALID_POLICY")
 	c.Assert(err, check.NotNil)
@@ -842,8 +1159,8 @@ func (s *IAMAPISuite) TestDeleteRoleFailureInvalidPolicyDocument(c *check.C) {
 
 func (s *IAMAPISuite) TestDeleteRoleFailureUnattachablePolicyDocument(c *check.C) {
 	s.mockI.EXPECT().DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String("UNATTACHABLE_POLICY")}).Times(1).Return(nil, awserr.New(iam.ErrCodePolicyNotAttachableException, "", errors.New(iam.ErrCodePolicyNotAttachableException)))
-	s.mockI.EXPECT().ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("UNATTACHABLE_POLICY")}).Times(1).Return(&iam.ListAttachedRolePoliciesOutput{}, nil)
-	s.mockI.EXPECT().ListRolePolicies(&iam.ListRolePoliciesInput{RoleName: aws.String("UNATTACHABLE_POLICY")}).Times(1).Return(&iam.ListRolePoliciesOutput{}, nil)
+	s.mockI.EXPECT().ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("UNATTACHABLE_POLICY")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListAttachedRolePoliciesInput](&iam.ListAttachedRolePoliciesOutput{}))
+	s.mockI.EXPECT().ListRolePoliciesPages(&iam.ListRolePoliciesInput{RoleName: aws.String("UNATTACHABLE_POLICY")}, gomock.Any()).Times(1).DoAndReturn(listPages[*iam.ListRolePoliciesInput](&iam.ListRolePoliciesOutput{}))
 
 	err := s.mockIAM.DeleteRole(s.ctx, "UNATTACHABLE_POLICY")
 	c.Assert(err, check.NotNil)
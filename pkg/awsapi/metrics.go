@@ -0,0 +1,53 @@
+package awsapi
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	apiCallsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iam_manager_aws_api_calls_total",
+		Help: "Number of AWS API calls by service and operation. Every retry is counted",
+	}, []string{"service", "operation"})
+	apiCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "iam_manager_aws_api_call_duration_seconds",
+		Help:    "Latency of the AWS API calls by service and operation",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"service", "operation"})
+	apiErrorsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "iam_manager_aws_api_errors_total",
+		Help: "Number of failed AWS API calls by service, operation and AWS error code",
+	}, []string{"service", "operation", "code"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(apiCallsCounter, apiCallDuration, apiErrorsCounter)
+}
+
+// InstrumentSession records every AWS API call made through the session in the iam_manager_aws_api metrics
+func InstrumentSession(sess *session.Session) {
+	sess.Handlers.CompleteAttempt.PushBackNamed(request.NamedHandler{Name: "iammanager.metrics", Fn: recordAPICall})
+}
+
+func recordAPICall(r *request.Request) {
+	service, operation := r.ClientInfo.ServiceName, "unknown"
+	if r.Operation != nil {
+		operation = r.Operation.Name
+	}
+	apiCallsCounter.WithLabelValues(service, operation).Inc()
+	apiCallDuration.WithLabelValues(service, operation).Observe(time.Since(r.AttemptTime).Seconds())
+	if r.Error == nil {
+		return
+	}
+	code := "Unknown"
+	if aerr, ok := r.Error.(awserr.Error); ok {
+		code = aerr.Code()
+	}
+	apiErrorsCounter.WithLabelValues(service, operation, code).Inc()
+}
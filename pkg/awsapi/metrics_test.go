@@ -0,0 +1,97 @@
+package awsapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	dto "github.com/prometheus/client_model/go"
+	"gopkg.in/check.v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/keikoproj/iam-manager/pkg/awsapi"
+)
+
+type MetricsSuite struct {
+	t *testing.T
+}
+
+func TestMetricsSuite(t *testing.T) {
+	check.Suite(&MetricsSuite{t: t})
+	check.TestingT(t)
+}
+
+// metricValue returns the value of the counter or the sample count of the histogram with the labels
+func metricValue(c *check.C, name string, labels map[string]string) float64 {
+	families, err := metrics.Registry.Gather()
+	c.Assert(err, check.IsNil)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if matchLabels(metric, labels) {
+				if metric.GetHistogram() != nil {
+					return float64(metric.GetHistogram().GetSampleCount())
+				}
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func matchLabels(metric *dto.Metric, labels map[string]string) bool {
+	matched := 0
+	for _, label := range metric.GetLabel() {
+		if value, ok := labels[label.GetName()]; ok && value == label.GetValue() {
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
+
+func (s *MetricsSuite) TestInstrumentSession(c *check.C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("Action") != "GetRole" {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`<ListRolesResponse><ListRolesResult><IsTruncated>false</IsTruncated><Roles></Roles></ListRolesResult></ListRolesResponse>`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>Throttling</Code><Message>Rate exceeded</Message></Error></ErrorResponse>`))
+	}))
+	defer server.Close()
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+		MaxRetries:  aws.Int(0),
+	})
+	c.Assert(err, check.IsNil)
+	awsapi.InstrumentSession(sess)
+	client := iam.New(sess)
+
+	getRole := map[string]string{"service": "iam", "operation": "GetRole"}
+	listRoles := map[string]string{"service": "iam", "operation": "ListRoles"}
+	throttled := map[string]string{"service": "iam", "operation": "GetRole", "code": "Throttling"}
+	calls := metricValue(c, "iam_manager_aws_api_calls_total", getRole)
+	listCalls := metricValue(c, "iam_manager_aws_api_calls_total", listRoles)
+	errs := metricValue(c, "iam_manager_aws_api_errors_total", throttled)
+
+	_, err = client.GetRole(&iam.GetRoleInput{RoleName: aws.String("k8s-default")})
+	c.Assert(err, check.NotNil)
+	_, err = client.ListRoles(&iam.ListRolesInput{})
+	c.Assert(err, check.IsNil)
+
+	c.Assert(metricValue(c, "iam_manager_aws_api_calls_total", getRole), check.Equals, calls+1)
+	c.Assert(metricValue(c, "iam_manager_aws_api_calls_total", listRoles), check.Equals, listCalls+1)
+	c.Assert(metricValue(c, "iam_manager_aws_api_errors_total", throttled), check.Equals, errs+1)
+	c.Assert(metricValue(c, "iam_manager_aws_api_call_duration_seconds", getRole) >= 1, check.Equals, true)
+	c.Assert(metricValue(c, "iam_manager_aws_api_errors_total", map[string]string{"service": "iam", "operation": "ListRoles"}), check.Equals, float64(0))
+}
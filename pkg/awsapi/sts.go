@@ -11,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 
 	"github.com/keikoproj/iam-manager/pkg/logging"
+	"github.com/keikoproj/iam-manager/pkg/tracing"
 )
 
 type STSIface interface {
@@ -26,14 +27,17 @@ func NewSTS(region string) *STS {
 	if err != nil {
 		panic(err)
 	}
+	InstrumentSession(sess)
 	return &STS{
 		Client: sts.New(sess),
 	}
 }
 
 // GetAccountID loads aws accountID from sts caller identity
-func (i *STS) GetAccountID(ctx context.Context) (string, error) {
-	log := logging.Logger(context.Background(), "awsapi", "iam", "GetAccountID")
+func (i *STS) GetAccountID(ctx context.Context) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "awsapi.GetAccountID")
+	defer func() { tracing.End(span, err) }()
+	log := logging.Logger(ctx, "awsapi", "sts", "GetAccountID")
 
 	// get caller identity in order to fetch aws account ID
 	result, err := i.Client.GetCallerIdentity(&sts.GetCallerIdentityInput{})
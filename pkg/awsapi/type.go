@@ -7,7 +7,9 @@ type PolicyDocument struct {
 
 // StatementEntry represents a single statement in an IAM policy document
 type StatementEntry struct {
-	Action   interface{} `json:"Action"`
-	Resource interface{} `json:"Resource"`
-	Effect   string      `json:"Effect"`
+	Action      interface{} `json:"Action"`
+	NotAction   interface{} `json:"NotAction"`
+	Resource    interface{} `json:"Resource"`
+	NotResource interface{} `json:"NotResource"`
+	Effect      string      `json:"Effect"`
 }
@@ -8,8 +8,6 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/dynamic"
 	clientv1 "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -24,15 +22,8 @@ import (
 	"github.com/keikoproj/iam-manager/pkg/logging"
 )
 
-// iamManagerRoleNameSuffixAnnotation mirrors the constant in internal/config
-// (config imports this package, so we can't import config here without a
-// cycle). Iamrole CRs carrying this annotation are additional roles
-// and are counted separately by IamrolesCount.
-const iamManagerRoleNameSuffixAnnotation = "iammanager.keikoproj.io/additional-role"
-
 type Client struct {
 	cl  kubernetes.Interface
-	dCl dynamic.Interface
 	rCl client.Client
 }
 
@@ -50,14 +41,8 @@ func NewK8sClient() (*Client, error) {
 		return nil, err
 	}
 
-	dClient, err := dynamic.NewForConfig(config)
-	if err != nil {
-		return nil, err
-	}
-
 	cl := &Client{
-		cl:  client,
-		dCl: dClient,
+		cl: client,
 	}
 	return cl, nil
 }
@@ -76,14 +61,8 @@ func NewK8sClientDoOrDie() *Client {
 		panic(err)
 	}
 
-	dClient, err := dynamic.NewForConfig(config)
-	if err != nil {
-		panic(err)
-	}
-
 	cl := &Client{
-		cl:  client,
-		dCl: dClient,
+		cl: client,
 	}
 	return cl
 }
@@ -99,61 +78,31 @@ func NewK8sManagerClient(client client.Client) *Client {
 
 // Iface defines required functions to be implemented by receivers
 type Iface interface {
-	IamrolesCount(ctx context.Context, ns string) (int, int, error)
-	GetConfigMap(ctx context.Context, ns string, name string) *v1.ConfigMap
+	GetConfigMap(ctx context.Context, ns string, name string) (*v1.ConfigMap, error)
 	SetUpEventHandler(ctx context.Context) record.EventRecorder
 	GetNamespace(ctx context.Context, ns string) *v1.Namespace
-	CreateOrUpdateServiceAccount(ctx context.Context, saName string, ns string) error
+	CreateOrUpdateServiceAccount(ctx context.Context, req ServiceAccountRequest) (bool, error)
+	ReleaseServiceAccount(ctx context.Context, ns string, name string, owner string) error
 }
 
-// IamrolesCount lists Iamroles in the namespace and returns the counts of
-// non-additional (standard) roles and additional roles separately,
-// so callers can enforce independent per-namespace limits on each.
-func (c *Client) IamrolesCount(ctx context.Context, ns string) (int, int, error) {
-	log := logging.Logger(ctx, "k8s", "client", "IamrolesCount")
-	log.WithValues("namespace", ns)
-	log.V(1).Info("list api call")
-	iamCR := schema.GroupVersionResource{
-		Group:    "iammanager.keikoproj.io",
-		Version:  "v1alpha1",
-		Resource: "iamroles",
-	}
-
-	roleList, err := c.dCl.Resource(iamCR).Namespace(ns).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		log.Error(err, "unable to list iamroles resources")
-		return 0, 0, err
-	}
-
-	nonAdditional, additional := 0, 0
-	for _, item := range roleList.Items {
-		if _, ok := item.GetAnnotations()[iamManagerRoleNameSuffixAnnotation]; ok {
-			additional++
-			continue
-		}
-		nonAdditional++
-	}
-	log.Info("Iamrole count for limit enforcement", "non_additional_count", nonAdditional, "additional_count", additional, "total", len(roleList.Items))
-	return nonAdditional, additional, nil
-}
-
-func (c *Client) GetConfigMap(ctx context.Context, ns string, name string) *v1.ConfigMap {
+// GetConfigMap gets the config map from the namespace
+func (c *Client) GetConfigMap(ctx context.Context, ns string, name string) (*v1.ConfigMap, error) {
 	log := logging.Logger(ctx, "k8s", "client", "GetConfigMap")
-	log.WithValues("namespace", ns)
+	log = log.WithValues("namespace", ns)
 	log.Info("Retrieving config map")
 	res, err := c.cl.CoreV1().ConfigMaps(ns).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		log.Error(err, "unable to get config map")
-		panic(err)
+		return nil, err
 	}
 
-	return res
+	return res, nil
 }
 
 // GetNamespace gets the namespace metadata. This will be used to validate if the namespace is annotated for privileged namespace.
 func (c *Client) GetNamespace(ctx context.Context, ns string) (*v1.Namespace, error) {
 	log := logging.Logger(ctx, "k8s", "client", "GetNamespace")
-	log.WithValues("namespace", ns)
+	log = log.WithValues("namespace", ns)
 	log.Info("Retrieving Namespace")
 	resp := &v1.Namespace{}
 	resp, err := c.cl.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
@@ -206,7 +155,7 @@ func (c *Client) SetUpEventHandler(ctx context.Context) record.EventRecorder {
 // GetServiceAccount returns the service account with a given name in a given namespace
 func (c *Client) GetServiceAccount(ctx context.Context, ns string, name string) *v1.ServiceAccount {
 	log := logging.Logger(ctx, "k8s", "client", "GetServiceAccount")
-	log.WithValues("namespace", ns)
+	log = log.WithValues("namespace", ns)
 	log.Info("Retrieving service account")
 	sa := &v1.ServiceAccount{}
 	err := c.rCl.Get(ctx, client.ObjectKey{Name: name, Namespace: ns}, sa)
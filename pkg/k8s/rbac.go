@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -14,44 +17,226 @@ import (
 	"github.com/keikoproj/iam-manager/pkg/logging"
 )
 
-// CreateServiceAccount adds the service account
-func (c *Client) CreateOrUpdateServiceAccount(ctx context.Context, saName string, ns string, roleARN string, regionalEndpointDisabled bool) error {
+// The service account ownership annotations mirror the constants in internal/config
+// (config imports this package, so we can't import config here without a cycle)
+const (
+	roleARNAnnotation             = "eks.amazonaws.com/role-arn"
+	regionalEndpointAnnotation    = "eks.amazonaws.com/sts-regional-endpoints"
+	serviceAccountOwnerAnnotation = "iammanager.keikoproj.io/iamrole"
+	managedAnnotationsAnnotation  = "iammanager.keikoproj.io/managed-annotations"
+	managedLabelsAnnotation       = "iammanager.keikoproj.io/managed-labels"
+	managedByLabel                = "app.kubernetes.io/managed-by"
+	managedByValue                = "iam-manager"
+	managedKeysSeparator          = ","
+)
+
+// ErrServiceAccountNotFound is returned when the service account does not exist and must not be created
+var ErrServiceAccountNotFound = errors.New("service account not found")
+
+// ServiceAccountRequest describes the IRSA service account of an iam role
+type ServiceAccountRequest struct {
+	Name                     string
+	Namespace                string
+	Owner                    string
+	RoleARN                  string
+	RegionalEndpointDisabled bool
+	Annotations              map[string]string
+	Labels                   map[string]string
+	CreateIfMissing          bool
+}
+
+// CreateOrUpdateServiceAccount creates the service account or patches the existing one with the IRSA annotations.
+// The annotations and labels iam-manager adds are recorded on the service account so ReleaseServiceAccount removes
+// only those. It returns true when the service account was created
+func (c *Client) CreateOrUpdateServiceAccount(ctx context.Context, req ServiceAccountRequest) (bool, error) {
 	log := logging.Logger(ctx, "pkg.k8s", "rbac", "CreateOrUpdateServiceAccount")
+	ns := req.Namespace
 
-	sa := &corev1.ServiceAccount{
-		ObjectMeta: v1.ObjectMeta{
-			Name:      saName,
-			Namespace: ns,
-			Annotations: map[string]string{
-				"eks.amazonaws.com/role-arn": roleARN,
-			},
-		},
+	annotations := map[string]string{}
+	for key, value := range req.Annotations {
+		annotations[key] = value
 	}
-	if !regionalEndpointDisabled {
-		sa.ObjectMeta.Annotations["eks.amazonaws.com/sts-regional-endpoints"] = "true"
+	annotations[roleARNAnnotation] = req.RoleARN
+	if !req.RegionalEndpointDisabled {
+		annotations[regionalEndpointAnnotation] = "true"
 	}
 
-	//_, err := c.cl.CoreV1().ServiceAccounts(ns).Create(sa)
-	log.V(1).Info("Service Account creation is in progress")
-	err := c.rCl.Create(ctx, sa)
-	if err != nil {
-		if !apierr.IsAlreadyExists(err) {
+	existing := &corev1.ServiceAccount{}
+	err := c.rCl.Get(ctx, client.ObjectKey{Name: req.Name, Namespace: ns}, existing)
+	if err != nil && !apierr.IsNotFound(err) {
+		msg := fmt.Sprintf("Failed to get service account %s in namespace %s due to %v", req.Name, ns, err)
+		log.Error(err, msg)
+		return false, errors.New(msg)
+	}
+
+	if apierr.IsNotFound(err) {
+		if !req.CreateIfMissing {
+			log.Info("Service account does not exist and is not created", "serviceAccount", req.Name, "namespace", ns)
+			return false, ErrServiceAccountNotFound
+		}
+		sa := &corev1.ServiceAccount{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        req.Name,
+				Namespace:   ns,
+				Annotations: annotations,
+				Labels:      map[string]string{managedByLabel: managedByValue},
+			},
+		}
+		for key, value := range req.Labels {
+			sa.Labels[key] = value
+		}
+		sa.Annotations[managedAnnotationsAnnotation] = joinKeys(annotations)
+		if len(req.Labels) > 0 {
+			sa.Annotations[managedLabelsAnnotation] = joinKeys(req.Labels)
+		}
+		sa.Annotations[serviceAccountOwnerAnnotation] = req.Owner
+
+		log.V(1).Info("Service Account creation is in progress")
+		if err := c.rCl.Create(ctx, sa); err != nil {
 			msg := fmt.Sprintf("Failed to create service account %s in namespace %s due to %v", sa.Name, ns, err)
 			log.Error(err, msg)
-			return errors.New(msg)
+			return false, errors.New(msg)
 		}
-		annotationByte, _ := json.Marshal(sa.Annotations)
-		patchStr := fmt.Sprintf(`{"metadata":{"annotations":%s}}`, string(annotationByte))
-		log.Info("Service account already exists. Trying to update", "serviceAccount",
-			sa.Name, "namespace", ns, "annotation", patchStr)
-		// Use patch to avoid creating new token each reconcile
-		err = c.rCl.Patch(ctx, sa, client.RawPatch(types.MergePatchType, []byte(patchStr)))
-		if err != nil {
-			msg := fmt.Sprintf("Failed to update service account %s due to %v", sa.Name, err)
+		log.Info("Service account got created successfully", "serviceAccount", sa.Name, "namespace", ns)
+		return true, nil
+	}
+
+	// Annotations and labels which existed before iam-manager touched the service account are not managed.
+	// Service accounts annotated with this role by an older iam-manager only carry the IRSA annotations
+	previousAnnotations := splitKeys(existing.Annotations[managedAnnotationsAnnotation])
+	previousLabels := splitKeys(existing.Annotations[managedLabelsAnnotation])
+	legacy := existing.Annotations[serviceAccountOwnerAnnotation] == "" && existing.Annotations[roleARNAnnotation] == req.RoleARN
+
+	patchAnnotations := map[string]interface{}{}
+	var managedAnnotations []string
+	for key, value := range annotations {
+		patchAnnotations[key] = value
+		_, exists := existing.Annotations[key]
+		if !exists || slices.Contains(previousAnnotations, key) || (legacy && (key == roleARNAnnotation || key == regionalEndpointAnnotation)) {
+			managedAnnotations = append(managedAnnotations, key)
+		}
+	}
+	for _, key := range previousAnnotations {
+		if _, ok := annotations[key]; !ok {
+			patchAnnotations[key] = nil
+		}
+	}
+	patchLabels := map[string]interface{}{}
+	var managedLabels []string
+	for key, value := range req.Labels {
+		patchLabels[key] = value
+		if _, exists := existing.Labels[key]; !exists || slices.Contains(previousLabels, key) {
+			managedLabels = append(managedLabels, key)
+		}
+	}
+	for _, key := range previousLabels {
+		if _, ok := req.Labels[key]; !ok {
+			patchLabels[key] = nil
+		}
+	}
+	patchAnnotations[serviceAccountOwnerAnnotation] = req.Owner
+	patchAnnotations[managedAnnotationsAnnotation] = nullIfEmpty(managedAnnotations)
+	patchAnnotations[managedLabelsAnnotation] = nullIfEmpty(managedLabels)
+
+	metadata := map[string]interface{}{"annotations": patchAnnotations}
+	if len(patchLabels) > 0 {
+		metadata["labels"] = patchLabels
+	}
+	patchByte, _ := json.Marshal(map[string]interface{}{"metadata": metadata})
+	log.Info("Service account already exists. Trying to update", "serviceAccount",
+		req.Name, "namespace", ns, "patch", string(patchByte))
+	// Use patch to avoid creating new token each reconcile
+	if err := c.rCl.Patch(ctx, existing, client.RawPatch(types.MergePatchType, patchByte)); err != nil {
+		msg := fmt.Sprintf("Failed to update service account %s due to %v", req.Name, err)
+		log.Error(err, msg)
+		return false, errors.New(msg)
+	}
+	log.Info("Service account got updated successfully", "serviceAccount", req.Name, "namespace", ns)
+	return false, nil
+}
+
+// ReleaseServiceAccount undoes CreateOrUpdateServiceAccount for the owner. Service accounts created by iam-manager
+// are deleted and only the annotations and labels iam-manager added are removed from the other ones.
+// Service accounts annotated by another Iamrole are left alone
+func (c *Client) ReleaseServiceAccount(ctx context.Context, ns string, name string, owner string) error {
+	log := logging.Logger(ctx, "pkg.k8s", "rbac", "ReleaseServiceAccount")
+
+	sa := &corev1.ServiceAccount{}
+	if err := c.rCl.Get(ctx, client.ObjectKey{Name: name, Namespace: ns}, sa); err != nil {
+		if apierr.IsNotFound(err) {
+			return nil
+		}
+		msg := fmt.Sprintf("Failed to get service account %s in namespace %s due to %v", name, ns, err)
+		log.Error(err, msg)
+		return errors.New(msg)
+	}
+	if sa.Annotations[serviceAccountOwnerAnnotation] != owner {
+		log.Info("Service account is not owned by the iamrole. Skipping", "serviceAccount", name, "namespace", ns, "owner", sa.Annotations[serviceAccountOwnerAnnotation])
+		return nil
+	}
+
+	if sa.Labels[managedByLabel] == managedByValue {
+		if err := c.rCl.Delete(ctx, sa); err != nil && !apierr.IsNotFound(err) {
+			msg := fmt.Sprintf("Failed to delete service account %s in namespace %s due to %v", name, ns, err)
 			log.Error(err, msg)
 			return errors.New(msg)
 		}
+		log.Info("Service account got deleted successfully", "serviceAccount", name, "namespace", ns)
+		return nil
+	}
+
+	patchAnnotations := map[string]interface{}{
+		serviceAccountOwnerAnnotation: nil,
+		managedAnnotationsAnnotation:  nil,
+		managedLabelsAnnotation:       nil,
+	}
+	for _, key := range splitKeys(sa.Annotations[managedAnnotationsAnnotation]) {
+		patchAnnotations[key] = nil
+	}
+	metadata := map[string]interface{}{"annotations": patchAnnotations}
+	if labels := splitKeys(sa.Annotations[managedLabelsAnnotation]); len(labels) > 0 {
+		patchLabels := map[string]interface{}{}
+		for _, key := range labels {
+			patchLabels[key] = nil
+		}
+		metadata["labels"] = patchLabels
 	}
-	log.Info("Service account got created successfully", "serviceAccount", sa.Name, "namespace", ns)
+	patchByte, _ := json.Marshal(map[string]interface{}{"metadata": metadata})
+	if err := c.rCl.Patch(ctx, sa, client.RawPatch(types.MergePatchType, patchByte)); err != nil && !apierr.IsNotFound(err) {
+		msg := fmt.Sprintf("Failed to update service account %s due to %v", name, err)
+		log.Error(err, msg)
+		return errors.New(msg)
+	}
+	log.Info("Service account annotations got removed successfully", "serviceAccount", name, "namespace", ns, "patch", string(patchByte))
 	return nil
 }
+
+// joinKeys returns the sorted keys of the map joined for the bookkeeping annotations
+func joinKeys(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return strings.Join(keys, managedKeysSeparator)
+}
+
+// splitKeys parses a bookkeeping annotation
+func splitKeys(value string) []string {
+	var keys []string
+	for _, key := range strings.Split(value, managedKeysSeparator) {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// nullIfEmpty joins the keys or removes the bookkeeping annotation when there are none
+func nullIfEmpty(keys []string) interface{} {
+	if len(keys) == 0 {
+		return nil
+	}
+	slices.Sort(keys)
+	return strings.Join(keys, managedKeysSeparator)
+}
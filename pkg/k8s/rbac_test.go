@@ -0,0 +1,112 @@
+package k8s_test
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/check.v1"
+	corev1 "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/keikoproj/iam-manager/pkg/k8s"
+)
+
+const roleARN = "arn:aws:iam::123456789012:role/k8s-test-ns"
+
+type RbacSuite struct {
+	t   *testing.T
+	ctx context.Context
+	cl  client.Client
+}
+
+func TestRbacSuite(t *testing.T) {
+	check.Suite(&RbacSuite{t: t})
+	check.TestingT(t)
+}
+
+func (s *RbacSuite) SetUpTest(c *check.C) {
+	s.ctx = context.Background()
+	s.cl = fake.NewClientBuilder().Build()
+}
+
+func (s *RbacSuite) getServiceAccount(c *check.C, name string) *corev1.ServiceAccount {
+	sa := &corev1.ServiceAccount{}
+	c.Assert(s.cl.Get(s.ctx, client.ObjectKey{Name: name, Namespace: "test-ns"}, sa), check.IsNil)
+	return sa
+}
+
+func (s *RbacSuite) TestCreateAndReleaseServiceAccount(c *check.C) {
+	created, err := k8s.NewK8sManagerClient(s.cl).CreateOrUpdateServiceAccount(s.ctx, k8s.ServiceAccountRequest{
+		Name: "app", Namespace: "test-ns", Owner: "iamrole", RoleARN: roleARN, Labels: map[string]string{"team": "orders"}, CreateIfMissing: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(created, check.Equals, true)
+	sa := s.getServiceAccount(c, "app")
+	c.Assert(sa.Annotations["eks.amazonaws.com/role-arn"], check.Equals, roleARN)
+	c.Assert(sa.Labels["app.kubernetes.io/managed-by"], check.Equals, "iam-manager")
+
+	// Another Iamrole does not own it
+	c.Assert(k8s.NewK8sManagerClient(s.cl).ReleaseServiceAccount(s.ctx, "test-ns", "app", "other"), check.IsNil)
+	s.getServiceAccount(c, "app")
+
+	c.Assert(k8s.NewK8sManagerClient(s.cl).ReleaseServiceAccount(s.ctx, "test-ns", "app", "iamrole"), check.IsNil)
+	err = s.cl.Get(s.ctx, client.ObjectKey{Name: "app", Namespace: "test-ns"}, &corev1.ServiceAccount{})
+	c.Assert(apierr.IsNotFound(err), check.Equals, true)
+}
+
+func (s *RbacSuite) TestPatchAndReleaseServiceAccount(c *check.C) {
+	c.Assert(s.cl.Create(s.ctx, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+		Name:        "app",
+		Namespace:   "test-ns",
+		Annotations: map[string]string{"eks.amazonaws.com/sts-regional-endpoints": "true", "owner": "team"},
+		Labels:      map[string]string{"team": "orders"},
+	}}), check.IsNil)
+
+	created, err := k8s.NewK8sManagerClient(s.cl).CreateOrUpdateServiceAccount(s.ctx, k8s.ServiceAccountRequest{
+		Name: "app", Namespace: "test-ns", Owner: "iamrole", RoleARN: roleARN,
+		Annotations: map[string]string{"example.com/extra": "value"}, Labels: map[string]string{"team": "orders", "app": "orders"},
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(created, check.Equals, false)
+	sa := s.getServiceAccount(c, "app")
+	c.Assert(sa.Annotations["eks.amazonaws.com/role-arn"], check.Equals, roleARN)
+	c.Assert(sa.Annotations["example.com/extra"], check.Equals, "value")
+	c.Assert(sa.Labels["app"], check.Equals, "orders")
+
+	// Only what iam-manager added is removed
+	c.Assert(k8s.NewK8sManagerClient(s.cl).ReleaseServiceAccount(s.ctx, "test-ns", "app", "iamrole"), check.IsNil)
+	sa = s.getServiceAccount(c, "app")
+	c.Assert(sa.Annotations, check.DeepEquals, map[string]string{"eks.amazonaws.com/sts-regional-endpoints": "true", "owner": "team"})
+	c.Assert(sa.Labels, check.DeepEquals, map[string]string{"team": "orders"})
+}
+
+func (s *RbacSuite) TestUpdateServiceAccountRemovesStaleAnnotations(c *check.C) {
+	req := k8s.ServiceAccountRequest{
+		Name: "app", Namespace: "test-ns", Owner: "iamrole", RoleARN: roleARN,
+		Annotations: map[string]string{"example.com/extra": "value"}, CreateIfMissing: true,
+	}
+	_, err := k8s.NewK8sManagerClient(s.cl).CreateOrUpdateServiceAccount(s.ctx, req)
+	c.Assert(err, check.IsNil)
+
+	req.Annotations = nil
+	req.RegionalEndpointDisabled = true
+	created, err := k8s.NewK8sManagerClient(s.cl).CreateOrUpdateServiceAccount(s.ctx, req)
+	c.Assert(err, check.IsNil)
+	c.Assert(created, check.Equals, false)
+	sa := s.getServiceAccount(c, "app")
+	_, ok := sa.Annotations["example.com/extra"]
+	c.Assert(ok, check.Equals, false)
+	_, ok = sa.Annotations["eks.amazonaws.com/sts-regional-endpoints"]
+	c.Assert(ok, check.Equals, false)
+	c.Assert(sa.Annotations["eks.amazonaws.com/role-arn"], check.Equals, roleARN)
+}
+
+func (s *RbacSuite) TestUpdateMissingServiceAccount(c *check.C) {
+	_, err := k8s.NewK8sManagerClient(s.cl).CreateOrUpdateServiceAccount(s.ctx, k8s.ServiceAccountRequest{
+		Name: "app", Namespace: "test-ns", Owner: "iamrole", RoleARN: roleARN,
+	})
+	c.Assert(err, check.Equals, k8s.ErrServiceAccountNotFound)
+}
@@ -2,39 +2,124 @@ package logging
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"strconv"
 
 	"github.com/go-logr/logr"
+	"go.uber.org/zap/zapcore"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
-//Set up with  Controller based on the level
+const (
+	EncoderJSON    = "json"
+	EncoderConsole = "console"
+)
+
+// requestIDKey is the context key of the request id
+type requestIDKey struct{}
+
+// valuesKey is the context key of the key/value pairs added to every logger of the request
+type valuesKey struct{}
+
+// Options configures the logger of the manager
+type Options struct {
+	// Debug enables the development mode: console encoder, debug level and stacktraces on warnings
+	Debug bool
+	// Level is debug, info, error or a verbosity, e.g. 2 to also log V(2) messages. Defaults to debug in debug mode and info otherwise
+	Level string
+	// Encoder is json or console. Defaults to console in debug mode and json otherwise
+	Encoder string
+}
 
-//Get the request id
+// BindFlags registers the --debug, --log-level and --log-encoder flags
+func (o *Options) BindFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.Debug, "debug", false, "Enable Debug?")
+	fs.StringVar(&o.Level, "log-level", "", "The log level: debug, info, error or a verbosity, e.g. 2. Defaults to debug with --debug and info otherwise")
+	fs.StringVar(&o.Encoder, "log-encoder", "", "The log encoder: json or console. Defaults to console with --debug and json otherwise")
+}
+
+// New sets up the logger of the controller runtime from the options
+func New(opts Options) error {
+	level, err := parseLevel(opts.Level, opts.Debug)
+	if err != nil {
+		return err
+	}
+	encoder := opts.Encoder
+	if encoder == "" {
+		encoder = EncoderJSON
+		if opts.Debug {
+			encoder = EncoderConsole
+		}
+	}
+	zapOpts := []zap.Opts{zap.UseDevMode(opts.Debug), zap.Level(level)}
+	switch encoder {
+	case EncoderJSON:
+		zapOpts = append(zapOpts, zap.JSONEncoder())
+	case EncoderConsole:
+		zapOpts = append(zapOpts, zap.ConsoleEncoder())
+	default:
+		return fmt.Errorf("unknown log encoder %q, must be %s or %s", encoder, EncoderJSON, EncoderConsole)
+	}
+	ctrl.SetLogger(zap.New(zapOpts...))
+	return nil
+}
 
-// New function sets the logging level based on the flag and also sets it with controller
-func New(debug ...bool) {
-	enabled := false
-	if len(debug) == 0 {
-		enabled = true
-	} else {
-		enabled = debug[0]
+// parseLevel converts the log level to a zap level. The verbosity n of logr is the zap level -n
+func parseLevel(level string, debug bool) (zapcore.Level, error) {
+	switch level {
+	case "":
+		if debug {
+			return zapcore.DebugLevel, nil
+		}
+		return zapcore.InfoLevel, nil
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	}
+	verbosity, err := strconv.Atoi(level)
+	if err != nil || verbosity < 0 {
+		return 0, fmt.Errorf("invalid log level %q, must be debug, info, error or a verbosity", level)
 	}
-	ctrl.SetLogger(zap.New(func(o *zap.Options) {
-		o.Development = enabled
-	}))
+	return zapcore.Level(-verbosity), nil
+}
+
+// WithRequestID returns a context whose loggers carry the request id
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request id of the context or an empty string
+func RequestID(ctx context.Context) string {
+	rId, _ := ctx.Value(requestIDKey{}).(string)
+	return rId
+}
+
+// WithValues returns a context whose loggers carry the key/value pairs, e.g. the namespace and name of the Iamrole
+func WithValues(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	existing, _ := ctx.Value(valuesKey{}).([]interface{})
+	merged := make([]interface{}, 0, len(existing)+len(keysAndValues))
+	merged = append(merged, existing...)
+	merged = append(merged, keysAndValues...)
+	return context.WithValue(ctx, valuesKey{}, merged)
 }
 
-// Logger with
+// Logger returns the logger named after the names, e.g. "awsapi.iam.EnsureRole", with the request id and
+// the key/value pairs of the context
 func Logger(ctx context.Context, names ...string) logr.Logger {
 	logk := ctrl.Log
 	for _, name := range names {
-		logk.WithName(name)
+		logk = logk.WithName(name)
 	}
-	rId := ctx.Value("request_id")
-	if rId != nil {
-		logk.WithValues("request_id", rId)
+	if rId := RequestID(ctx); rId != "" {
+		logk = logk.WithValues("request_id", rId)
+	}
+	if values, ok := ctx.Value(valuesKey{}).([]interface{}); ok {
+		logk = logk.WithValues(values...)
 	}
-
 	return logk
 }
@@ -0,0 +1,63 @@
+package logging_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"gopkg.in/check.v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/keikoproj/iam-manager/pkg/logging"
+)
+
+type LoggingSuite struct {
+	t     *testing.T
+	ctx   context.Context
+	lines []string
+}
+
+func TestLoggingSuite(t *testing.T) {
+	check.Suite(&LoggingSuite{t: t})
+	check.TestingT(t)
+}
+
+func (s *LoggingSuite) SetUpSuite(c *check.C) {
+	ctrl.SetLogger(funcr.New(func(prefix, args string) {
+		s.lines = append(s.lines, fmt.Sprintf("%s %s", prefix, args))
+	}, funcr.Options{}))
+}
+
+func (s *LoggingSuite) SetUpTest(c *check.C) {
+	s.ctx = context.Background()
+	s.lines = nil
+}
+
+func (s *LoggingSuite) TestLoggerWithoutContextValues(c *check.C) {
+	logging.Logger(s.ctx, "awsapi", "iam", "GetRole").Info("Initiating api call")
+	c.Assert(s.lines, check.DeepEquals, []string{`awsapi/iam/GetRole "level"=0 "msg"="Initiating api call"`})
+}
+
+func (s *LoggingSuite) TestLoggerWithContextValues(c *check.C) {
+	ctx := logging.WithRequestID(s.ctx, "8a1c1b04")
+	ctx = logging.WithValues(ctx, "namespace", "default")
+	ctx = logging.WithValues(ctx, "iamrole", "iamrole")
+	c.Assert(logging.RequestID(ctx), check.Equals, "8a1c1b04")
+
+	logging.Logger(ctx, "awsapi", "iam", "GetRole").WithValues("roleName", "k8s-default").Info("Initiating api call")
+	c.Assert(s.lines, check.DeepEquals, []string{
+		`awsapi/iam/GetRole "level"=0 "msg"="Initiating api call" "request_id"="8a1c1b04" "namespace"="default" "iamrole"="iamrole" "roleName"="k8s-default"`,
+	})
+}
+
+func (s *LoggingSuite) TestRequestIDIsNotAStringKey(c *check.C) {
+	ctx := context.WithValue(s.ctx, "request_id", "8a1c1b04")
+	c.Assert(logging.RequestID(ctx), check.Equals, "")
+}
+
+func (s *LoggingSuite) TestNewWithInvalidOptions(c *check.C) {
+	c.Assert(logging.New(logging.Options{Level: "verbose"}), check.ErrorMatches, `invalid log level "verbose".*`)
+	c.Assert(logging.New(logging.Options{Level: "-1"}), check.ErrorMatches, `invalid log level "-1".*`)
+	c.Assert(logging.New(logging.Options{Encoder: "xml"}), check.ErrorMatches, `unknown log encoder "xml".*`)
+}
@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/keikoproj/iam-manager/pkg/logging"
+)
+
+const (
+	tracerName  = "github.com/keikoproj/iam-manager"
+	serviceName = "iam-manager"
+)
+
+// Attribute keys used on the iam-manager spans
+const (
+	RequestIDKey    = attribute.Key("request_id")
+	NamespaceKey    = attribute.Key("k8s.namespace.name")
+	IamroleKey      = attribute.Key("iammanager.iamrole")
+	RoleNameKey     = attribute.Key("aws.iam.role_name")
+	AWSErrorCodeKey = attribute.Key("aws.error.code")
+)
+
+type attributesKey struct{}
+
+// Setup installs an OTLP/gRPC tracer provider when an endpoint is given, either through the endpoint
+// argument or the standard OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_TRACES_ENDPOINT env variables.
+// Otherwise the global no-op tracer provider is left as is. The returned function flushes and stops the exporter
+func Setup(ctx context.Context, endpoint string, insecure bool) (func(context.Context) error, error) {
+	log := logging.Logger(ctx, "pkg.tracing", "Setup")
+	if endpoint == "" && os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		log.V(1).Info("No OTLP endpoint is configured, tracing is disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var opts []otlptracegrpc.Option
+	if endpoint != "" {
+		opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+	}
+	if insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the OTLP trace exporter: %v", err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence over the default service name
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the trace resource: %v", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	log.Info("Tracing is enabled", "endpoint", endpoint)
+	return tp.Shutdown, nil
+}
+
+// WithAttributes returns a context whose spans all carry the attributes, e.g. the namespace of the Iamrole
+func WithAttributes(ctx context.Context, attrs ...attribute.KeyValue) context.Context {
+	existing, _ := ctx.Value(attributesKey{}).([]attribute.KeyValue)
+	merged := make([]attribute.KeyValue, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, attributesKey{}, merged)
+}
+
+// Start starts a span with the attributes of the context and its request id
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	all, _ := ctx.Value(attributesKey{}).([]attribute.KeyValue)
+	all = append(all[:len(all):len(all)], attrs...)
+	if rId := logging.RequestID(ctx); rId != "" {
+		all = append(all, RequestIDKey.String(rId))
+	}
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(all...))
+}
+
+// End records the error, with its AWS error code if there is one, and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) {
+			span.SetAttributes(AWSErrorCodeKey.String(aerr.Code()))
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
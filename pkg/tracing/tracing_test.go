@@ -0,0 +1,96 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gopkg.in/check.v1"
+
+	"github.com/keikoproj/iam-manager/pkg/logging"
+	"github.com/keikoproj/iam-manager/pkg/tracing"
+)
+
+type TracingSuite struct {
+	t        *testing.T
+	ctx      context.Context
+	recorder *tracetest.SpanRecorder
+}
+
+func TestTracingSuite(t *testing.T) {
+	check.Suite(&TracingSuite{t: t})
+	check.TestingT(t)
+}
+
+func (s *TracingSuite) SetUpTest(c *check.C) {
+	s.ctx = context.Background()
+	s.recorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder)))
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	attrs := map[attribute.Key]string{}
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value.Emit()
+	}
+	return attrs
+}
+
+func (s *TracingSuite) TestSetupWithoutEndpoint(c *check.C) {
+	s.t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	s.t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	shutdown, err := tracing.Setup(s.ctx, "", false)
+	c.Assert(err, check.IsNil)
+	c.Assert(shutdown(s.ctx), check.IsNil)
+}
+
+func (s *TracingSuite) TestStartWithContextAttributes(c *check.C) {
+	ctx := logging.WithRequestID(s.ctx, "8a1c1b04")
+	ctx = tracing.WithAttributes(ctx, tracing.NamespaceKey.String("default"), tracing.IamroleKey.String("iamrole"))
+	ctx, parent := tracing.Start(ctx, "Reconcile")
+	_, child := tracing.Start(ctx, "awsapi.GetRole", tracing.RoleNameKey.String("k8s-default"))
+	tracing.End(child, nil)
+	tracing.End(parent, nil)
+
+	spans := s.recorder.Ended()
+	c.Assert(spans, check.HasLen, 2)
+	c.Assert(spans[0].Name(), check.Equals, "awsapi.GetRole")
+	c.Assert(spans[0].Parent().SpanID(), check.Equals, spans[1].SpanContext().SpanID())
+	c.Assert(attributes(spans[0]), check.DeepEquals, map[attribute.Key]string{
+		tracing.NamespaceKey: "default",
+		tracing.IamroleKey:   "iamrole",
+		tracing.RoleNameKey:  "k8s-default",
+		tracing.RequestIDKey: "8a1c1b04",
+	})
+	c.Assert(spans[0].Status().Code, check.Equals, codes.Unset)
+	c.Assert(attributes(spans[1])[tracing.RoleNameKey], check.Equals, "")
+}
+
+func (s *TracingSuite) TestEndWithAWSError(c *check.C) {
+	_, span := tracing.Start(s.ctx, "awsapi.DeleteRole")
+	tracing.End(span, fmt.Errorf("unable to delete the role: %w", awserr.New("DeleteConflict", "role has attached policies", nil)))
+
+	spans := s.recorder.Ended()
+	c.Assert(spans, check.HasLen, 1)
+	c.Assert(spans[0].Status().Code, check.Equals, codes.Error)
+	c.Assert(attributes(spans[0])[tracing.AWSErrorCodeKey], check.Equals, "DeleteConflict")
+	c.Assert(spans[0].Events(), check.HasLen, 1)
+}
+
+func (s *TracingSuite) TestEndWithError(c *check.C) {
+	_, span := tracing.Start(s.ctx, "ValidateCreate")
+	tracing.End(span, errors.New("Iamrole.iammanager.keikoproj.io \"iamrole\" is invalid"))
+
+	spans := s.recorder.Ended()
+	c.Assert(spans, check.HasLen, 1)
+	c.Assert(spans[0].Status().Code, check.Equals, codes.Error)
+	_, ok := attributes(spans[0])[tracing.AWSErrorCodeKey]
+	c.Assert(ok, check.Equals, false)
+}
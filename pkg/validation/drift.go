@@ -0,0 +1,361 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+
+	"github.com/keikoproj/iam-manager/api/v1alpha1"
+	"github.com/keikoproj/iam-manager/pkg/awsapi"
+	"github.com/keikoproj/iam-manager/pkg/logging"
+)
+
+// Kinds of DriftChange, from the point of view of AWS: added means found in AWS but not in the spec
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "changed"
+)
+
+// DriftChange is one difference between the iam role in AWS and the spec
+type DriftChange struct {
+	// Component is the drifted component, e.g. DriftPolicy
+	Component string
+	// Subject is what differs, e.g. inline policy default statement 2 Action
+	Subject string
+	// Change is ChangeAdded, ChangeRemoved or ChangeModified
+	Change string
+	// Spec and AWS are the values in the spec and in AWS, if any
+	Spec string
+	AWS  string
+}
+
+// String describes the change, e.g. inline policy default statement 1 Action: added s3:DeleteObject
+func (c DriftChange) String() string {
+	switch {
+	case c.Change == ChangeAdded && c.AWS != "":
+		return fmt.Sprintf("%s: added %s", c.Subject, c.AWS)
+	case c.Change == ChangeRemoved && c.Spec != "":
+		return fmt.Sprintf("%s: removed %s", c.Subject, c.Spec)
+	case c.Change == ChangeModified:
+		return fmt.Sprintf("%s: changed from %s to %s", c.Subject, c.Spec, c.AWS)
+	}
+	return fmt.Sprintf("%s: %s", c.Subject, c.Change)
+}
+
+// DescribeDrift describes every difference between the iam role in AWS and the spec, which RoleDrift and
+// CompareManagedPolicies only report as drifted components
+// targetRolePolicies holds the inline policy documents present in AWS keyed by policy name
+func DescribeDrift(ctx context.Context, request awsapi.IAMRoleRequest, targetRole *iam.GetRoleOutput, targetRolePolicies map[string]string, attachedPolicies []string) []DriftChange {
+	var changes []DriftChange
+
+	// Inline policies
+	desired := map[string]string{request.PolicyName: request.PermissionPolicy}
+	for policyName, policy := range request.InlinePolicies {
+		desired[policyName] = policy
+	}
+	for _, policyName := range slices.Sorted(maps.Keys(desired)) {
+		subject := "inline policy " + policyName
+		target, ok := targetRolePolicies[policyName]
+		if !ok {
+			changes = append(changes, DriftChange{Component: DriftPolicy, Subject: subject, Change: ChangeRemoved})
+			continue
+		}
+		changes = append(changes, diffPermissionPolicy(ctx, subject, desired[policyName], target)...)
+	}
+	for _, policyName := range request.PreviousInlinePolicies {
+		if _, ok := desired[policyName]; ok {
+			continue
+		}
+		if _, ok := targetRolePolicies[policyName]; ok {
+			changes = append(changes, DriftChange{Component: DriftPolicy, Subject: "inline policy " + policyName, Change: ChangeAdded})
+		}
+	}
+
+	// Trust policy
+	changes = append(changes, diffAssumeRolePolicy(ctx, request.TrustPolicy, aws.StringValue(targetRole.Role.AssumeRolePolicyDocument))...)
+
+	// Permission boundary
+	targetBoundary := ""
+	if targetRole.Role.PermissionsBoundary != nil {
+		targetBoundary = aws.StringValue(targetRole.Role.PermissionsBoundary.PermissionsBoundaryArn)
+	}
+	if request.ManagedPermissionBoundaryPolicy != targetBoundary {
+		changes = append(changes, DriftChange{Component: DriftBoundary, Subject: "permission boundary", Change: ChangeModified, Spec: orNone(request.ManagedPermissionBoundaryPolicy), AWS: orNone(targetBoundary)})
+	}
+
+	// Tags. Tags which were never attached by iam-manager are ignored
+	targetTags := map[string]string{}
+	for _, tag := range managedTags(request, targetRole.Role.Tags) {
+		targetTags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+	for _, key := range slices.Sorted(maps.Keys(request.Tags)) {
+		value, ok := targetTags[key]
+		switch {
+		case !ok:
+			changes = append(changes, DriftChange{Component: DriftTags, Subject: "tag " + key, Change: ChangeRemoved, Spec: request.Tags[key]})
+		case value != request.Tags[key]:
+			changes = append(changes, DriftChange{Component: DriftTags, Subject: "tag " + key, Change: ChangeModified, Spec: request.Tags[key], AWS: value})
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(targetTags)) {
+		if _, ok := request.Tags[key]; !ok {
+			changes = append(changes, DriftChange{Component: DriftTags, Subject: "tag " + key, Change: ChangeAdded, AWS: targetTags[key]})
+		}
+	}
+
+	// Role settings
+	if request.Description != aws.StringValue(targetRole.Role.Description) {
+		changes = append(changes, DriftChange{Component: DriftSettings, Subject: "description", Change: ChangeModified, Spec: strconv.Quote(request.Description), AWS: strconv.Quote(aws.StringValue(targetRole.Role.Description))})
+	}
+	if request.SessionDuration != aws.Int64Value(targetRole.Role.MaxSessionDuration) {
+		changes = append(changes, DriftChange{Component: DriftSettings, Subject: "max session duration", Change: ChangeModified, Spec: strconv.FormatInt(request.SessionDuration, 10), AWS: strconv.FormatInt(aws.Int64Value(targetRole.Role.MaxSessionDuration), 10)})
+	}
+	if request.Path != "" && request.Path != aws.StringValue(targetRole.Role.Path) {
+		changes = append(changes, DriftChange{Component: DriftSettings, Subject: "path", Change: ChangeModified, Spec: request.Path, AWS: aws.StringValue(targetRole.Role.Path)})
+	}
+
+	// Managed policies
+	for _, policy := range request.ManagedPolicies {
+		if policy != "" && !ContainsString(attachedPolicies, policy) {
+			changes = append(changes, DriftChange{Component: DriftManagedPolicies, Subject: "managed policy " + policy, Change: ChangeRemoved})
+		}
+	}
+	for _, policy := range request.PreviousManagedPolicies {
+		if !ContainsString(request.ManagedPolicies, policy) && ContainsString(attachedPolicies, policy) {
+			changes = append(changes, DriftChange{Component: DriftManagedPolicies, Subject: "managed policy " + policy, Change: ChangeAdded})
+		}
+	}
+	return changes
+}
+
+// diffPermissionPolicy describes the statements of an inline policy which differ between the spec and AWS
+func diffPermissionPolicy(ctx context.Context, subject string, request string, target string) []DriftChange {
+	log := logging.Logger(ctx, "pkg.validation", "diffPermissionPolicy")
+
+	d, _ := url.QueryUnescape(target)
+	req, dest := v1alpha1.PolicyDocument{}, v1alpha1.PolicyDocument{}
+	if err := json.Unmarshal([]byte(request), &req); err != nil {
+		log.Error(err, "failed to unmarshal policy document")
+		return []DriftChange{{Component: DriftPolicy, Subject: subject, Change: ChangeModified, Spec: request, AWS: d}}
+	}
+	if err := json.Unmarshal([]byte(d), &dest); err != nil {
+		log.Error(err, "failed to unmarshal policy document")
+		return []DriftChange{{Component: DriftPolicy, Subject: subject, Change: ChangeModified, Spec: request, AWS: d}}
+	}
+	normalizePolicyDocument(&req)
+	normalizePolicyDocument(&dest)
+
+	var changes []DriftChange
+	if req.Version != dest.Version {
+		changes = append(changes, DriftChange{Component: DriftPolicy, Subject: subject + " Version", Change: ChangeModified, Spec: req.Version, AWS: dest.Version})
+	}
+	pairs, removed, added := pairStatements(req.Statement, dest.Statement, func(s v1alpha1.Statement) string { return s.Sid })
+	for _, pair := range pairs {
+		spec, actual := req.Statement[pair[0]], dest.Statement[pair[1]]
+		statement := fmt.Sprintf("%s %s", subject, statementName(spec.Sid, pair[0]))
+		statementChanges := slices.Concat(
+			diffValue(DriftPolicy, statement+" Sid", spec.Sid, actual.Sid),
+			diffValue(DriftPolicy, statement+" Effect", string(spec.Effect), string(actual.Effect)),
+			diffList(DriftPolicy, statement+" Action", spec.Action, actual.Action),
+			diffList(DriftPolicy, statement+" NotAction", spec.NotAction, actual.NotAction),
+			diffList(DriftPolicy, statement+" Resource", spec.Resource, actual.Resource),
+			diffList(DriftPolicy, statement+" NotResource", spec.NotResource, actual.NotResource),
+			diffCondition(DriftPolicy, statement+" Condition", spec.Condition, actual.Condition),
+		)
+		if len(statementChanges) == 0 {
+			// Only the order of the values differs
+			statementChanges = diffValue(DriftPolicy, statement, compactJSON(spec), compactJSON(actual))
+		}
+		changes = append(changes, statementChanges...)
+	}
+	for _, i := range removed {
+		changes = append(changes, DriftChange{Component: DriftPolicy, Subject: fmt.Sprintf("%s %s", subject, statementName(req.Statement[i].Sid, i)), Change: ChangeRemoved, Spec: compactJSON(req.Statement[i])})
+	}
+	for _, i := range added {
+		changes = append(changes, DriftChange{Component: DriftPolicy, Subject: fmt.Sprintf("%s %s", subject, statementName(dest.Statement[i].Sid, i)), Change: ChangeAdded, AWS: compactJSON(dest.Statement[i])})
+	}
+	return changes
+}
+
+// diffAssumeRolePolicy describes the statements of the trust policy which differ between the spec and AWS,
+// down to the principals
+func diffAssumeRolePolicy(ctx context.Context, request string, target string) []DriftChange {
+	log := logging.Logger(ctx, "pkg.validation", "diffAssumeRolePolicy")
+	const subject = "trust policy"
+
+	a, _ := url.QueryUnescape(target)
+	reqAssume, destAssume := v1alpha1.AssumeRolePolicyDocument{}, v1alpha1.AssumeRolePolicyDocument{}
+	if err := json.Unmarshal([]byte(request), &reqAssume); err != nil {
+		log.Error(err, "failed to unmarshal assume role policy document")
+		return []DriftChange{{Component: DriftTrust, Subject: subject, Change: ChangeModified, Spec: request, AWS: a}}
+	}
+	if err := json.Unmarshal([]byte(a), &destAssume); err != nil {
+		log.Error(err, "failed to unmarshal assume role policy document")
+		return []DriftChange{{Component: DriftTrust, Subject: subject, Change: ChangeModified, Spec: request, AWS: a}}
+	}
+	normalizeAssumeRolePolicyDocument(&reqAssume)
+	normalizeAssumeRolePolicyDocument(&destAssume)
+
+	var changes []DriftChange
+	if reqAssume.Version != destAssume.Version {
+		changes = append(changes, DriftChange{Component: DriftTrust, Subject: subject + " Version", Change: ChangeModified, Spec: reqAssume.Version, AWS: destAssume.Version})
+	}
+	pairs, removed, added := pairStatements(reqAssume.Statement, destAssume.Statement, func(v1alpha1.TrustPolicyStatement) string { return "" })
+	for _, pair := range pairs {
+		spec, actual := reqAssume.Statement[pair[0]], destAssume.Statement[pair[1]]
+		statement := fmt.Sprintf("%s %s", subject, statementName("", pair[0]))
+		statementChanges := slices.Concat(
+			diffValue(DriftTrust, statement+" Effect", string(spec.Effect), string(actual.Effect)),
+			diffValue(DriftTrust, statement+" Action", spec.Action, actual.Action),
+			diffList(DriftTrust, statement+" Principal.AWS", spec.Principal.AWS, actual.Principal.AWS),
+			diffValue(DriftTrust, statement+" Principal.Service", spec.Principal.Service, actual.Principal.Service),
+			diffValue(DriftTrust, statement+" Principal.Federated", spec.Principal.Federated, actual.Principal.Federated),
+			diffCondition(DriftTrust, statement+" Condition", spec.Condition, actual.Condition),
+		)
+		if len(statementChanges) == 0 {
+			// Only the order of the values differs
+			statementChanges = diffValue(DriftTrust, statement, compactJSON(spec), compactJSON(actual))
+		}
+		changes = append(changes, statementChanges...)
+	}
+	for _, i := range removed {
+		changes = append(changes, DriftChange{Component: DriftTrust, Subject: fmt.Sprintf("%s %s", subject, statementName("", i)), Change: ChangeRemoved, Spec: compactJSON(reqAssume.Statement[i])})
+	}
+	for _, i := range added {
+		changes = append(changes, DriftChange{Component: DriftTrust, Subject: fmt.Sprintf("%s %s", subject, statementName("", i)), Change: ChangeAdded, AWS: compactJSON(destAssume.Statement[i])})
+	}
+	return changes
+}
+
+// pairStatements matches the statements of the spec with the ones in AWS. Equal statements are left out, the
+// others are paired by sid and then in order. The indexes of the spec statements missing in AWS are returned as
+// removed, the ones of the AWS statements missing in the spec as added
+func pairStatements[S any](spec []S, target []S, sid func(S) string) (pairs [][2]int, removed []int, added []int) {
+	matched := make([]bool, len(target))
+	// unmatchedIndex returns the first AWS statement which is not matched yet and satisfies f
+	unmatchedIndex := func(f func(S) bool) int {
+		for j := range target {
+			if !matched[j] && f(target[j]) {
+				return j
+			}
+		}
+		return -1
+	}
+
+	var unmatched []int
+	for i := range spec {
+		if j := unmatchedIndex(func(s S) bool { return reflect.DeepEqual(spec[i], s) }); j >= 0 {
+			matched[j] = true
+			continue
+		}
+		unmatched = append(unmatched, i)
+	}
+	// Statements which kept their sid were changed
+	var rest []int
+	for _, i := range unmatched {
+		j := -1
+		if sid(spec[i]) != "" {
+			j = unmatchedIndex(func(s S) bool { return sid(s) == sid(spec[i]) })
+		}
+		if j < 0 {
+			rest = append(rest, i)
+			continue
+		}
+		matched[j] = true
+		pairs = append(pairs, [2]int{i, j})
+	}
+	// The others are paired in order
+	for _, i := range rest {
+		j := unmatchedIndex(func(S) bool { return true })
+		if j < 0 {
+			removed = append(removed, i)
+			continue
+		}
+		matched[j] = true
+		pairs = append(pairs, [2]int{i, j})
+	}
+	for j := range target {
+		if !matched[j] {
+			added = append(added, j)
+		}
+	}
+	return pairs, removed, added
+}
+
+// statementName names a statement by its sid, or by its position in the policy
+func statementName(sid string, i int) string {
+	if sid != "" {
+		return "statement " + strconv.Quote(sid)
+	}
+	return fmt.Sprintf("statement %d", i+1)
+}
+
+// diffValue describes a single value which differs
+func diffValue(component string, subject string, spec string, target string) []DriftChange {
+	switch {
+	case spec == target:
+		return nil
+	case spec == "":
+		return []DriftChange{{Component: component, Subject: subject, Change: ChangeAdded, AWS: target}}
+	case target == "":
+		return []DriftChange{{Component: component, Subject: subject, Change: ChangeRemoved, Spec: spec}}
+	}
+	return []DriftChange{{Component: component, Subject: subject, Change: ChangeModified, Spec: spec, AWS: target}}
+}
+
+// diffList describes the values added to or removed from a list, e.g. the actions of a statement
+func diffList(component string, subject string, spec []string, target []string) []DriftChange {
+	var changes []DriftChange
+	for _, value := range spec {
+		if !slices.Contains(target, value) {
+			changes = append(changes, DriftChange{Component: component, Subject: subject, Change: ChangeRemoved, Spec: value})
+		}
+	}
+	for _, value := range target {
+		if !slices.Contains(spec, value) {
+			changes = append(changes, DriftChange{Component: component, Subject: subject, Change: ChangeAdded, AWS: value})
+		}
+	}
+	return changes
+}
+
+// diffCondition describes a condition block which differs. Condition blocks are compared as a whole
+func diffCondition(component string, subject string, spec v1alpha1.PolicyCondition, target v1alpha1.PolicyCondition) []DriftChange {
+	if reflect.DeepEqual(spec, target) {
+		return nil
+	}
+	specJSON, targetJSON := "", ""
+	if len(spec) > 0 {
+		specJSON = compactJSON(spec)
+	}
+	if len(target) > 0 {
+		targetJSON = compactJSON(target)
+	}
+	return diffValue(component, subject, specJSON, targetJSON)
+}
+
+// compactJSON renders a statement or a condition block for a DriftChange
+func compactJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return string(b)
+}
+
+// orNone returns the value, or none when it is empty
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
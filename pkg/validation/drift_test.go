@@ -0,0 +1,152 @@
+package validation_test
+
+import (
+	"net/url"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"gopkg.in/check.v1"
+
+	"github.com/keikoproj/iam-manager/internal/config"
+	"github.com/keikoproj/iam-manager/pkg/awsapi"
+	"github.com/keikoproj/iam-manager/pkg/validation"
+)
+
+const (
+	driftPolicy      = `{"Version":"2012-10-17","Statement":[{"Sid":"Read","Effect":"Allow","Action":["s3:GetObject"],"Resource":["arn:aws:s3:::bucket/*"]},{"Effect":"Allow","Action":["sqs:SendMessage"],"Resource":["*"]}]}`
+	driftTrustPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":["arn:aws:iam::123456789012:role/trust_role"]}}]}`
+)
+
+func driftRequest() awsapi.IAMRoleRequest {
+	return awsapi.IAMRoleRequest{
+		PolicyName:                      config.InlinePolicyName,
+		PermissionPolicy:                driftPolicy,
+		InlinePolicies:                  map[string]string{"dynamodb": `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["dynamodb:GetItem"],"Resource":["*"]}]}`},
+		PreviousInlinePolicies:          []string{"sns"},
+		TrustPolicy:                     driftTrustPolicy,
+		ManagedPermissionBoundaryPolicy: "arn:aws:iam::123456789012:policy/k8s-boundary",
+		ManagedPolicies:                 []string{"arn:aws:iam::123456789012:policy/shared"},
+		PreviousManagedPolicies:         []string{"arn:aws:iam::123456789012:policy/old"},
+		SessionDuration:                 3600,
+		Description:                     "app role",
+		Tags:                            map[string]string{"team": "payments", "owner": "alice"},
+		PreviousTags:                    []string{"cost-center"},
+	}
+}
+
+func driftTarget() *iam.GetRoleOutput {
+	return &iam.GetRoleOutput{
+		Role: &iam.Role{
+			AssumeRolePolicyDocument: aws.String(url.QueryEscape(driftTrustPolicy)),
+			PermissionsBoundary:      &iam.AttachedPermissionsBoundary{PermissionsBoundaryArn: aws.String("arn:aws:iam::123456789012:policy/k8s-boundary")},
+			MaxSessionDuration:       aws.Int64(3600),
+			Description:              aws.String("app role"),
+			Tags: []*iam.Tag{
+				{Key: aws.String("team"), Value: aws.String("payments")},
+				{Key: aws.String("owner"), Value: aws.String("alice")},
+				{Key: aws.String("unmanaged"), Value: aws.String("ignored")},
+			},
+		},
+	}
+}
+
+func driftTargetPolicies() map[string]string {
+	return map[string]string{
+		config.InlinePolicyName: url.QueryEscape(driftPolicy),
+		"dynamodb":              `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["dynamodb:GetItem"],"Resource":["*"]}]}`,
+	}
+}
+
+func driftStrings(changes []validation.DriftChange) []string {
+	var descriptions []string
+	for _, change := range changes {
+		descriptions = append(descriptions, change.String())
+	}
+	return descriptions
+}
+
+func (s *ValidateSuite) TestDescribeDriftNoDrift(c *check.C) {
+	attached := []string{"arn:aws:iam::123456789012:policy/shared"}
+	c.Assert(validation.DescribeDrift(s.ctx, driftRequest(), driftTarget(), driftTargetPolicies(), attached), check.HasLen, 0)
+}
+
+func (s *ValidateSuite) TestDescribeDriftStatements(c *check.C) {
+	policies := driftTargetPolicies()
+	// The Read statement gets another action and loses its resource, the other one is denied and a statement is added
+	policies[config.InlinePolicyName] = `{"Version":"2012-10-17","Statement":[` +
+		`{"Effect":"Deny","Action":["sqs:SendMessage"],"Resource":["*"]},` +
+		`{"Sid":"Read","Effect":"Allow","Action":["s3:GetObject","s3:DeleteObject"],"Resource":["*"]},` +
+		`{"Effect":"Allow","Action":["iam:*"],"Resource":["*"]}]}`
+	delete(policies, "dynamodb")
+	policies["sns"] = `{"Version":"2012-10-17","Statement":[]}`
+
+	changes := validation.DescribeDrift(s.ctx, driftRequest(), driftTarget(), policies, []string{"arn:aws:iam::123456789012:policy/shared"})
+	c.Assert(driftStrings(changes), check.DeepEquals, []string{
+		`inline policy custom statement "Read" Action: added s3:DeleteObject`,
+		`inline policy custom statement "Read" Resource: removed arn:aws:s3:::bucket/*`,
+		`inline policy custom statement "Read" Resource: added *`,
+		"inline policy custom statement 2 Effect: changed from Allow to Deny",
+		`inline policy custom statement 3: added {"Effect":"Allow","Action":["iam:*"],"Resource":["*"]}`,
+		"inline policy dynamodb: removed",
+		"inline policy sns: added",
+	})
+	for _, change := range changes {
+		c.Assert(change.Component, check.Equals, validation.DriftPolicy)
+	}
+}
+
+func (s *ValidateSuite) TestDescribeDriftRemovedStatement(c *check.C) {
+	policies := driftTargetPolicies()
+	policies[config.InlinePolicyName] = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["sqs:SendMessage"],"Resource":["*"]}]}`
+
+	changes := validation.DescribeDrift(s.ctx, driftRequest(), driftTarget(), policies, []string{"arn:aws:iam::123456789012:policy/shared"})
+	c.Assert(driftStrings(changes), check.DeepEquals, []string{
+		`inline policy custom statement "Read": removed {"Effect":"Allow","Action":["s3:GetObject"],"Resource":["arn:aws:s3:::bucket/*"],"Sid":"Read"}`,
+	})
+}
+
+func (s *ValidateSuite) TestDescribeDriftTrustPolicy(c *check.C) {
+	target := driftTarget()
+	target.Role.AssumeRolePolicyDocument = aws.String(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":["arn:aws:iam::999999999999:root"],"Service":"ec2.amazonaws.com"}}]}`)
+
+	changes := validation.DescribeDrift(s.ctx, driftRequest(), target, driftTargetPolicies(), []string{"arn:aws:iam::123456789012:policy/shared"})
+	c.Assert(driftStrings(changes), check.DeepEquals, []string{
+		"trust policy statement 1 Principal.AWS: removed arn:aws:iam::123456789012:role/trust_role",
+		"trust policy statement 1 Principal.AWS: added arn:aws:iam::999999999999:root",
+		"trust policy statement 1 Principal.Service: added ec2.amazonaws.com",
+	})
+	for _, change := range changes {
+		c.Assert(change.Component, check.Equals, validation.DriftTrust)
+	}
+}
+
+func (s *ValidateSuite) TestDescribeDriftRoleSettings(c *check.C) {
+	target := driftTarget()
+	target.Role.PermissionsBoundary = nil
+	target.Role.MaxSessionDuration = aws.Int64(43200)
+	target.Role.Description = aws.String("changed in the console")
+	target.Role.Tags = []*iam.Tag{
+		{Key: aws.String("team"), Value: aws.String("orders")},
+		{Key: aws.String("cost-center"), Value: aws.String("42")},
+	}
+
+	changes := validation.DescribeDrift(s.ctx, driftRequest(), target, driftTargetPolicies(), []string{"arn:aws:iam::123456789012:policy/old"})
+	c.Assert(driftStrings(changes), check.DeepEquals, []string{
+		"permission boundary: changed from arn:aws:iam::123456789012:policy/k8s-boundary to none",
+		"tag owner: removed alice",
+		"tag team: changed from payments to orders",
+		"tag cost-center: added 42",
+		`description: changed from "app role" to "changed in the console"`,
+		"max session duration: changed from 3600 to 43200",
+		"managed policy arn:aws:iam::123456789012:policy/shared: removed",
+		"managed policy arn:aws:iam::123456789012:policy/old: added",
+	})
+
+	var components []string
+	for _, change := range changes {
+		if len(components) == 0 || components[len(components)-1] != change.Component {
+			components = append(components, change.Component)
+		}
+	}
+	c.Assert(components, check.DeepEquals, []string{validation.DriftBoundary, validation.DriftTags, validation.DriftSettings, validation.DriftManagedPolicies})
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/url"
 	"reflect"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -26,10 +29,20 @@ func ValidateIAMPolicyAction(ctx context.Context, pDoc v1alpha1.PolicyDocument)
 
 	//Check the incoming policy actions
 	for _, statement := range pDoc.Statement {
+		if err := statement.ValidateElements(); err != nil {
+			log.Error(err, "invalid statement included in the request")
+			return field.Invalid(field.NewPath("spec").Child("PolicyDocument").Child("Statement"), statement.Sid, err.Error())
+		}
 		if statement.Effect == "Deny" {
 			//This should ignore the validation for all Deny action
 			continue
 		}
+		//NotAction in an Allow statement grants every action outside of the list which bypasses the allowed action prefixes
+		if len(statement.NotAction) > 0 && !config.Props.IsNotActionAllowed() {
+			err := "NotAction is not allowed in Allow statements"
+			log.Error(errors.New(err), err)
+			return field.Forbidden(field.NewPath("spec").Child("PolicyDocument").Child("NotAction"), err)
+		}
 		for _, action := range statement.Action {
 			isAllowed := false
 			for _, prefix := range config.Props.AllowedPolicyAction() {
@@ -48,6 +61,13 @@ func ValidateIAMPolicyAction(ctx context.Context, pDoc v1alpha1.PolicyDocument)
 			}
 			//This is special case-- May be only for Intuit
 			if strings.HasPrefix(action, "s3:") {
+				for _, res := range config.Props.RestrictedS3Resources() {
+					if len(statement.NotResource) > 0 && res != "" && !statement.ExcludesResource(res) {
+						err := fmt.Sprintf("NotResource must exclude restricted resource %s", res)
+						log.Error(errors.New(err), err)
+						return field.Forbidden(field.NewPath("spec").Child("PolicyDocument").Child("NotResource"), err)
+					}
+				}
 				for _, resource := range statement.Resource {
 					for _, res := range config.Props.RestrictedS3Resources() {
 						isAllowed := false
@@ -80,6 +100,16 @@ func ValidateIAMPolicyResource(ctx context.Context, pDoc v1alpha1.PolicyDocument
 			//This should ignore the validation for all Deny action
 			continue
 		}
+		//NotResource in an Allow statement matches every resource outside of the list, so it must exclude all the restricted ones
+		if len(statement.NotResource) > 0 {
+			for _, res := range config.Props.RestrictedPolicyResources() {
+				if res != "" && !statement.ExcludesResourcesContaining(res) {
+					err := fmt.Sprintf("NotResource must exclude restricted resource %s", res)
+					log.Error(errors.New(err), err)
+					return field.Forbidden(field.NewPath("spec").Child("PolicyDocument").Child("NotResource"), err)
+				}
+			}
+		}
 		for _, resource := range statement.Resource {
 			isAllowed := true
 			for _, res := range config.Props.RestrictedPolicyResources() {
@@ -100,30 +130,249 @@ func ValidateIAMPolicyResource(ctx context.Context, pDoc v1alpha1.PolicyDocument
 	return nil
 }
 
+// ValidateIAMPolicyCondition validates the condition block of every policy statement
+func ValidateIAMPolicyCondition(ctx context.Context, pDoc v1alpha1.PolicyDocument) *field.Error {
+	log := logging.Logger(ctx, "pkg.validation", "ValidateIAMPolicyCondition")
+
+	for _, statement := range pDoc.Statement {
+		if err := statement.Condition.Validate(); err != nil {
+			log.Error(err, "invalid condition included in the request")
+			return field.Invalid(field.NewPath("spec").Child("PolicyDocument").Child("Condition"), statement.Condition, err.Error())
+		}
+	}
+	return nil
+}
+
+// ValidateAssumeRolePolicyCondition validates the condition block of every trust policy statement
+func ValidateAssumeRolePolicyCondition(ctx context.Context, tDoc *v1alpha1.AssumeRolePolicyDocument) *field.Error {
+	log := logging.Logger(ctx, "pkg.validation", "ValidateAssumeRolePolicyCondition")
+
+	if tDoc == nil {
+		return nil
+	}
+	for _, statement := range tDoc.Statement {
+		if err := statement.Condition.Validate(); err != nil {
+			log.Error(err, "invalid trust policy condition included in the request")
+			return field.Invalid(field.NewPath("spec").Child("AssumeRolePolicyDocument").Child("Condition"), statement.Condition, err.Error())
+		}
+	}
+	return nil
+}
+
+// ValidateManagedPolicyArns validates the managed policies requested through the spec against the allow list
+func ValidateManagedPolicyArns(ctx context.Context, policyArns []string) *field.Error {
+	log := logging.Logger(ctx, "pkg.validation", "ValidateManagedPolicyArns")
+
+	for _, policyArn := range policyArns {
+		if !config.Props.IsManagedPolicyAllowed(policyArn) {
+			err := fmt.Sprintf("managed policy %s is not allowed to be attached", policyArn)
+			log.Error(errors.New(err), err)
+			return field.Forbidden(field.NewPath("spec").Child("ManagedPolicyArns"), err)
+		}
+	}
+	return nil
+}
+
+// ValidateTags validates the custom tags against the AWS tag rules and the reserved tag keys
+func ValidateTags(ctx context.Context, tags map[string]string) *field.Error {
+	log := logging.Logger(ctx, "pkg.validation", "ValidateTags")
+
+	if err := v1alpha1.ValidateTags(tags); err != nil {
+		log.Error(err, "invalid tags")
+		return field.Invalid(field.NewPath("spec").Child("Tags"), tags, err.Error())
+	}
+	return nil
+}
+
+// ValidateServiceAccounts validates the IRSA service accounts listed in the spec
+func ValidateServiceAccounts(ctx context.Context, serviceAccounts []v1alpha1.ServiceAccount) *field.Error {
+	log := logging.Logger(ctx, "pkg.validation", "ValidateServiceAccounts")
+
+	for i, sa := range serviceAccounts {
+		if err := v1alpha1.ValidateServiceAccount(sa); err != nil {
+			log.Error(err, "invalid service account")
+			return field.Invalid(field.NewPath("spec").Child("ServiceAccounts").Index(i), sa.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// ValidateMaxSessionDuration validates the role max session duration against the configured bounds
+func ValidateMaxSessionDuration(ctx context.Context, duration int64) *field.Error {
+	log := logging.Logger(ctx, "pkg.validation", "ValidateMaxSessionDuration")
+
+	if !config.Props.IsSessionDurationAllowed(duration) {
+		err := fmt.Sprintf("max session duration %d must be between %d and %d seconds", duration, config.Props.MinSessionDuration(), config.Props.MaxSessionDuration())
+		log.Error(errors.New(err), err)
+		return field.Invalid(field.NewPath("spec").Child("MaxSessionDuration"), duration, err)
+	}
+	return nil
+}
+
+// ValidateRolePath validates the role path against the configured path prefix. An empty path is allowed as the
+// role is then created at the prefix
+func ValidateRolePath(ctx context.Context, path string) *field.Error {
+	log := logging.Logger(ctx, "pkg.validation", "ValidateRolePath")
+
+	if path != "" && !config.Props.IsRolePathAllowed(path) {
+		err := fmt.Sprintf("path %s must start with %s", path, config.Props.RolePathPrefix())
+		log.Error(errors.New(err), err)
+		return field.Forbidden(field.NewPath("spec").Child("Path"), err)
+	}
+	return nil
+}
+
+// ValidateAdoptRoleARN validates the ARN of the pre-existing role to adopt. It must refer to the generated role name
+func ValidateAdoptRoleARN(ctx context.Context, roleARN string, roleName string, path string) *field.Error {
+	log := logging.Logger(ctx, "pkg.validation", "ValidateAdoptRoleARN")
+
+	if roleARN == "" {
+		return nil
+	}
+	adoptedRoleName, err := v1alpha1.ValidateAdoptRoleARN(roleARN, path)
+	if err == nil && adoptedRoleName != roleName {
+		err = fmt.Errorf("role %s cannot be adopted as the role name is %s", adoptedRoleName, roleName)
+	}
+	if err != nil {
+		log.Error(err, "invalid role to adopt")
+		return field.Forbidden(field.NewPath("spec").Child("AdoptRoleARN"), err.Error())
+	}
+	return nil
+}
+
+// CompareManagedPolicies verifies that every desired managed policy is attached and none of the
+// previously attached managed policies which are no longer desired is still attached
+func CompareManagedPolicies(ctx context.Context, request awsapi.IAMRoleRequest, attached []string) bool {
+	log := logging.Logger(ctx, "pkg.validation", "CompareManagedPolicies")
+
+	for _, policy := range request.ManagedPolicies {
+		if policy != "" && !ContainsString(attached, policy) {
+			log.Info("desired managed policy is not attached", "policy", policy)
+			return false
+		}
+	}
+	for _, policy := range request.PreviousManagedPolicies {
+		if !ContainsString(request.ManagedPolicies, policy) && ContainsString(attached, policy) {
+			log.Info("managed policy is no longer desired but still attached", "policy", policy)
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateInlinePolicyNames validates that additional inline policies don't reuse the reserved or each other's names
+func ValidateInlinePolicyNames(ctx context.Context, policies []v1alpha1.InlinePolicy) *field.Error {
+	log := logging.Logger(ctx, "pkg.validation", "ValidateInlinePolicyNames")
+
+	names := map[string]bool{}
+	for _, policy := range policies {
+		if policy.Name == config.InlinePolicyName || names[policy.Name] {
+			err := fmt.Sprintf("inline policy name %s is reserved or used more than once", policy.Name)
+			log.Error(errors.New(err), err)
+			return field.Invalid(field.NewPath("spec").Child("InlinePolicies").Child("Name"), policy.Name, err)
+		}
+		names[policy.Name] = true
+	}
+	return nil
+}
+
+// Components of an iam role which can drift from the spec, as reported by RoleDrift and DescribeDrift
+const (
+	DriftPolicy   = "policy"
+	DriftTrust    = "trust"
+	DriftBoundary = "boundary"
+	DriftTags     = "tags"
+	DriftSettings = "settings"
+	// DriftManagedPolicies is only reported by DescribeDrift, RoleDrift leaves them to CompareManagedPolicies
+	DriftManagedPolicies = "managed_policies"
+)
+
 // CompareRole function compares input role to target role
-func CompareRole(ctx context.Context, request awsapi.IAMRoleRequest, targetRole *iam.GetRoleOutput, targetRolePolicy string) bool {
-	log := logging.Logger(ctx, "pkg.validation", "ComparePolicy")
+// targetRolePolicies holds the inline policy documents present in AWS keyed by policy name
+func CompareRole(ctx context.Context, request awsapi.IAMRoleRequest, targetRole *iam.GetRoleOutput, targetRolePolicies map[string]string) bool {
+	return len(RoleDrift(ctx, request, targetRole, targetRolePolicies)) == 0
+}
 
-	// Step 1: Compare the permission policy
-	if !ComparePermissionPolicy(ctx, request.PermissionPolicy, targetRolePolicy) {
-		return false
+// RoleDrift compares input role to target role and returns the components which differ
+func RoleDrift(ctx context.Context, request awsapi.IAMRoleRequest, targetRole *iam.GetRoleOutput, targetRolePolicies map[string]string) []string {
+	log := logging.Logger(ctx, "pkg.validation", "RoleDrift")
+	var drifted []string
+
+	// Step 1: Compare the permission policies
+	if !CompareInlinePolicies(ctx, request, targetRolePolicies) {
+		drifted = append(drifted, DriftPolicy)
 	}
 
 	//Step 2: Compare Assume Role Policy Document
-	if !CompareAssumeRolePolicy(ctx, request.TrustPolicy, *targetRole.Role.AssumeRolePolicyDocument) {
-		return false
+	if !CompareAssumeRolePolicy(ctx, request.TrustPolicy, aws.StringValue(targetRole.Role.AssumeRolePolicyDocument)) {
+		drifted = append(drifted, DriftTrust)
 	}
 	//Step 3: Compare Permission Boundary
-	if !reflect.DeepEqual(request.ManagedPermissionBoundaryPolicy, *targetRole.Role.PermissionsBoundary.PermissionsBoundaryArn) {
+	targetBoundary := ""
+	if targetRole.Role.PermissionsBoundary != nil {
+		targetBoundary = aws.StringValue(targetRole.Role.PermissionsBoundary.PermissionsBoundaryArn)
+	}
+	if request.ManagedPermissionBoundaryPolicy != targetBoundary {
 		log.Info("input permission boundary and target permission boundary are NOT equal")
-		return false
+		drifted = append(drifted, DriftBoundary)
 	}
 
-	//Step 4: Compare Tags
-	if !CompareTags(ctx, request.Tags, targetRole.Role.Tags) {
-		return false
+	//Step 4: Compare Tags. Tags which were never attached by iam-manager are ignored
+	if !CompareTags(ctx, request.Tags, managedTags(request, targetRole.Role.Tags)) {
+		drifted = append(drifted, DriftTags)
 	}
 
+	//Step 5: Compare role settings
+	settingsDrifted := false
+	if request.SessionDuration != aws.Int64Value(targetRole.Role.MaxSessionDuration) {
+		log.Info("input max session duration and target max session duration are NOT equal", "req", request.SessionDuration, "dest", aws.Int64Value(targetRole.Role.MaxSessionDuration))
+		settingsDrifted = true
+	}
+	if request.Description != aws.StringValue(targetRole.Role.Description) {
+		log.Info("input description and target description are NOT equal")
+		settingsDrifted = true
+	}
+	if request.Path != "" && request.Path != aws.StringValue(targetRole.Role.Path) {
+		log.Info("input path and target path are NOT equal", "req", request.Path, "dest", aws.StringValue(targetRole.Role.Path))
+		settingsDrifted = true
+	}
+	if settingsDrifted {
+		drifted = append(drifted, DriftSettings)
+	}
+
+	return drifted
+}
+
+// CompareInlinePolicies compares every inline policy from the request with the ones present in AWS.
+// Inline policies put by iam-manager earlier which are no longer desired must be gone
+func CompareInlinePolicies(ctx context.Context, request awsapi.IAMRoleRequest, targetRolePolicies map[string]string) bool {
+	log := logging.Logger(ctx, "pkg.validation", "CompareInlinePolicies")
+
+	desired := map[string]string{request.PolicyName: request.PermissionPolicy}
+	for policyName, policy := range request.InlinePolicies {
+		desired[policyName] = policy
+	}
+
+	for policyName, policy := range desired {
+		target, ok := targetRolePolicies[policyName]
+		if !ok {
+			log.Info("inline policy is missing in the target role", "policyName", policyName)
+			return false
+		}
+		if !ComparePermissionPolicy(ctx, policy, target) {
+			return false
+		}
+	}
+
+	for _, policyName := range request.PreviousInlinePolicies {
+		if _, ok := desired[policyName]; ok {
+			continue
+		}
+		if _, ok := targetRolePolicies[policyName]; ok {
+			log.Info("inline policy is no longer desired but still present in the target role", "policyName", policyName)
+			return false
+		}
+	}
 	return true
 }
 
@@ -142,6 +391,34 @@ func CompareRoleIRSA(ctx context.Context, sa *v1.ServiceAccount, props config.Pr
 	return true
 }
 
+// CompareServiceAccount verifies that the service account is annotated with the role ARN and carries
+// the annotations and labels requested in the spec
+func CompareServiceAccount(ctx context.Context, sa *v1.ServiceAccount, roleARN string, desired v1alpha1.ServiceAccount, props config.Properties) bool {
+	log := logging.Logger(ctx, "pkg.validation", "CompareServiceAccount")
+
+	if sa.Annotations["eks.amazonaws.com/role-arn"] != roleARN {
+		log.Info("service account is not annotated with the role arn", "serviceAccount", sa.Name)
+		return false
+	}
+	if !CompareRoleIRSA(ctx, sa, props) {
+		log.Info("service account regional endpoint annotation is not in sync", "serviceAccount", sa.Name)
+		return false
+	}
+	for key, value := range desired.Annotations {
+		if existing, ok := sa.Annotations[key]; !ok || existing != value {
+			log.Info("service account annotation is not in sync", "serviceAccount", sa.Name, "annotation", key)
+			return false
+		}
+	}
+	for key, value := range desired.Labels {
+		if existing, ok := sa.Labels[key]; !ok || existing != value {
+			log.Info("service account label is not in sync", "serviceAccount", sa.Name, "label", key)
+			return false
+		}
+	}
+	return true
+}
+
 // ComparePermissionPolicy compares role policy from request and response
 func ComparePermissionPolicy(ctx context.Context, request string, target string) bool {
 	log := logging.Logger(ctx, "pkg.validation", "ComparePermissionPolicy")
@@ -158,6 +435,8 @@ func ComparePermissionPolicy(ctx context.Context, request string, target string)
 	if err != nil {
 		log.Error(err, "failed to marshal policy document")
 	}
+	normalizePolicyDocument(&req)
+	normalizePolicyDocument(&dest)
 	//compare
 	if !reflect.DeepEqual(req, dest) {
 		log.Info("input policy and target policy are NOT equal", "req", req, "dest", dest)
@@ -167,6 +446,47 @@ func ComparePermissionPolicy(ctx context.Context, request string, target string)
 	return true
 }
 
+// normalizePolicyDocument makes the condition values order insensitive so that
+// a policy read back from AWS does not show up as drift
+func normalizePolicyDocument(pDoc *v1alpha1.PolicyDocument) {
+	for i := range pDoc.Statement {
+		pDoc.Statement[i].Condition = normalizeCondition(pDoc.Statement[i].Condition)
+	}
+}
+
+// normalizeCondition returns a copy of the condition block with sorted values. Empty blocks are returned as nil
+func normalizeCondition(condition v1alpha1.PolicyCondition) v1alpha1.PolicyCondition {
+	if len(condition) == 0 {
+		return nil
+	}
+	normalized := v1alpha1.PolicyCondition{}
+	for operator, keys := range condition {
+		normalized[operator] = map[string]v1alpha1.StringOrStrings{}
+		for key, values := range keys {
+			sorted := append(v1alpha1.StringOrStrings{}, values...)
+			sort.Strings(sorted)
+			normalized[operator][key] = sorted
+		}
+	}
+	return normalized
+}
+
+// normalizeAssumeRolePolicyDocument makes the trust policy comparison insensitive to the order of
+// statements, principals and condition values
+func normalizeAssumeRolePolicyDocument(tDoc *v1alpha1.AssumeRolePolicyDocument) {
+	for i := range tDoc.Statement {
+		tDoc.Statement[i].Condition = normalizeCondition(tDoc.Statement[i].Condition)
+		if len(tDoc.Statement[i].Principal.AWS) > 0 {
+			sorted := append(v1alpha1.StringOrStrings{}, tDoc.Statement[i].Principal.AWS...)
+			sort.Strings(sorted)
+			tDoc.Statement[i].Principal.AWS = sorted
+		}
+	}
+	sort.SliceStable(tDoc.Statement, func(i, j int) bool {
+		return tDoc.Statement[i].Id() < tDoc.Statement[j].Id()
+	})
+}
+
 // CompareAssumeRolePolicy compares assume role policy from request and response
 func CompareAssumeRolePolicy(ctx context.Context, request string, target string) bool {
 	log := logging.Logger(ctx, "pkg.validation", "CompareAssumeRolePolicy")
@@ -183,6 +503,8 @@ func CompareAssumeRolePolicy(ctx context.Context, request string, target string)
 	if err != nil {
 		log.Error(err, "failed to marshal assume role policy document")
 	}
+	normalizeAssumeRolePolicyDocument(&reqAssume)
+	normalizeAssumeRolePolicyDocument(&destAssume)
 	//compare
 	if !reflect.DeepEqual(reqAssume, destAssume) {
 		log.Info("input assume role policy and target assume role policy are NOT equal", "req", reqAssume, "dest", destAssume)
@@ -192,6 +514,72 @@ func CompareAssumeRolePolicy(ctx context.Context, request string, target string)
 	return true
 }
 
+// IsRoleManaged returns true if the tags of the role show that iam-manager manages it for the namespace and cluster of the request
+func IsRoleManaged(request awsapi.IAMRoleRequest, target []*iam.Tag) bool {
+	tags := map[string]string{}
+	for _, tag := range target {
+		if tag != nil {
+			tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+	}
+	return tags["managedBy"] == request.Tags["managedBy"] && tags["Namespace"] == request.Tags["Namespace"] && tags["Cluster"] == request.Tags["Cluster"]
+}
+
+// AdoptionOverwrites describes what iam-manager overwrites when it adopts the pre-existing role
+// targetRolePolicies holds the inline policy documents present in AWS keyed by policy name
+func AdoptionOverwrites(ctx context.Context, request awsapi.IAMRoleRequest, targetRole *iam.GetRoleOutput, targetRolePolicies map[string]string) []string {
+	var overwrites []string
+	if !CompareAssumeRolePolicy(ctx, request.TrustPolicy, aws.StringValue(targetRole.Role.AssumeRolePolicyDocument)) {
+		overwrites = append(overwrites, "trust policy")
+	}
+	requestPolicies := map[string]string{request.PolicyName: request.PermissionPolicy}
+	for name, policy := range request.InlinePolicies {
+		requestPolicies[name] = policy
+	}
+	for _, name := range slices.Sorted(maps.Keys(requestPolicies)) {
+		if target, ok := targetRolePolicies[name]; ok && !ComparePermissionPolicy(ctx, requestPolicies[name], target) {
+			overwrites = append(overwrites, "inline policy "+name)
+		}
+	}
+	boundary := "none"
+	if targetRole.Role.PermissionsBoundary != nil {
+		boundary = aws.StringValue(targetRole.Role.PermissionsBoundary.PermissionsBoundaryArn)
+	}
+	if boundary != request.ManagedPermissionBoundaryPolicy {
+		overwrites = append(overwrites, "permission boundary "+boundary)
+	}
+	if request.Description != aws.StringValue(targetRole.Role.Description) {
+		overwrites = append(overwrites, "description")
+	}
+	if request.SessionDuration != aws.Int64Value(targetRole.Role.MaxSessionDuration) {
+		overwrites = append(overwrites, fmt.Sprintf("max session duration %d", aws.Int64Value(targetRole.Role.MaxSessionDuration)))
+	}
+	for _, tag := range targetRole.Role.Tags {
+		if tag == nil {
+			continue
+		}
+		if value, ok := request.Tags[aws.StringValue(tag.Key)]; ok && value != aws.StringValue(tag.Value) {
+			overwrites = append(overwrites, fmt.Sprintf("tag %s=%s", aws.StringValue(tag.Key), aws.StringValue(tag.Value)))
+		}
+	}
+	return overwrites
+}
+
+// managedTags filters the target tags down to the ones requested now or attached by iam-manager earlier
+func managedTags(request awsapi.IAMRoleRequest, target []*iam.Tag) []*iam.Tag {
+	var tags []*iam.Tag
+	for _, tag := range target {
+		if tag == nil {
+			continue
+		}
+		key := aws.StringValue(tag.Key)
+		if _, ok := request.Tags[key]; ok || slices.Contains(request.PreviousTags, key) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
 // CompareTags compares tags from request and response
 func CompareTags(ctx context.Context, request map[string]string, target []*iam.Tag) bool {
 	log := logging.Logger(ctx, "pkg.validation", "CompareTags")
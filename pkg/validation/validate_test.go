@@ -3,6 +3,7 @@ package validation_test
 import (
 	"context"
 	"encoding/json"
+	"net/url"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -141,6 +142,142 @@ func (s *ValidateSuite) TestValidateIAMPolicyResourceDeny(c *check.C) {
 	c.Assert(err, check.IsNil)
 }
 
+func (s *ValidateSuite) TestValidateIAMPolicyActionAndNotActionFailure(c *check.C) {
+	input := v1alpha1.PolicyDocument{
+		Statement: []v1alpha1.Statement{
+			{
+				Action:    []string{"route53:Get"},
+				NotAction: []string{"iam:*"},
+				Effect:    "Deny",
+				Resource:  []string{"*"},
+			},
+		},
+	}
+	err := validation.ValidateIAMPolicyAction(s.ctx, input)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *ValidateSuite) TestValidateIAMPolicyNoResourceFailure(c *check.C) {
+	input := v1alpha1.PolicyDocument{
+		Statement: []v1alpha1.Statement{
+			{
+				Action: []string{"route53:Get"},
+				Effect: "Allow",
+			},
+		},
+	}
+	err := validation.ValidateIAMPolicyAction(s.ctx, input)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *ValidateSuite) TestValidateIAMPolicyAllowNotActionFailure(c *check.C) {
+	input := v1alpha1.PolicyDocument{
+		Statement: []v1alpha1.Statement{
+			{
+				NotAction: []string{"iam:*"},
+				Effect:    "Allow",
+				Resource:  []string{"*"},
+			},
+		},
+	}
+	err := validation.ValidateIAMPolicyAction(s.ctx, input)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *ValidateSuite) TestValidateIAMPolicyDenyNotActionSuccess(c *check.C) {
+	input := v1alpha1.PolicyDocument{
+		Statement: []v1alpha1.Statement{
+			{
+				NotAction: []string{"route53:Get"},
+				Effect:    "Deny",
+				Resource:  []string{"*"},
+			},
+		},
+	}
+	err := validation.ValidateIAMPolicyAction(s.ctx, input)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *ValidateSuite) TestValidateIAMPolicyActionS3NotResourceFailure(c *check.C) {
+	input := v1alpha1.PolicyDocument{
+		Statement: []v1alpha1.Statement{
+			{
+				Action:      []string{"s3:GetObject"},
+				Effect:      "Allow",
+				NotResource: []string{"arn:aws:s3:::other-bucket"},
+			},
+		},
+	}
+	err := validation.ValidateIAMPolicyAction(s.ctx, input)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *ValidateSuite) TestValidateIAMPolicyResourceNotResourceSuccess(c *check.C) {
+	input := v1alpha1.PolicyDocument{
+		Statement: []v1alpha1.Statement{
+			{
+				Action:      []string{"route53:Get"},
+				Effect:      "Allow",
+				NotResource: []string{"*policy-resource*"}, //policy-resource is in Makefile
+			},
+		},
+	}
+	err := validation.ValidateIAMPolicyResource(s.ctx, input)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *ValidateSuite) TestValidateIAMPolicyResourceNotResourceFailure(c *check.C) {
+	input := v1alpha1.PolicyDocument{
+		Statement: []v1alpha1.Statement{
+			{
+				Action:      []string{"route53:Get"},
+				Effect:      "Allow",
+				NotResource: []string{"something-something"},
+			},
+		},
+	}
+	err := validation.ValidateIAMPolicyResource(s.ctx, input)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *ValidateSuite) TestValidateIAMPolicyConditionSuccess(c *check.C) {
+	input := v1alpha1.PolicyDocument{
+		Statement: []v1alpha1.Statement{
+			{
+				Action:   []string{"s3:GetObject"},
+				Effect:   "Allow",
+				Resource: []string{"arn:aws:s3:::bucket/*"},
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"aws:SourceVpce": {"vpce-1a2b3c4d"},
+					},
+				},
+			},
+		},
+	}
+	err := validation.ValidateIAMPolicyCondition(s.ctx, input)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *ValidateSuite) TestValidateIAMPolicyConditionFailure(c *check.C) {
+	input := v1alpha1.PolicyDocument{
+		Statement: []v1alpha1.Statement{
+			{
+				Action:   []string{"s3:GetObject"},
+				Effect:   "Allow",
+				Resource: []string{"arn:aws:s3:::bucket/*"},
+				Condition: v1alpha1.PolicyCondition{
+					"StringMatches": {
+						"aws:SourceVpce": {"vpce-1a2b3c4d"},
+					},
+				},
+			},
+		},
+	}
+	err := validation.ValidateIAMPolicyCondition(s.ctx, input)
+	c.Assert(err, check.NotNil)
+}
+
 func (s *ValidateSuite) TestCompareRoleSuccess(c *check.C) {
 
 	input1 := v1alpha1.PolicyDocument{
@@ -200,19 +337,148 @@ func (s *ValidateSuite) TestCompareRoleSuccess(c *check.C) {
 			PermissionsBoundary: &iam.AttachedPermissionsBoundary{
 				PermissionsBoundaryArn: &boundary,
 			},
+			MaxSessionDuration: aws.Int64(3600),
+			Description:        aws.String("my role"),
+			Path:               aws.String("/k8s/"),
 		},
 	}
 
 	i1 := awsapi.IAMRoleRequest{
+		PolicyName:                      config.InlinePolicyName,
 		PermissionPolicy:                string(role1),
 		TrustPolicy:                     string(role3),
 		ManagedPermissionBoundaryPolicy: config.Props.ManagedPermissionBoundaryPolicy(),
+		SessionDuration:                 3600,
+		Description:                     "my role",
+		Path:                            "/k8s/",
+	}
+
+	flag := validation.CompareRole(s.ctx, i1, &target, map[string]string{config.InlinePolicyName: string(role2)})
+	c.Assert(flag, check.Equals, true)
+}
+
+func (s *ValidateSuite) TestCompareRoleSettingsFailure(c *check.C) {
+	policy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":["*"]}]}`
+	trustPolicy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":["arn:aws:iam::123456789012:role/user_request_role"]}}]}`
+	boundary := config.Props.ManagedPermissionBoundaryPolicy()
+	request := awsapi.IAMRoleRequest{
+		PolicyName:                      config.InlinePolicyName,
+		PermissionPolicy:                policy,
+		TrustPolicy:                     trustPolicy,
+		ManagedPermissionBoundaryPolicy: boundary,
+		SessionDuration:                 43200,
+		Description:                     config.DefaultRoleDescription,
+	}
+	newTarget := func() *iam.GetRoleOutput {
+		return &iam.GetRoleOutput{
+			Role: &iam.Role{
+				AssumeRolePolicyDocument: aws.String(trustPolicy),
+				PermissionsBoundary: &iam.AttachedPermissionsBoundary{
+					PermissionsBoundaryArn: aws.String(boundary),
+				},
+				MaxSessionDuration: aws.Int64(43200),
+				Description:        aws.String(config.DefaultRoleDescription),
+				Path:               aws.String("/"),
+			},
+		}
+	}
+	policies := map[string]string{config.InlinePolicyName: policy}
+	c.Assert(validation.CompareRole(s.ctx, request, newTarget(), policies), check.Equals, true)
+
+	// max session duration changed from the console
+	target := newTarget()
+	target.Role.MaxSessionDuration = aws.Int64(3600)
+	c.Assert(validation.CompareRole(s.ctx, request, target, policies), check.Equals, false)
+
+	// description changed from the console
+	target = newTarget()
+	target.Role.Description = aws.String("changed")
+	c.Assert(validation.CompareRole(s.ctx, request, target, policies), check.Equals, false)
+
+	// requested path doesn't match
+	pathRequest := request
+	pathRequest.Path = "/k8s/"
+	c.Assert(validation.CompareRole(s.ctx, pathRequest, newTarget(), policies), check.Equals, false)
+}
+
+func (s *ValidateSuite) TestRoleDrift(c *check.C) {
+	policy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":["*"]}]}`
+	trustPolicy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":["arn:aws:iam::123456789012:role/user_request_role"]}}]}`
+	boundary := config.Props.ManagedPermissionBoundaryPolicy()
+	request := awsapi.IAMRoleRequest{
+		PolicyName:                      config.InlinePolicyName,
+		PermissionPolicy:                policy,
+		TrustPolicy:                     trustPolicy,
+		ManagedPermissionBoundaryPolicy: boundary,
+		SessionDuration:                 43200,
+		Description:                     config.DefaultRoleDescription,
+		Tags:                            map[string]string{"team": "payments"},
 	}
+	target := &iam.GetRoleOutput{
+		Role: &iam.Role{
+			AssumeRolePolicyDocument: aws.String(trustPolicy),
+			PermissionsBoundary: &iam.AttachedPermissionsBoundary{
+				PermissionsBoundaryArn: aws.String(boundary),
+			},
+			MaxSessionDuration: aws.Int64(43200),
+			Description:        aws.String(config.DefaultRoleDescription),
+			Tags:               []*iam.Tag{{Key: aws.String("team"), Value: aws.String("payments")}},
+		},
+	}
+	policies := map[string]string{config.InlinePolicyName: policy}
+	c.Assert(validation.RoleDrift(s.ctx, request, target, policies), check.HasLen, 0)
 
-	flag := validation.CompareRole(s.ctx, i1, &target, string(role2))
+	// Every drifted component is reported, not only the first one
+	target.Role.AssumeRolePolicyDocument = aws.String(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":["arn:aws:iam::123456789012:role/other"]}}]}`)
+	target.Role.PermissionsBoundary = nil
+	target.Role.Tags = nil
+	target.Role.MaxSessionDuration = aws.Int64(3600)
+	drifted := validation.RoleDrift(s.ctx, request, target, map[string]string{})
+	c.Assert(drifted, check.DeepEquals, []string{validation.DriftPolicy, validation.DriftTrust, validation.DriftBoundary, validation.DriftTags, validation.DriftSettings})
+	c.Assert(validation.CompareRole(s.ctx, request, target, map[string]string{}), check.Equals, false)
+}
+
+func (s *ValidateSuite) TestCompareInlinePoliciesSuccess(c *check.C) {
+	policy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":["*"]}]}`
+	request := awsapi.IAMRoleRequest{
+		PolicyName:             config.InlinePolicyName,
+		PermissionPolicy:       policy,
+		InlinePolicies:         map[string]string{"data": policy},
+		PreviousInlinePolicies: []string{"data", "removed"},
+	}
+	// inline policies put outside of iam-manager are not drift
+	flag := validation.CompareInlinePolicies(s.ctx, request, map[string]string{config.InlinePolicyName: policy, "data": policy, "other": policy})
 	c.Assert(flag, check.Equals, true)
 }
 
+func (s *ValidateSuite) TestCompareInlinePoliciesFailure(c *check.C) {
+	policy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":["*"]}]}`
+	request := awsapi.IAMRoleRequest{
+		PolicyName:             config.InlinePolicyName,
+		PermissionPolicy:       policy,
+		InlinePolicies:         map[string]string{"data": policy},
+		PreviousInlinePolicies: []string{"data", "removed"},
+	}
+	// desired inline policy is missing
+	flag := validation.CompareInlinePolicies(s.ctx, request, map[string]string{config.InlinePolicyName: policy})
+	c.Assert(flag, check.Equals, false)
+
+	// removed inline policy is still present
+	flag = validation.CompareInlinePolicies(s.ctx, request, map[string]string{config.InlinePolicyName: policy, "data": policy, "removed": policy})
+	c.Assert(flag, check.Equals, false)
+}
+
+func (s *ValidateSuite) TestValidateInlinePolicyNamesFailure(c *check.C) {
+	err := validation.ValidateInlinePolicyNames(s.ctx, []v1alpha1.InlinePolicy{{Name: config.InlinePolicyName}})
+	c.Assert(err, check.NotNil)
+
+	err = validation.ValidateInlinePolicyNames(s.ctx, []v1alpha1.InlinePolicy{{Name: "data"}, {Name: "data"}})
+	c.Assert(err, check.NotNil)
+
+	err = validation.ValidateInlinePolicyNames(s.ctx, []v1alpha1.InlinePolicy{{Name: "data"}, {Name: "logs"}})
+	c.Assert(err, check.IsNil)
+}
+
 func (s *ValidateSuite) TestComparePermissionPolicySuccess(c *check.C) {
 
 	input1 := v1alpha1.PolicyDocument{
@@ -305,6 +571,77 @@ func (s *ValidateSuite) TestComparePermissionPolicyFailure(c *check.C) {
 	c.Assert(flag, check.Equals, false)
 }
 
+func (s *ValidateSuite) TestComparePermissionPolicyWithConditionSuccess(c *check.C) {
+	input1 := v1alpha1.PolicyDocument{
+		Statement: []v1alpha1.Statement{
+			{
+				Action:   []string{"s3:ListBucket"},
+				Effect:   "Allow",
+				Resource: []string{"arn:aws:s3:::bucket"},
+				Condition: v1alpha1.PolicyCondition{
+					"StringLike": {
+						"s3:prefix": {"home/*", "shared/*"},
+					},
+					"StringEquals": {
+						"aws:RequestedRegion": {"us-west-2"},
+					},
+				},
+			},
+		},
+	}
+	role1, _ := json.Marshal(input1)
+
+	// AWS may return single values as a plain string and multiple values in any order
+	target := `{"Statement":[{"Effect":"Allow","Action":["s3:ListBucket"],"Resource":["arn:aws:s3:::bucket"],"Condition":{"StringEquals":{"aws:RequestedRegion":"us-west-2"},"StringLike":{"s3:prefix":["shared/*","home/*"]}}}]}`
+
+	flag := validation.ComparePermissionPolicy(s.ctx, string(role1), target)
+	c.Assert(flag, check.Equals, true)
+}
+
+func (s *ValidateSuite) TestComparePermissionPolicyWithConditionFailure(c *check.C) {
+	input1 := v1alpha1.PolicyDocument{
+		Statement: []v1alpha1.Statement{
+			{
+				Action:   []string{"s3:ListBucket"},
+				Effect:   "Allow",
+				Resource: []string{"arn:aws:s3:::bucket"},
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"aws:RequestedRegion": {"us-west-2"},
+					},
+				},
+			},
+		},
+	}
+	role1, _ := json.Marshal(input1)
+
+	target := `{"Statement":[{"Effect":"Allow","Action":["s3:ListBucket"],"Resource":["arn:aws:s3:::bucket"],"Condition":{"StringEquals":{"aws:RequestedRegion":"us-east-1"}}}]}`
+
+	flag := validation.ComparePermissionPolicy(s.ctx, string(role1), target)
+	c.Assert(flag, check.Equals, false)
+}
+
+func (s *ValidateSuite) TestComparePermissionPolicyNotActionFailure(c *check.C) {
+	input1 := v1alpha1.PolicyDocument{
+		Statement: []v1alpha1.Statement{
+			{
+				NotAction:   []string{"iam:*"},
+				Effect:      "Deny",
+				NotResource: []string{"arn:aws:s3:::bucket"},
+			},
+		},
+	}
+	role1, _ := json.Marshal(input1)
+
+	target := `{"Statement":[{"Effect":"Deny","Action":["iam:*"],"NotResource":["arn:aws:s3:::bucket"]}]}`
+
+	flag := validation.ComparePermissionPolicy(s.ctx, string(role1), target)
+	c.Assert(flag, check.Equals, false)
+
+	flag = validation.ComparePermissionPolicy(s.ctx, string(role1), string(role1))
+	c.Assert(flag, check.Equals, true)
+}
+
 func (s *ValidateSuite) TestCompareAssumeRolePolicySuccess(c *check.C) {
 
 	input1 := v1alpha1.AssumeRolePolicyDocument{
@@ -435,6 +772,173 @@ func (s *ValidateSuite) TestCompareAssumeRolePolicyFailure(c *check.C) {
 	c.Assert(flag, check.Equals, false)
 }
 
+func (s *ValidateSuite) TestCompareAssumeRolePolicyOrderInsensitiveSuccess(c *check.C) {
+	input1 := v1alpha1.AssumeRolePolicyDocument{
+		Statement: []v1alpha1.TrustPolicyStatement{
+			{
+				Action: "sts:AssumeRole",
+				Effect: "Allow",
+				Principal: v1alpha1.Principal{
+					AWS: []string{"arn:aws:iam::123456789012:role/role_a", "arn:aws:iam::123456789012:role/role_b"},
+				},
+			},
+			{
+				Action: "sts:AssumeRoleWithWebIdentity",
+				Effect: "Allow",
+				Principal: v1alpha1.Principal{
+					Federated: "arn:aws:iam::123456789012:oidc-provider/google.com/OIDC",
+				},
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"google.com/OIDC:sub": {"system:serviceaccount:ns:sa1", "system:serviceaccount:ns:sa2"},
+					},
+					"IpAddress": {
+						"aws:SourceIp": {"10.0.0.0/8"},
+					},
+				},
+			},
+		},
+		Version: "2012-10-17",
+	}
+	role1, _ := json.Marshal(input1)
+
+	target := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRoleWithWebIdentity","Principal":{"Federated":"arn:aws:iam::123456789012:oidc-provider/google.com/OIDC"},"Condition":{"IpAddress":{"aws:SourceIp":"10.0.0.0/8"},"StringEquals":{"google.com/OIDC:sub":["system:serviceaccount:ns:sa2","system:serviceaccount:ns:sa1"]}}},{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":["arn:aws:iam::123456789012:role/role_b","arn:aws:iam::123456789012:role/role_a"]}}]}`
+
+	flag := validation.CompareAssumeRolePolicy(s.ctx, string(role1), target)
+	c.Assert(flag, check.Equals, true)
+}
+
+func (s *ValidateSuite) TestCompareAssumeRolePolicyConditionFailure(c *check.C) {
+	input1 := v1alpha1.AssumeRolePolicyDocument{
+		Statement: []v1alpha1.TrustPolicyStatement{
+			{
+				Action: "sts:AssumeRoleWithWebIdentity",
+				Effect: "Allow",
+				Principal: v1alpha1.Principal{
+					Federated: "arn:aws:iam::123456789012:oidc-provider/google.com/OIDC",
+				},
+				Condition: v1alpha1.PolicyCondition{
+					"StringEquals": {
+						"google.com/OIDC:sub": {"system:serviceaccount:ns:sa1", "system:serviceaccount:ns:sa2"},
+					},
+				},
+			},
+		},
+		Version: "2012-10-17",
+	}
+	role1, _ := json.Marshal(input1)
+
+	target := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRoleWithWebIdentity","Principal":{"Federated":"arn:aws:iam::123456789012:oidc-provider/google.com/OIDC"},"Condition":{"StringEquals":{"google.com/OIDC:sub":"system:serviceaccount:ns:sa1"}}}]}`
+
+	flag := validation.CompareAssumeRolePolicy(s.ctx, string(role1), target)
+	c.Assert(flag, check.Equals, false)
+}
+
+func (s *ValidateSuite) TestValidateManagedPolicyArnsFailure(c *check.C) {
+	err := validation.ValidateManagedPolicyArns(s.ctx, []string{"arn:aws:iam::aws:policy/AdministratorAccess"})
+	c.Assert(err, check.NotNil)
+}
+
+func (s *ValidateSuite) TestValidateManagedPolicyArnsEmptySuccess(c *check.C) {
+	err := validation.ValidateManagedPolicyArns(s.ctx, nil)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *ValidateSuite) TestValidateAdoptRoleARNNotAllowed(c *check.C) {
+	err := validation.ValidateAdoptRoleARN(s.ctx, "arn:aws:iam::123456789012:role/k8s-app", "k8s-app", "")
+	c.Assert(err, check.NotNil)
+}
+
+func (s *ValidateSuite) TestValidateAdoptRoleARNOtherAccount(c *check.C) {
+	err := validation.ValidateAdoptRoleARN(s.ctx, "arn:aws:iam::210987654321:role/k8s-app", "k8s-app", "")
+	c.Assert(err, check.NotNil)
+}
+
+func (s *ValidateSuite) TestValidateAdoptRoleARNEmptySuccess(c *check.C) {
+	err := validation.ValidateAdoptRoleARN(s.ctx, "", "k8s-app", "")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *ValidateSuite) TestIsRoleManaged(c *check.C) {
+	request := awsapi.IAMRoleRequest{Tags: map[string]string{"managedBy": "iam-manager", "Namespace": "ns", "Cluster": "cluster"}}
+	managed := []*iam.Tag{
+		{Key: aws.String("managedBy"), Value: aws.String("iam-manager")},
+		{Key: aws.String("Namespace"), Value: aws.String("ns")},
+		{Key: aws.String("Cluster"), Value: aws.String("cluster")},
+	}
+	c.Assert(validation.IsRoleManaged(request, managed), check.Equals, true)
+	c.Assert(validation.IsRoleManaged(request, managed[1:]), check.Equals, false)
+	c.Assert(validation.IsRoleManaged(request, nil), check.Equals, false)
+}
+
+func (s *ValidateSuite) TestAdoptionOverwrites(c *check.C) {
+	request := awsapi.IAMRoleRequest{
+		PolicyName:                      "custom",
+		PermissionPolicy:                `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":["*"]}]}`,
+		TrustPolicy:                     `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":["arn:aws:iam::123456789012:role/node"]}}]}`,
+		ManagedPermissionBoundaryPolicy: "arn:aws:iam::123456789012:policy/boundary",
+		Description:                     "#DO NOT DELETE#. Managed by iam-manager",
+		SessionDuration:                 3600,
+		Tags:                            map[string]string{"managedBy": "iam-manager", "Namespace": "ns"},
+	}
+	targetRole := &iam.GetRoleOutput{Role: &iam.Role{
+		AssumeRolePolicyDocument: aws.String(url.QueryEscape(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"Service":"ec2.amazonaws.com"}}]}`)),
+		Description:              aws.String("#DO NOT DELETE#. Managed by iam-manager"),
+		MaxSessionDuration:       aws.Int64(3600),
+		Tags:                     []*iam.Tag{{Key: aws.String("Namespace"), Value: aws.String("other")}, {Key: aws.String("team"), Value: aws.String("payments")}},
+	}}
+	targetPolicies := map[string]string{"custom": url.QueryEscape(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:*"],"Resource":["*"]}]}`)}
+
+	overwrites := validation.AdoptionOverwrites(s.ctx, request, targetRole, targetPolicies)
+	c.Assert(overwrites, check.DeepEquals, []string{"trust policy", "inline policy custom", "permission boundary none", "tag Namespace=other"})
+}
+
+func (s *ValidateSuite) TestValidateMaxSessionDurationSuccess(c *check.C) {
+	err := validation.ValidateMaxSessionDuration(s.ctx, 3600)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *ValidateSuite) TestValidateMaxSessionDurationFailure(c *check.C) {
+	err := validation.ValidateMaxSessionDuration(s.ctx, 43201)
+	c.Assert(err, check.NotNil)
+	err = validation.ValidateMaxSessionDuration(s.ctx, 900)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *ValidateSuite) TestValidateRolePathSuccess(c *check.C) {
+	err := validation.ValidateRolePath(s.ctx, "/k8s/my-cluster/")
+	c.Assert(err, check.IsNil)
+	err = validation.ValidateRolePath(s.ctx, "")
+	c.Assert(err, check.IsNil)
+}
+
+func (s *ValidateSuite) TestValidateRolePathFailure(c *check.C) {
+	err := validation.ValidateRolePath(s.ctx, "k8s/")
+	c.Assert(err, check.NotNil)
+}
+
+func (s *ValidateSuite) TestCompareManagedPoliciesSuccess(c *check.C) {
+	request := awsapi.IAMRoleRequest{
+		ManagedPolicies:         []string{"arn:aws:iam::123456789012:policy/KEEP"},
+		PreviousManagedPolicies: []string{"arn:aws:iam::123456789012:policy/KEEP", "arn:aws:iam::123456789012:policy/REMOVED"},
+	}
+	// policies attached outside of iam-manager are not drift
+	flag := validation.CompareManagedPolicies(s.ctx, request, []string{"arn:aws:iam::123456789012:policy/KEEP", "arn:aws:iam::123456789012:policy/OTHER"})
+	c.Assert(flag, check.Equals, true)
+}
+
+func (s *ValidateSuite) TestCompareManagedPoliciesFailure(c *check.C) {
+	request := awsapi.IAMRoleRequest{
+		ManagedPolicies:         []string{"arn:aws:iam::123456789012:policy/KEEP"},
+		PreviousManagedPolicies: []string{"arn:aws:iam::123456789012:policy/KEEP", "arn:aws:iam::123456789012:policy/REMOVED"},
+	}
+	flag := validation.CompareManagedPolicies(s.ctx, request, []string{"arn:aws:iam::123456789012:policy/KEEP", "arn:aws:iam::123456789012:policy/REMOVED"})
+	c.Assert(flag, check.Equals, false)
+
+	flag = validation.CompareManagedPolicies(s.ctx, request, nil)
+	c.Assert(flag, check.Equals, false)
+}
+
 func (s *ValidateSuite) TestCompareTagsSuccess(c *check.C) {
 	input1 := map[string]string{
 		"cluster":   "clusterName",
@@ -481,6 +985,45 @@ func (s *ValidateSuite) TestCompareTagsFailure(c *check.C) {
 	c.Assert(flag, check.Equals, false)
 }
 
+func (s *ValidateSuite) TestCompareRoleTags(c *check.C) {
+	policy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":["*"]}]}`
+	trustPolicy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"AWS":["arn:aws:iam::123456789012:role/user_request_role"]}}]}`
+	boundary := config.Props.ManagedPermissionBoundaryPolicy()
+	request := awsapi.IAMRoleRequest{
+		PolicyName:                      config.InlinePolicyName,
+		PermissionPolicy:                policy,
+		TrustPolicy:                     trustPolicy,
+		ManagedPermissionBoundaryPolicy: boundary,
+		Tags:                            map[string]string{"managedBy": "iam-manager", "team": "payments"},
+		PreviousTags:                    []string{"team", "removed"},
+	}
+	target := &iam.GetRoleOutput{
+		Role: &iam.Role{
+			AssumeRolePolicyDocument: aws.String(trustPolicy),
+			PermissionsBoundary: &iam.AttachedPermissionsBoundary{
+				PermissionsBoundaryArn: aws.String(boundary),
+			},
+			Tags: []*iam.Tag{
+				{Key: aws.String("managedBy"), Value: aws.String("iam-manager")},
+				{Key: aws.String("team"), Value: aws.String("payments")},
+				// tags attached outside of iam-manager are not drift
+				{Key: aws.String("owner"), Value: aws.String("someone")},
+			},
+		},
+	}
+	policies := map[string]string{config.InlinePolicyName: policy}
+	c.Assert(validation.CompareRole(s.ctx, request, target, policies), check.Equals, true)
+
+	// tag removed from the spec but still attached
+	target.Role.Tags = append(target.Role.Tags, &iam.Tag{Key: aws.String("removed"), Value: aws.String("value")})
+	c.Assert(validation.CompareRole(s.ctx, request, target, policies), check.Equals, false)
+}
+
+func (s *ValidateSuite) TestValidateTagsFailure(c *check.C) {
+	err := validation.ValidateTags(s.ctx, map[string]string{"Cluster": "other"})
+	c.Assert(err, check.NotNil)
+}
+
 func (s *ValidateSuite) TestCompareRoleIRSASuccess(c *check.C) {
 	sa := v1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
@@ -508,6 +1051,37 @@ func (s *ValidateSuite) TestCompareRoleIRSAFailure(c *check.C) {
 	c.Assert(flag, check.Equals, false)
 }
 
+func (s *ValidateSuite) TestCompareServiceAccount(c *check.C) {
+	roleARN := "arn:aws:iam::123456789012:role/k8s-test-ns"
+	sa := v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-sa",
+			Namespace: "test-ns",
+			Annotations: map[string]string{
+				"eks.amazonaws.com/role-arn":               roleARN,
+				"eks.amazonaws.com/sts-regional-endpoints": "true",
+				"example.com/team":                         "orders",
+			},
+			Labels: map[string]string{"app": "orders"},
+		},
+	}
+	desired := v1alpha1.ServiceAccount{Name: "test-sa", Annotations: map[string]string{"example.com/team": "orders"}, Labels: map[string]string{"app": "orders"}}
+	c.Assert(validation.CompareServiceAccount(s.ctx, &sa, roleARN, desired, config.Properties{}), check.Equals, true)
+
+	// annotated with another role
+	c.Assert(validation.CompareServiceAccount(s.ctx, &sa, "arn:aws:iam::123456789012:role/other", desired, config.Properties{}), check.Equals, false)
+
+	// requested label changed
+	sa.Labels["app"] = "payments"
+	c.Assert(validation.CompareServiceAccount(s.ctx, &sa, roleARN, desired, config.Properties{}), check.Equals, false)
+}
+
+func (s *ValidateSuite) TestValidateServiceAccountsFailure(c *check.C) {
+	err := validation.ValidateServiceAccounts(s.ctx, []v1alpha1.ServiceAccount{{Name: "app"}, {Name: "app", Annotations: map[string]string{"eks.amazonaws.com/role-arn": "arn"}}})
+	c.Assert(err, check.NotNil)
+	c.Assert(err.Field, check.Equals, "spec.ServiceAccounts[1]")
+}
+
 func (s *ValidateSuite) TestContainsStringSuccess(c *check.C) {
 	resp := validation.ContainsString([]string{"iamrole.finalizers.iammanager.keikoproj.io", "iamrole.finalizers2.iammanager.keikoproj.io"}, "iamrole.finalizers.iammanager.keikoproj.io")
 	c.Assert(resp, check.Equals, true)